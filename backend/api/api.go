@@ -14,7 +14,10 @@ import (
 	"github.com/projuktisheba/ajfses/backend/internal/config"
 	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
 	"github.com/projuktisheba/ajfses/backend/internal/driver"
+	"github.com/projuktisheba/ajfses/backend/internal/mailer"
 	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+	"github.com/projuktisheba/ajfses/backend/internal/webhook"
 )
 
 var app *Application
@@ -65,13 +68,15 @@ func RunServer(ctx context.Context) error {
 		errorLog.Println(err)
 		return err
 	}
-	cfg.JWT = models.JWTConfig{
-		SecretKey: cfg.JWT.SecretKey,
-		Issuer:    cfg.JWT.Issuer,
-		Audience:  cfg.JWT.Audience,
-		Algorithm: "HS256",
-		Expiry:    time.Hour * 24,
+	cfg.JWT.Algorithm = "HS256"
+	if cfg.JWT.Expiry == 0 {
+		cfg.JWT.Expiry = time.Hour * 24
 	}
+	if cfg.JWT.Refresh == 0 {
+		cfg.JWT.Refresh = time.Hour * 24 * 30
+	}
+
+	utils.SetTrustedProxies(cfg.TrustedProxies)
 
 	infoLog.Println(cfg)
 	// Connection to database
@@ -92,8 +97,21 @@ func RunServer(ctx context.Context) error {
 	dbRepo := dbrepo.NewDBRepository(dbConn)
 	infoLog.Println("Connected to database")
 
+	// Outgoing mail
+	mail, err := mailer.New(cfg.Mail, cfg.Company, dbRepo.EmailMessageRepo, dbRepo.EmailTemplateRepo, infoLog, errorLog)
+	if err != nil {
+		errorLog.Println(err)
+		return err
+	}
+
+	// Outgoing webhooks, delivered in the background until the server stops
+	hooks := webhook.New(cfg.Webhook, dbRepo.WebhookRepo, infoLog, errorLog)
+	hooksCtx, stopHooks := context.WithCancel(ctx)
+	defer stopHooks()
+	go hooks.Run(hooksCtx)
+
 	// create router instance
-	routes := routes.Routes(cfg.Host, cfg.Env, dbRepo, cfg.JWT, infoLog, errorLog)
+	routes := routes.Routes(cfg, dbRepo, mail, hooks, infoLog, errorLog)
 	//Initiate handlers
 	app = &Application{
 		config:    cfg,
@@ -3,28 +3,38 @@ package handlers
 import (
 	"errors"
 	"fmt"
+	"io"
 	"log"
 	"net/http"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
+	"github.com/projuktisheba/ajfses/backend/internal/mailer"
 	"github.com/projuktisheba/ajfses/backend/internal/models"
 	"github.com/projuktisheba/ajfses/backend/internal/utils"
 )
 
 type AuthHandler struct {
-	DB        *dbrepo.DBRepository
-	JWTConfig models.JWTConfig
-	infoLog   *log.Logger
-	errorLog  *log.Logger
+	DB               *dbrepo.DBRepository
+	JWTConfig        models.JWTConfig
+	LoginConfig      models.LoginConfig
+	Mailer           *mailer.Mailer
+	PasswordResetURL string
+	infoLog          *log.Logger
+	errorLog         *log.Logger
 }
 
-func newAuthHandler(db *dbrepo.DBRepository, JWTConfig models.JWTConfig, infoLog, errorLog *log.Logger) AuthHandler {
+func newAuthHandler(db *dbrepo.DBRepository, cfg models.Config, mail *mailer.Mailer, infoLog, errorLog *log.Logger) AuthHandler {
 	return AuthHandler{
-		DB:        db,
-		JWTConfig: JWTConfig,
-		infoLog:   infoLog,
-		errorLog:  errorLog,
+		DB:               db,
+		JWTConfig:        cfg.JWT,
+		LoginConfig:      cfg.Login,
+		Mailer:           mail,
+		PasswordResetURL: cfg.PasswordResetURL,
+		infoLog:          infoLog,
+		errorLog:         errorLog,
 	}
 }
 
@@ -50,54 +60,309 @@ func (h *AuthHandler) Signin(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Too many failures from this IP
+	if h.ipThrottled(w, r) {
+		return
+	}
+
 	// Fetch employee by mobile OR email
 	user, err := h.DB.UserRepo.GetUserByUsername(r.Context(), req.Username)
 	if err != nil {
 		h.errorLog.Println("ERROR_02_Signin: user not found:", err)
+		h.recordLoginAttempt(r, req.Username, 0, false)
 		utils.BadRequest(w, errors.New("invalid username or password"))
 		return
 	}
 
+	// Locked accounts are rejected before the password is checked
+	if until, locked := accountLockedUntil(user); locked {
+		h.errorLog.Println("ERROR_03_Signin: locked account:", user.ID)
+		h.recordLoginAttempt(r, req.Username, user.ID, false)
+		utils.Locked(w, lockedError(until), until)
+		return
+	}
+
 	// Check password (assumes hashed in DB)
 	if !utils.CheckPassword(req.Password, user.Password) {
 		h.errorLog.Println("ERROR_03_Signin: password mismatch")
+		if until := h.registerFailedLogin(r, req.Username, user); until != nil {
+			utils.Locked(w, lockedError(*until), *until)
+			return
+		}
 		utils.BadRequest(w, errors.New("invalid username or password"))
 		return
 	}
 
-	// Check role (Only admin has the access)
-	if user.Role != "Admin" {
+	// Check account status (an expired lock is lifted on successful sign-in)
+	if user.Status == "Inactive" {
+		h.errorLog.Println("ERROR_03_Signin: inactive account:", user.ID)
+		utils.BadRequest(w, errors.New("your account is not active. Please contact to the system administrator"))
+		return
+	}
+
+	// Check role (roles without any permission have no access)
+	if len(models.PermissionsFor(user.Role)) == 0 {
 		h.errorLog.Println("ERROR_03_Signin: Access Denied")
 		utils.BadRequest(w, errors.New("You don't have the access. Please contact to the system administrator"))
 		return
 	}
 
-	// Generate JWT
+	// Accounts with two-factor enabled get a challenge token first (see VerifyTwoFactor)
+	if user.TOTPEnabled {
+		h.writeTwoFactorChallenge(w, user)
+		return
+	}
+
+	// Generate access & refresh tokens
+	h.completeSignin(r, req.Username, user)
+	h.issueTokens(w, r, user, "")
+}
+
+// ipThrottled writes a 429 response and returns true when the client IP has too many recent failed sign-ins.
+func (h *AuthHandler) ipThrottled(w http.ResponseWriter, r *http.Request) bool {
+	if h.LoginConfig.IPMaxAttempts <= 0 {
+		return false
+	}
+
+	failures, err := h.DB.LoginAttemptRepo.CountFailuresByIP(r.Context(), utils.ClientIP(r), time.Now().Add(-h.LoginConfig.Window))
+	if err != nil {
+		// Do not block sign-in because the counter is unavailable
+		h.errorLog.Println("ERROR_01_ipThrottled: db error:", err)
+		return false
+	}
+
+	if failures >= h.LoginConfig.IPMaxAttempts {
+		h.errorLog.Printf("ERROR_02_ipThrottled: %d failed sign-ins from %s", failures, utils.ClientIP(r))
+		utils.TooManyRequests(w, errors.New("too many failed sign-in attempts. Please try again later"), h.LoginConfig.Window)
+		return true
+	}
+	return false
+}
+
+// registerFailedLogin records a failed attempt and counts it against the account.
+// It returns the end of the lock if this failure locked the account.
+func (h *AuthHandler) registerFailedLogin(r *http.Request, username string, user *models.User) *time.Time {
+	h.recordLoginAttempt(r, username, user.ID, false)
+
+	if h.LoginConfig.MaxAttempts <= 0 {
+		return nil
+	}
+
+	until, err := h.DB.UserRepo.RegisterFailedLogin(r.Context(), user.ID, h.LoginConfig.MaxAttempts, h.LoginConfig.LockDuration, h.LoginConfig.MaxLockDuration)
+	if err != nil {
+		h.errorLog.Println("ERROR_01_registerFailedLogin: db error:", err)
+		return nil
+	}
+	if until != nil {
+		h.infoLog.Printf("User ID %d locked until %s after repeated failed sign-ins.", user.ID, until.Format(time.RFC3339))
+	}
+	return until
+}
+
+// completeSignin clears the failure counters after a successful sign-in.
+func (h *AuthHandler) completeSignin(r *http.Request, username string, user *models.User) {
+	h.recordLoginAttempt(r, username, user.ID, true)
+
+	if user.FailedLogins > 0 || user.Status == "Locked" {
+		if err := h.DB.UserRepo.ResetFailedLogins(r.Context(), user.ID); err != nil {
+			h.errorLog.Println("ERROR_01_completeSignin: db error:", err)
+		}
+		user.FailedLogins = 0
+		user.LockedUntil = nil
+		user.Status = "Active"
+	}
+}
+
+func (h *AuthHandler) recordLoginAttempt(r *http.Request, username string, userID int64, success bool) {
+	if err := h.DB.LoginAttemptRepo.Record(r.Context(), username, userID, utils.ClientIP(r), success); err != nil {
+		h.errorLog.Println("ERROR_01_recordLoginAttempt: db error:", err)
+	}
+}
+
+// accountLockedUntil returns the end of the user's lock if the account is currently locked.
+func accountLockedUntil(user *models.User) (time.Time, bool) {
+	if user.Status == "Locked" && user.LockedUntil != nil && user.LockedUntil.After(time.Now()) {
+		return *user.LockedUntil, true
+	}
+	return time.Time{}, false
+}
+
+func lockedError(until time.Time) error {
+	minutes := int(time.Until(until).Minutes()) + 1
+	return fmt.Errorf("account is locked after too many failed sign-in attempts. Try again in %d minute(s) or contact the system administrator", minutes)
+}
+
+// Refresh exchanges a refresh token for a new access token.
+// The presented refresh token is rotated: it is revoked and a new one is returned.
+// Presenting a refresh token that was already rotated revokes every token of that sign-in.
+func (h *AuthHandler) Refresh(w http.ResponseWriter, r *http.Request) {
+	type refreshRequest struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+
+	var req refreshRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_01_Refresh: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	req.RefreshToken = strings.TrimSpace(req.RefreshToken)
+	if req.RefreshToken == "" {
+		utils.BadRequest(w, errors.New("refresh token is required"))
+		return
+	}
+
+	// 1. Look up the stored token
+	stored, err := h.DB.RefreshTokenRepo.GetByHash(r.Context(), utils.HashToken(req.RefreshToken))
+	if err != nil {
+		h.errorLog.Println("ERROR_02_Refresh: lookup failed:", err)
+		utils.Unauthorized(w, errors.New("invalid refresh token"))
+		return
+	}
+
+	// 2. Reuse detection: a revoked token means it was already rotated (or revoked on purpose)
+	if stored.RevokedAt != nil {
+		h.errorLog.Printf("ERROR_03_Refresh: reuse of revoked refresh token %d (user %d), revoking family", stored.ID, stored.UserID)
+		if err := h.DB.RefreshTokenRepo.RevokeFamily(r.Context(), stored.FamilyID); err != nil {
+			h.errorLog.Println("ERROR_04_Refresh: revoke family failed:", err)
+		}
+		utils.Unauthorized(w, errors.New("refresh token has already been used. Please sign in again"))
+		return
+	}
+
+	if time.Now().After(stored.ExpiresAt) {
+		utils.Unauthorized(w, errors.New("refresh token has expired. Please sign in again"))
+		return
+	}
+
+	// 3. Reload the user so role changes are reflected in the new access token
+	user, err := h.DB.UserRepo.GetUserByID(r.Context(), stored.UserID)
+	if err != nil {
+		h.errorLog.Println("ERROR_05_Refresh: user not found:", err)
+		utils.Unauthorized(w, errors.New("invalid refresh token"))
+		return
+	}
+
+	if user.Status == "Inactive" {
+		h.errorLog.Println("ERROR_06_Refresh: inactive user:", user.ID)
+		utils.Unauthorized(w, errors.New("account is not active"))
+		return
+	}
+
+	if len(models.PermissionsFor(user.Role)) == 0 {
+		h.errorLog.Println("ERROR_07_Refresh: Access Denied")
+		utils.Forbidden(w, errors.New("access denied. Insufficient privileges"))
+		return
+	}
+
+	// 4. Rotate and issue new tokens
+	h.rotateTokens(w, r, user, stored)
+}
+
+// issueTokens generates an access token and a new refresh token for the user and writes the sign-in response.
+// An empty familyID starts a new refresh token family.
+func (h *AuthHandler) issueTokens(w http.ResponseWriter, r *http.Request, user *models.User, familyID string) {
+	if familyID == "" {
+		var err error
+		familyID, err = utils.GenerateOpaqueToken(16)
+		if err != nil {
+			h.errorLog.Println("ERROR_01_issueTokens: failed to generate token family:", err)
+			utils.ServerError(w, errors.New("failed to generate token"))
+			return
+		}
+	}
+
+	refreshToken, stored, err := h.newRefreshToken(r, user.ID, familyID)
+	if err != nil {
+		h.errorLog.Println("ERROR_02_issueTokens: failed to generate refresh token:", err)
+		utils.ServerError(w, errors.New("failed to generate token"))
+		return
+	}
+
+	if err := h.DB.RefreshTokenRepo.Create(r.Context(), stored); err != nil {
+		h.errorLog.Println("ERROR_03_issueTokens: failed to store refresh token:", err)
+		utils.ServerError(w, errors.New("failed to generate token"))
+		return
+	}
+
+	h.writeTokens(w, user, refreshToken)
+}
+
+// rotateTokens replaces the current refresh token with a new one of the same family and writes the sign-in response.
+func (h *AuthHandler) rotateTokens(w http.ResponseWriter, r *http.Request, user *models.User, current *models.RefreshToken) {
+	refreshToken, next, err := h.newRefreshToken(r, user.ID, current.FamilyID)
+	if err != nil {
+		h.errorLog.Println("ERROR_01_rotateTokens: failed to generate refresh token:", err)
+		utils.ServerError(w, errors.New("failed to generate token"))
+		return
+	}
+
+	if err := h.DB.RefreshTokenRepo.Rotate(r.Context(), current.ID, next); err != nil {
+		if errors.Is(err, dbrepo.ErrRefreshTokenReused) {
+			h.errorLog.Printf("ERROR_02_rotateTokens: concurrent reuse of refresh token %d, revoking family", current.ID)
+			if err := h.DB.RefreshTokenRepo.RevokeFamily(r.Context(), current.FamilyID); err != nil {
+				h.errorLog.Println("ERROR_03_rotateTokens: revoke family failed:", err)
+			}
+			utils.Unauthorized(w, errors.New("refresh token has already been used. Please sign in again"))
+			return
+		}
+		h.errorLog.Println("ERROR_04_rotateTokens: failed to rotate refresh token:", err)
+		utils.ServerError(w, errors.New("failed to generate token"))
+		return
+	}
+
+	h.writeTokens(w, user, refreshToken)
+}
+
+// newRefreshToken generates a plain refresh token and the row that stores its hash.
+func (h *AuthHandler) newRefreshToken(r *http.Request, userID int64, familyID string) (string, *models.RefreshToken, error) {
+	plain, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		return "", nil, err
+	}
+
+	return plain, &models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: utils.HashToken(plain),
+		ExpiresAt: time.Now().Add(h.JWTConfig.Refresh),
+		UserAgent: r.UserAgent(),
+		IPAddress: utils.ClientIP(r),
+	}, nil
+}
+
+// writeTokens signs an access token for the user and writes it together with the refresh token.
+func (h *AuthHandler) writeTokens(w http.ResponseWriter, user *models.User, refreshToken string) {
 	token, err := utils.GenerateJWT(models.JWT{
-		ID:        user.ID,
-		Name:      user.Name,
-		Username:  user.Email,
-		Role:      user.Role,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+		ID:           user.ID,
+		TokenVersion: user.TokenVersion,
+		Name:         user.Name,
+		Username:     user.Email,
+		Role:         user.Role,
+		CreatedAt:    user.CreatedAt,
+		UpdatedAt:    user.UpdatedAt,
 	}, h.JWTConfig)
 
 	if err != nil {
-		h.errorLog.Println("ERROR_04_Signin: failed to generate JWT:", err)
-		utils.BadRequest(w, fmt.Errorf("failed to generate token: %w", err))
+		h.errorLog.Println("ERROR_01_writeTokens: failed to generate JWT:", err)
+		utils.ServerError(w, fmt.Errorf("failed to generate token: %w", err))
 		return
 	}
 
 	// Build response
 	resp := struct {
-		Error        bool         `json:"error"`
-		AccessToken  string       `json:"accessToken"`
-		RefreshToken string       `json:"refreshToken"`
-		User         *models.User `json:"user"`
+		Error        bool                `json:"error"`
+		AccessToken  string              `json:"accessToken"`
+		RefreshToken string              `json:"refreshToken"`
+		User         *models.User        `json:"user"`
+		Permissions  []models.Permission `json:"permissions"`
 	}{
-		Error:       false,
-		AccessToken: token,
-		User:        user,
+		Error:        false,
+		AccessToken:  token,
+		RefreshToken: refreshToken,
+		User:         user,
+		Permissions:  models.PermissionsFor(user.Role),
 	}
 
 	utils.WriteJSON(w, http.StatusOK, resp)
@@ -170,3 +435,79 @@ func (h *AuthHandler) UpdatePassword(w http.ResponseWriter, r *http.Request) {
 	h.infoLog.Printf("User ID %d successfully updated their password.", userID)
 	utils.WriteJSON(w, http.StatusOK, resp)
 }
+
+// Logout revokes the access token used for the request.
+// If the body carries the refresh token, every refresh token of that sign-in is revoked as well.
+func (h *AuthHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	type logoutRequest struct {
+		RefreshToken string `json:"refreshToken"`
+	}
+
+	authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+	if !ok {
+		h.errorLog.Println("ERROR_01_Logout: authentication claims not found in context.")
+		utils.Unauthorized(w, errors.New("authentication context missing. Please log in again."))
+		return
+	}
+
+	// The body is optional
+	var req logoutRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil && !errors.Is(err, io.EOF) {
+		h.errorLog.Println("ERROR_02_Logout: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	// 1. Revoke the access token
+	err := h.DB.SessionRepo.RevokeAccessToken(r.Context(), authClaims.TokenID, authClaims.ID, time.Unix(authClaims.ExpiresAt, 0))
+	if err != nil {
+		h.errorLog.Println("ERROR_03_Logout: revoke access token failed:", err)
+		utils.ServerError(w, errors.New("failed to log out"))
+		return
+	}
+
+	// 2. Revoke the refresh token family, only if it belongs to the same user
+	req.RefreshToken = strings.TrimSpace(req.RefreshToken)
+	if req.RefreshToken != "" {
+		stored, err := h.DB.RefreshTokenRepo.GetByHash(r.Context(), utils.HashToken(req.RefreshToken))
+		if err == nil && stored.UserID == authClaims.ID {
+			if err := h.DB.RefreshTokenRepo.RevokeFamily(r.Context(), stored.FamilyID); err != nil {
+				h.errorLog.Println("ERROR_04_Logout: revoke refresh token failed:", err)
+				utils.ServerError(w, errors.New("failed to log out"))
+				return
+			}
+		}
+	}
+
+	h.infoLog.Printf("User ID %d logged out.", authClaims.ID)
+	utils.WriteJSON(w, http.StatusOK, models.Response{
+		Error:   false,
+		Message: "Logged out successfully.",
+	})
+}
+
+// RevokeSessions signs a user out everywhere: all access and refresh tokens issued so far stop working.
+// Query parameter {id} is the target user's ID.
+func (h *AuthHandler) RevokeSessions(w http.ResponseWriter, r *http.Request) {
+	idStr := strings.TrimSpace(r.URL.Query().Get("id"))
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil || id < 1 {
+		utils.BadRequest(w, errors.New("invalid user ID"))
+		return
+	}
+
+	if err := h.DB.SessionRepo.RevokeAllForUser(r.Context(), id); err != nil {
+		h.errorLog.Println("ERROR_01_RevokeSessions: db error:", err)
+		utils.ServerError(w, errors.New("failed to revoke sessions"))
+		return
+	}
+
+	if authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT); ok {
+		h.infoLog.Printf("User ID %d revoked all sessions of user ID %d.", authClaims.ID, id)
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.Response{
+		Error:   false,
+		Message: "All sessions of the user have been revoked.",
+	})
+}
@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+)
+
+// readBrandForm parses a multipart brand form and applies the fields that were sent to b.
+// Fields: name, slug (derived from the name when empty), website, description, sort_order; file: logo.
+func readBrandForm(w http.ResponseWriter, r *http.Request, b *models.Brand) (*pendingAttachment, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxProductImage+productFormMemoryBuffer)
+	if err := r.ParseMultipartForm(productFormMemoryBuffer); err != nil {
+		return nil, errors.New("invalid form data or logo too large")
+	}
+
+	if v, ok := formField(r, "name"); ok {
+		b.Name = v
+	}
+	if v, ok := formField(r, "slug"); ok {
+		b.Slug = strings.ToLower(v)
+	}
+	if v, ok := formField(r, "website"); ok {
+		b.Website = v
+	}
+	if v, ok := formField(r, "description"); ok {
+		b.Description = v
+	}
+	if v, ok := formField(r, "sort_order"); ok && v != "" {
+		order, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errors.New("sort_order must be a whole number")
+		}
+		b.SortOrder = order
+	}
+
+	if b.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if len(b.Name) > 100 {
+		return nil, errors.New("name is limited to 100 characters")
+	}
+	if b.Slug == "" {
+		b.Slug = slugify(b.Name)
+	}
+	if err := validSlug(b.Slug); err != nil {
+		return nil, err
+	}
+	if b.Website != "" {
+		u, err := url.Parse(b.Website)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || len(b.Website) > 255 {
+			return nil, errors.New("website must be an http or https URL of at most 255 characters")
+		}
+	}
+
+	return readCatalogueImage(r, "logo")
+}
+
+// GetBrands lists every brand with its number of active products.
+func (h *ProductHandler) GetBrands(w http.ResponseWriter, r *http.Request) {
+	brands, err := h.DB.BrandRepo.GetAll(r.Context())
+	if err != nil {
+		h.errorLog.Println("ERROR_GetBrands_01: db error:", err)
+		utils.ServerError(w, errors.New("failed to retrieve brands"))
+		return
+	}
+
+	var response struct {
+		Error   bool            `json:"error"`
+		Message string          `json:"message"`
+		Brands  []*models.Brand `json:"brands"`
+	}
+	response.Error = false
+	response.Message = "Brands fetched successfully"
+	response.Brands = brands
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetProductsByBrand lists the active products of brand {slug}.
+// Query parameters: category, search, page, limit (all optional).
+func (h *ProductHandler) GetProductsByBrand(w http.ResponseWriter, r *http.Request) {
+	brand, err := h.DB.BrandRepo.GetBySlug(r.Context(), chi.URLParam(r, "slug"))
+	if err != nil {
+		h.errorLog.Println("ERROR_GetProductsByBrand_01: db error:", err)
+		utils.NotFound(w, "brand not found")
+		return
+	}
+
+	h.writeProductList(w, r, false, nil, brand)
+}
+
+// CreateBrand adds a brand from a multipart form (see readBrandForm).
+func (h *ProductHandler) CreateBrand(w http.ResponseWriter, r *http.Request) {
+	brand := &models.Brand{}
+	logo, err := readBrandForm(w, r, brand)
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+	if err != nil {
+		h.errorLog.Println("ERROR_CreateBrand_01: invalid form:", err)
+		utils.BadRequest(w, err)
+		return
+	}
+
+	if logo != nil {
+		brand.LogoLink, err = saveCatalogueImage(logo, brandLogoDir, brand.Slug)
+		if err != nil {
+			h.errorLog.Println("ERROR_CreateBrand_02: save logo:", err)
+			utils.ServerError(w, errors.New("failed to save logo"))
+			return
+		}
+	}
+
+	if err := h.DB.BrandRepo.Create(r.Context(), brand); err != nil {
+		if brand.LogoLink != "" {
+			os.Remove(filepath.Join(brandLogoDir, brand.LogoLink))
+		}
+		if errors.Is(err, dbrepo.ErrBrandSlugTaken) {
+			utils.BadRequest(w, err)
+			return
+		}
+		h.errorLog.Println("ERROR_CreateBrand_03: db create:", err)
+		utils.ServerError(w, errors.New("failed to save brand"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, struct {
+		Error   bool          `json:"error"`
+		Message string        `json:"message"`
+		Data    *models.Brand `json:"data"`
+	}{
+		Error:   false,
+		Message: "Brand created successfully",
+		Data:    brand,
+	})
+}
+
+// UpdateBrand changes the fields sent in the form and replaces the logo when a new one is uploaded.
+func (h *ProductHandler) UpdateBrand(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid brand ID"))
+		return
+	}
+
+	brand, err := h.DB.BrandRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_UpdateBrand_01: fetch error:", err)
+		utils.NotFound(w, "brand not found")
+		return
+	}
+	oldLogoLink := brand.LogoLink
+
+	logo, err := readBrandForm(w, r, brand)
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+	if err != nil {
+		h.errorLog.Println("ERROR_UpdateBrand_02: invalid form:", err)
+		utils.BadRequest(w, err)
+		return
+	}
+
+	if logo != nil {
+		brand.LogoLink, err = saveCatalogueImage(logo, brandLogoDir, brand.Slug)
+		if err != nil {
+			h.errorLog.Println("ERROR_UpdateBrand_03: save logo:", err)
+			utils.ServerError(w, errors.New("failed to save logo"))
+			return
+		}
+	}
+
+	if err := h.DB.BrandRepo.Update(r.Context(), brand); err != nil {
+		if logo != nil {
+			os.Remove(filepath.Join(brandLogoDir, brand.LogoLink))
+		}
+		if errors.Is(err, dbrepo.ErrBrandSlugTaken) {
+			utils.BadRequest(w, err)
+			return
+		}
+		h.errorLog.Println("ERROR_UpdateBrand_04: db update:", err)
+		utils.ServerError(w, errors.New("failed to update brand"))
+		return
+	}
+
+	if logo != nil && oldLogoLink != "" {
+		os.Remove(filepath.Join(brandLogoDir, oldLogoLink))
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error   bool          `json:"error"`
+		Message string        `json:"message"`
+		Data    *models.Brand `json:"data"`
+	}{
+		Error:   false,
+		Message: "Brand updated successfully",
+		Data:    brand,
+	})
+}
+
+// DeleteBrand removes a brand; its products are kept without a brand.
+func (h *ProductHandler) DeleteBrand(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid brand ID"))
+		return
+	}
+
+	brand, err := h.DB.BrandRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_DeleteBrand_01: fetch error:", err)
+		utils.NotFound(w, "brand not found")
+		return
+	}
+
+	if err := h.DB.BrandRepo.Delete(r.Context(), id); err != nil {
+		h.errorLog.Println("ERROR_DeleteBrand_02: db error:", err)
+		utils.ServerError(w, errors.New("failed to delete brand"))
+		return
+	}
+
+	if brand.LogoLink != "" {
+		os.Remove(filepath.Join(brandLogoDir, brand.LogoLink))
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error   bool   `json:"error"`
+		Message string `json:"message"`
+	}{
+		Error:   false,
+		Message: "Brand deleted successfully",
+	})
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -15,20 +16,23 @@ import (
 	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
 	"github.com/projuktisheba/ajfses/backend/internal/models"
 	"github.com/projuktisheba/ajfses/backend/internal/utils"
+	"github.com/projuktisheba/ajfses/backend/internal/webhook"
 )
 
 // ClientHandler is the new handler struct for client operations.
 type ClientHandler struct {
 	DB       *dbrepo.DBRepository
+	Webhooks *webhook.Dispatcher
 	infoLog  *log.Logger
 	errorLog *log.Logger
 }
 
-func newClientHandler(db *dbrepo.DBRepository, infoLog, errorLog *log.Logger) ClientHandler {
+func newClientHandler(db *dbrepo.DBRepository, hooks *webhook.Dispatcher, infoLog, errorLog *log.Logger) ClientHandler {
 	// IMPORTANT: This assumes dbrepo.DBRepository has a field 'ClientRepo'
 	// (e.g., DB.ClientRepo.Create, DB.ClientRepo.GetAll, etc.)
 	return ClientHandler{
 		DB:       db,
+		Webhooks: hooks,
 		infoLog:  infoLog,
 		errorLog: errorLog,
 	}
@@ -73,6 +77,8 @@ func (h *ClientHandler) CreateClient(w http.ResponseWriter, r *http.Request) {
 		utils.ServerError(w, errors.New("failed to save client info"))
 		return
 	}
+	// The client exists from here on, whether or not the image is saved
+	defer h.publishClient(r.Context(), models.WebhookEventClientCreated, id)
 
 	// 4. STEP TWO: Save Image to File System
 	file, header, err := r.FormFile("profileImage")
@@ -151,7 +157,7 @@ func (h *ClientHandler) GetAllClients(w http.ResponseWriter, r *http.Request) {
 	utils.WriteJSON(w, http.StatusOK, response)
 }
 
-// GetClient retrieves a single client by ID.
+// GetClient retrieves a single client by ID for the public profile, without the source inquiry.
 func (h *ClientHandler) GetClient(w http.ResponseWriter, r *http.Request) {
 	idStr := chi.URLParam(r, "id")
 	id, err := strconv.ParseInt(idStr, 10, 64)
@@ -166,6 +172,26 @@ func (h *ClientHandler) GetClient(w http.ResponseWriter, r *http.Request) {
 		utils.NotFound(w, "client not found")
 		return
 	}
+	client.SourceInquiry = nil
+
+	utils.WriteJSON(w, http.StatusOK, client)
+}
+
+// GetClientDetail retrieves a single client by ID for staff, including the inquiry it was converted from.
+func (h *ClientHandler) GetClientDetail(w http.ResponseWriter, r *http.Request) {
+	idStr := chi.URLParam(r, "id")
+	id, err := strconv.ParseInt(idStr, 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid client ID"))
+		return
+	}
+
+	client, err := h.DB.ClientRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetClientDetail_01: db error:", err)
+		utils.NotFound(w, "client not found")
+		return
+	}
 
 	utils.WriteJSON(w, http.StatusOK, client)
 }
@@ -371,6 +397,8 @@ func (h *ClientHandler) UpdateClient(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	h.Webhooks.Publish(r.Context(), models.WebhookEventClientUpdated, existing)
+
 	utils.WriteJSON(w, http.StatusOK, struct {
 		Error   bool           `json:"error"`
 		Message string         `json:"message"`
@@ -409,6 +437,8 @@ func (h *ClientHandler) DeleteClient(w http.ResponseWriter, r *http.Request) {
 	// Changed directory from 'images' to 'clients'
 	os.Remove(filepath.Join("data", "images", "clients", client.ImageLink))
 
+	h.Webhooks.Publish(r.Context(), models.WebhookEventClientDeleted, client)
+
 	utils.WriteJSON(w, http.StatusOK, struct {
 		Error   bool   `json:"error"`
 		Message string `json:"message"`
@@ -418,6 +448,16 @@ func (h *ClientHandler) DeleteClient(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// publishClient sends a webhook event with the client as stored, including the image link saved last.
+func (h *ClientHandler) publishClient(ctx context.Context, event string, id int64) {
+	client, err := h.DB.ClientRepo.GetByID(ctx, id)
+	if err != nil {
+		h.errorLog.Println("ERROR_publishClient_01: fetch error:", err)
+		return
+	}
+	h.Webhooks.Publish(ctx, event, client)
+}
+
 func (h *ClientHandler) respondSuccess(w http.ResponseWriter, id int64, msg string) {
 	utils.WriteJSON(w, http.StatusCreated, struct {
 		Error   bool   `json:"error"`
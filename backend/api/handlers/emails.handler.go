@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
+	"github.com/projuktisheba/ajfses/backend/internal/mailer"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+)
+
+// EmailHandler serves the log of outgoing emails and the editable email templates.
+type EmailHandler struct {
+	DB       *dbrepo.DBRepository
+	Mailer   *mailer.Mailer
+	infoLog  *log.Logger
+	errorLog *log.Logger
+}
+
+func newEmailHandler(db *dbrepo.DBRepository, mail *mailer.Mailer, infoLog, errorLog *log.Logger) EmailHandler {
+	return EmailHandler{
+		DB:       db,
+		Mailer:   mail,
+		infoLog:  infoLog,
+		errorLog: errorLog,
+	}
+}
+
+// GetAllEmails returns a page of the email log, newest first.
+func (h *EmailHandler) GetAllEmails(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+
+	page, limit := 1, 20
+	if v := queryParams.Get("page"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil || val < 1 {
+			utils.BadRequest(w, errors.New("Invalid format for 'page'. Must be a positive integer."))
+			return
+		}
+		page = val
+	}
+	if v := queryParams.Get("limit"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil || val < 1 || val > 100 {
+			utils.BadRequest(w, errors.New("Invalid format for 'limit'. Must be between 1 and 100."))
+			return
+		}
+		limit = val
+	}
+
+	status := strings.ToUpper(strings.TrimSpace(queryParams.Get("status")))
+	if status != "" && status != models.EmailStatusSent && status != models.EmailStatusFailed {
+		utils.BadRequest(w, errors.New("Invalid 'status'. Must be SENT or FAILED."))
+		return
+	}
+
+	emails, total, err := h.DB.EmailMessageRepo.GetAll(r.Context(), page, limit, status,
+		strings.TrimSpace(queryParams.Get("template")),
+		strings.TrimSpace(queryParams.Get("search")),
+	)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetAllEmails_01: db error:", err)
+		utils.ServerError(w, errors.New("failed to retrieve emails"))
+		return
+	}
+
+	var response struct {
+		Error   bool                  `json:"error"`
+		Message string                `json:"message"`
+		Emails  []models.EmailMessage `json:"emails"`
+		Total   int                   `json:"total"`
+		Page    int                   `json:"page"`
+		Limit   int                   `json:"limit"`
+	}
+	response.Error = false
+	response.Message = "Emails fetched successfully"
+	response.Emails = emails
+	response.Total = total
+	response.Page = page
+	response.Limit = limit
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetTemplates lists every email template with its current source: the edited version if there is one,
+// otherwise the built-in default. Each entry also carries the default for comparison.
+func (h *EmailHandler) GetTemplates(w http.ResponseWriter, r *http.Request) {
+	type templateEntry struct {
+		*models.EmailTemplate
+		Default *models.EmailTemplate `json:"default"`
+	}
+
+	templates := []templateEntry{}
+	for _, name := range h.Mailer.Templates() {
+		def, err := h.Mailer.DefaultTemplate(name)
+		if err != nil {
+			h.errorLog.Println("ERROR_GetTemplates_01: default template:", err)
+			utils.ServerError(w, errors.New("failed to load email templates"))
+			return
+		}
+
+		current, err := h.DB.EmailTemplateRepo.GetTemplate(r.Context(), name)
+		if err != nil {
+			h.errorLog.Println("ERROR_GetTemplates_02: db error:", err)
+			utils.ServerError(w, errors.New("failed to load email templates"))
+			return
+		}
+		if current == nil {
+			current = def
+		}
+
+		templates = append(templates, templateEntry{EmailTemplate: current, Default: def})
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error     bool            `json:"error"`
+		Message   string          `json:"message"`
+		Templates []templateEntry `json:"templates"`
+	}{
+		Error:     false,
+		Message:   "Email templates fetched successfully",
+		Templates: templates,
+	})
+}
+
+// UpdateTemplate saves an edited version of a built-in template (query parameter name).
+// Subject and text are required; an empty html body sends text-only emails.
+func (h *EmailHandler) UpdateTemplate(w http.ResponseWriter, r *http.Request) {
+	type templateRequest struct {
+		Subject string `json:"subject"`
+		Text    string `json:"text"`
+		HTML    string `json:"html"`
+	}
+
+	authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+	if !ok {
+		h.errorLog.Println("ERROR_UpdateTemplate_01: authentication claims not found in context.")
+		utils.Unauthorized(w, errors.New("authentication context missing. Please log in again."))
+		return
+	}
+
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+	if _, err := h.Mailer.DefaultTemplate(name); err != nil {
+		utils.NotFound(w, "email template not found")
+		return
+	}
+
+	var req templateRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_UpdateTemplate_02: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	t := &models.EmailTemplate{
+		Name:      name,
+		Subject:   strings.TrimSpace(req.Subject),
+		Text:      strings.TrimSpace(req.Text),
+		HTML:      strings.TrimSpace(req.HTML),
+		UpdatedBy: &authClaims.ID,
+	}
+	if err := h.Mailer.ValidateTemplate(t); err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+
+	if err := h.DB.EmailTemplateRepo.Save(r.Context(), t); err != nil {
+		h.errorLog.Println("ERROR_UpdateTemplate_03: db error:", err)
+		utils.ServerError(w, errors.New("failed to save email template"))
+		return
+	}
+
+	h.infoLog.Printf("User ID %d updated email template %s.", authClaims.ID, name)
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error    bool                  `json:"error"`
+		Message  string                `json:"message"`
+		Template *models.EmailTemplate `json:"template"`
+	}{
+		Error:    false,
+		Message:  "Email template updated successfully",
+		Template: t,
+	})
+}
+
+// ResetTemplate discards the edited version of a template (query parameter name) and restores the default.
+func (h *EmailHandler) ResetTemplate(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+	def, err := h.Mailer.DefaultTemplate(name)
+	if err != nil {
+		utils.NotFound(w, "email template not found")
+		return
+	}
+
+	if err := h.DB.EmailTemplateRepo.Delete(r.Context(), name); err != nil {
+		h.errorLog.Println("ERROR_ResetTemplate_01: db error:", err)
+		utils.ServerError(w, errors.New("failed to reset email template"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error    bool                  `json:"error"`
+		Message  string                `json:"message"`
+		Template *models.EmailTemplate `json:"template"`
+	}{
+		Error:    false,
+		Message:  "Email template restored to the default",
+		Template: def,
+	})
+}
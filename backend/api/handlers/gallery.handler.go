@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -14,17 +15,20 @@ import (
 	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
 	"github.com/projuktisheba/ajfses/backend/internal/models"
 	"github.com/projuktisheba/ajfses/backend/internal/utils"
+	"github.com/projuktisheba/ajfses/backend/internal/webhook"
 )
 
 type GalleryHandler struct {
 	DB       *dbrepo.DBRepository
+	Webhooks *webhook.Dispatcher
 	infoLog  *log.Logger
 	errorLog *log.Logger
 }
 
-func newGalleryHandler(db *dbrepo.DBRepository, infoLog, errorLog *log.Logger) GalleryHandler {
+func newGalleryHandler(db *dbrepo.DBRepository, hooks *webhook.Dispatcher, infoLog, errorLog *log.Logger) GalleryHandler {
 	return GalleryHandler{
 		DB:       db,
+		Webhooks: hooks,
 		infoLog:  infoLog,
 		errorLog: errorLog,
 	}
@@ -125,6 +129,7 @@ func (h *GalleryHandler) CreateGallery(w http.ResponseWriter, r *http.Request) {
 			h.errorLog.Println("ERROR_CreateGallery_07: update link:", err)
 		} else {
 			countSuccess++
+			h.publishGalleryItem(r.Context(), models.WebhookEventGalleryCreated, id)
 		}
 	}
 
@@ -237,6 +242,7 @@ func (h *GalleryHandler) UpdateGallery(w http.ResponseWriter, r *http.Request) {
 			utils.ServerError(w, errors.New("failed to update image link"))
 			return
 		}
+		item.ImageLink = newFilename
 	} else if !errors.Is(err, http.ErrMissingFile) {
 		// Real error occurred during file retrieval
 		h.errorLog.Println("ERROR_UpdateGallery_07: retrieving file:", err)
@@ -244,6 +250,8 @@ func (h *GalleryHandler) UpdateGallery(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.Webhooks.Publish(r.Context(), models.WebhookEventGalleryUpdated, item)
+
 	utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "Gallery item updated successfully"})
 }
 
@@ -283,9 +291,21 @@ func (h *GalleryHandler) DeleteGallery(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	h.Webhooks.Publish(r.Context(), models.WebhookEventGalleryDeleted, item)
+
 	utils.WriteJSON(w, http.StatusOK, map[string]string{"message": "Gallery item deleted successfully"})
 }
 
+// publishGalleryItem sends a webhook event with the gallery item as stored.
+func (h *GalleryHandler) publishGalleryItem(ctx context.Context, event string, id int64) {
+	item, err := h.DB.GalleryRepo.GetByID(ctx, id)
+	if err != nil {
+		h.errorLog.Println("ERROR_publishGalleryItem_01: fetch error:", err)
+		return
+	}
+	h.Webhooks.Publish(ctx, event, item)
+}
+
 // GetAllGallery retrieves all items.
 func (h *GalleryHandler) GetAllGallery(w http.ResponseWriter, r *http.Request) {
 	limit := 0
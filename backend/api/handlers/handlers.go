@@ -4,31 +4,43 @@ import (
 	"log"
 
 	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
+	"github.com/projuktisheba/ajfses/backend/internal/mailer"
 	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/webhook"
 )
 
 type HandlerRepo struct {
-	JWT      models.JWTConfig
-	InfoLog  *log.Logger
-	ErrorLog *log.Logger
-	Auth     AuthHandler
-	Inquiry  InquiryHandler
-	Member   MemberHandler
-	Team     TeamHandler
-	Gallery  GalleryHandler
-	Client   ClientHandler
+	JWT       models.JWTConfig
+	InfoLog   *log.Logger
+	ErrorLog  *log.Logger
+	Auth      AuthHandler
+	Inquiry   InquiryHandler
+	Member    MemberHandler
+	Team      TeamHandler
+	Gallery   GalleryHandler
+	Client    ClientHandler
+	User      UserHandler
+	Email     EmailHandler
+	Webhook   WebhookHandler
+	Product   ProductHandler
+	Quotation QuotationHandler
 }
 
-func NewHandlerRepo(host string, db *dbrepo.DBRepository, jwt models.JWTConfig, infoLog, errorLog *log.Logger) *HandlerRepo {
+func NewHandlerRepo(cfg models.Config, db *dbrepo.DBRepository, mail *mailer.Mailer, hooks *webhook.Dispatcher, infoLog, errorLog *log.Logger) *HandlerRepo {
 	return &HandlerRepo{
-		JWT:     jwt,
-		InfoLog: infoLog,
-		ErrorLog: errorLog,
-		Auth:    newAuthHandler(db, jwt, infoLog, errorLog),
-		Inquiry: newInquiryHandler(db, infoLog, errorLog),
-		Member:  newMemberHandler(db, infoLog, errorLog),
-		Team:    newTeamHandler(db, infoLog, errorLog),
-		Gallery: newGalleryHandler(db, infoLog, errorLog),
-		Client:  newClientHandler(db, infoLog, errorLog),
+		JWT:       cfg.JWT,
+		InfoLog:   infoLog,
+		ErrorLog:  errorLog,
+		Auth:      newAuthHandler(db, cfg, mail, infoLog, errorLog),
+		Inquiry:   newInquiryHandler(db, cfg.Inquiry, mail, hooks, infoLog, errorLog),
+		Member:    newMemberHandler(db, hooks, infoLog, errorLog),
+		Team:      newTeamHandler(db, hooks, infoLog, errorLog),
+		Gallery:   newGalleryHandler(db, hooks, infoLog, errorLog),
+		Client:    newClientHandler(db, hooks, infoLog, errorLog),
+		User:      newUserHandler(db, infoLog, errorLog),
+		Email:     newEmailHandler(db, mail, infoLog, errorLog),
+		Webhook:   newWebhookHandler(db, hooks, infoLog, errorLog),
+		Product:   newProductHandler(db, hooks, infoLog, errorLog),
+		Quotation: newQuotationHandler(db, cfg, mail, hooks, infoLog, errorLog),
 	}
 }
@@ -1,41 +1,92 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log"
+	"mime/multipart"
 	"net/http"
+	"net/mail"
+	"os"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
+	"github.com/projuktisheba/ajfses/backend/internal/mailer"
 	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/spam"
 	"github.com/projuktisheba/ajfses/backend/internal/utils"
+	"github.com/projuktisheba/ajfses/backend/internal/webhook"
 )
 
 type InquiryHandler struct {
-	DB       *dbrepo.DBRepository
-	infoLog  *log.Logger
-	errorLog *log.Logger
+	DB         *dbrepo.DBRepository
+	Config     models.InquiryConfig
+	Mailer     *mailer.Mailer
+	FormTokens *spam.FormTokens
+	Spam       spam.Scorer
+	Webhooks   *webhook.Dispatcher
+	infoLog    *log.Logger
+	errorLog   *log.Logger
 }
 
-func newInquiryHandler(db *dbrepo.DBRepository, infoLog, errorLog *log.Logger) InquiryHandler {
+func newInquiryHandler(db *dbrepo.DBRepository, cfg models.InquiryConfig, mail *mailer.Mailer, hooks *webhook.Dispatcher, infoLog, errorLog *log.Logger) InquiryHandler {
 	return InquiryHandler{
-		DB:       db,
-		infoLog:  infoLog,
-		errorLog: errorLog,
+		DB:         db,
+		Config:     cfg,
+		Mailer:     mail,
+		FormTokens: spam.NewFormTokens(cfg.FormTokenSecret, cfg.FormTokenMaxAge),
+		Spam:       spam.Scorers{spam.NewHeuristicScorer(cfg.MinSubmitTime)},
+		Webhooks:   hooks,
+		infoLog:    infoLog,
+		errorLog:   errorLog,
 	}
 }
 
+// GetFormToken issues the signed token the contact form sends back with its submission.
+// It lets CreateInquiry tell how long the visitor took to fill in the form.
+func (h *InquiryHandler) GetFormToken(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error bool   `json:"error"`
+		Token string `json:"token"`
+	}{
+		Error: false,
+		Token: h.FormTokens.Issue(time.Now()),
+	})
+}
+
+// createInquiryRequest is the contact form, sent as JSON or as multipart/form-data with attachments.
+type createInquiryRequest struct {
+	models.Inquiry
+	Website   string `json:"website"` // honeypot, hidden from humans
+	FormToken string `json:"form_token"`
+}
+
 // CreateInquiry handles the submission of a new inquiry.
+// Submissions that fail the spam checks (honeypot field "website", form token, spam scorer) are stored
+// with status SPAM and no email is sent; the response is the same so bots learn nothing.
+// Sent as multipart/form-data, the form may carry up to maxInquiryAttachments files in "attachments".
 func (h *InquiryHandler) CreateInquiry(w http.ResponseWriter, r *http.Request) {
-	var req models.Inquiry
-	if err := utils.ReadJSON(w, r, &req); err != nil {
+	var body createInquiryRequest
+	var files []*multipart.FileHeader
+	if strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		var err error
+		files, err = readInquiryForm(w, r, &body)
+		if err != nil {
+			h.errorLog.Println("ERROR_01_CreateInquiry: invalid form:", err)
+			utils.BadRequest(w, fmt.Errorf("attachments too large or invalid form data (max %d MB in total)", maxInquiryUploadSize>>20))
+			return
+		}
+		defer r.MultipartForm.RemoveAll()
+	} else if err := utils.ReadJSON(w, r, &body); err != nil {
 		h.errorLog.Println("ERROR_01_CreateInquiry: invalid JSON:", err)
 		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
 		return
 	}
+	req := body.Inquiry
 
 	// Basic Validation
 	req.Name = strings.TrimSpace(req.Name)
@@ -47,38 +98,234 @@ func (h *InquiryHandler) CreateInquiry(w http.ResponseWriter, r *http.Request) {
 		utils.BadRequest(w, errors.New("All fields are required"))
 		return
 	}
+	if !utils.ValidEmail(req.Email) {
+		utils.BadRequest(w, errors.New("please enter a valid email address"))
+		return
+	}
+	if !utils.ValidPhone(req.Mobile) {
+		utils.BadRequest(w, errors.New("please enter a valid mobile number"))
+		return
+	}
+	attachments, err := checkAttachments(files)
+	if err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
 
-	// Set default status if empty
-	if req.Status == "" {
-		req.Status = "NEW"
+	// Spam checks
+	submission := &spam.Submission{
+		Name:     req.Name,
+		Email:    req.Email,
+		Mobile:   req.Mobile,
+		Subject:  req.Subject,
+		Message:  req.Message,
+		IP:       utils.ClientIP(r),
+		Honeypot: body.Website,
+	}
+	submission.TokenAge, submission.TokenErr = h.FormTokens.Verify(strings.TrimSpace(body.FormToken), time.Now())
+	result, err := h.Spam.Score(r.Context(), submission)
+	if err != nil {
+		h.errorLog.Println("ERROR_02_CreateInquiry: spam scorer error:", err)
+	}
+
+	// New inquiries always start in the workflow's initial status
+	workflow, err := h.DB.InquiryWorkflowRepo.Get(r.Context())
+	if err != nil {
+		h.errorLog.Println("ERROR_03_CreateInquiry: db error (workflow):", err)
+		utils.ServerError(w, errors.New("failed to submit inquiry"))
+		return
+	}
+	req.Status = workflow.InitialStatus()
+	req.SpamScore = result.Score
+	req.SpamReasons = strings.Join(result.Reasons, "; ")
+	req.IPAddress = submission.IP
+	// A spam score at the threshold never sends email or webhooks, even if the SPAM status is missing
+	isSpam := result.Score >= h.Config.SpamThreshold
+	if isSpam && workflow.Status(models.InquiryStatusSpam) != nil {
+		req.Status = models.InquiryStatusSpam
 	}
 
 	id, err := h.DB.InquiryRepo.Create(r.Context(), &req)
 	if err != nil {
-		h.errorLog.Println("ERROR_02_CreateInquiry: db error:", err)
+		h.errorLog.Println("ERROR_04_CreateInquiry: db error:", err)
 		utils.ServerError(w, errors.New("failed to submit inquiry"))
 		return
 	}
 
+	h.saveAttachments(r.Context(), id, attachments)
+
+	if isSpam {
+		h.infoLog.Printf("Inquiry %d from %s stored as spam (score %d): %s", id, req.IPAddress, req.SpamScore, req.SpamReasons)
+	} else {
+		h.notifyStaff(r.Context(), &req)
+		h.acknowledge(r.Context(), &req)
+		h.Webhooks.Publish(r.Context(), models.WebhookEventInquiryCreated, &req)
+	}
+
 	resp := struct {
-		Error   bool   `json:"error"`
-		Message string `json:"message"`
-		ID      int64  `json:"id"`
+		Error       bool   `json:"error"`
+		Message     string `json:"message"`
+		ID          int64  `json:"id"`
+		ReferenceNo string `json:"referenceNo"`
 	}{
-		Error:   false,
-		Message: "Inquiry submitted successfully",
-		ID:      id,
+		Error:       false,
+		Message:     "Inquiry submitted successfully",
+		ID:          id,
+		ReferenceNo: req.ReferenceNo(),
 	}
 
 	utils.WriteJSON(w, http.StatusCreated, resp)
 }
 
-// GetAllInquiries retrieves a list of all inquiries AND status counts (Admin only).
+// notifyStaff emails the new inquiry to the configured staff recipients in the background.
+// Replies go straight to the customer.
+func (h *InquiryHandler) notifyStaff(ctx context.Context, inquiry *models.Inquiry) {
+	if len(h.Config.NotifyRecipients) == 0 {
+		return
+	}
+
+	link := ""
+	if h.Config.AdminURL != "" {
+		link = fmt.Sprintf("%s?inquiry=%d", h.Config.AdminURL, inquiry.ID)
+	}
+
+	msg, err := h.Mailer.Render(ctx, "inquiry_staff_notification", map[string]any{
+		"Inquiry":     inquiry,
+		"ReferenceNo": inquiry.ReferenceNo(),
+		"Link":        link,
+	})
+	if err != nil {
+		h.errorLog.Println("ERROR_01_notifyStaff: failed to render email:", err)
+		return
+	}
+	msg.To = h.Config.NotifyRecipients
+	if _, err := mail.ParseAddress(inquiry.Email); err == nil {
+		msg.ReplyTo = inquiry.Email
+	}
+
+	h.Mailer.SendAsync(msg)
+}
+
+// acknowledge emails the submitter a confirmation with the reference number and a copy of the message.
+func (h *InquiryHandler) acknowledge(ctx context.Context, inquiry *models.Inquiry) {
+	if _, err := mail.ParseAddress(inquiry.Email); err != nil {
+		h.errorLog.Println("ERROR_01_acknowledge: invalid email, no acknowledgement sent:", inquiry.Email)
+		return
+	}
+
+	err := h.Mailer.SendTemplateAsync(ctx, "inquiry_acknowledgement", []string{inquiry.Email}, map[string]any{
+		"Inquiry":     inquiry,
+		"ReferenceNo": inquiry.ReferenceNo(),
+	})
+	if err != nil {
+		h.errorLog.Println("ERROR_02_acknowledge: failed to render email:", err)
+	}
+}
+
+// parseInquiryFilter reads the list query parameters: pageIndex (1-based), pageLength, status,
+// from and to (YYYY-MM-DD, both inclusive) and search.
+func parseInquiryFilter(r *http.Request) (models.InquiryFilter, error) {
+	queryParams := r.URL.Query()
+	f := models.InquiryFilter{PageIndex: 1, PageLength: 20}
+
+	if v := queryParams.Get("pageIndex"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil || val < 1 {
+			return f, errors.New("Invalid format for 'pageIndex'. Must be a positive integer.")
+		}
+		f.PageIndex = val
+	}
+	if v := queryParams.Get("pageLength"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil || val < 1 || val > 200 {
+			return f, errors.New("Invalid format for 'pageLength'. Must be between 1 and 200.")
+		}
+		f.PageLength = val
+	}
+
+	f.Status = strings.ToUpper(strings.TrimSpace(queryParams.Get("status")))
+	f.Search = strings.TrimSpace(queryParams.Get("search"))
+
+	if v := strings.TrimSpace(queryParams.Get("from")); v != "" {
+		from, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return f, errors.New("Invalid format for 'from'. Use YYYY-MM-DD.")
+		}
+		f.From = &from
+	}
+	if v := strings.TrimSpace(queryParams.Get("to")); v != "" {
+		to, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			return f, errors.New("Invalid format for 'to'. Use YYYY-MM-DD.")
+		}
+		// inclusive: everything before the start of the next day
+		to = to.AddDate(0, 0, 1)
+		f.To = &to
+	}
+	if f.From != nil && f.To != nil && !f.From.Before(*f.To) {
+		return f, errors.New("'from' must not be after 'to'.")
+	}
+
+	// assignee: a user ID, "me" or "none" (unassigned)
+	switch v := strings.TrimSpace(queryParams.Get("assignee")); v {
+	case "":
+	case "none":
+		f.Unassigned = true
+	case "me":
+		authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+		if !ok {
+			return f, errors.New("authentication context missing. Please log in again.")
+		}
+		f.AssignedTo = &authClaims.ID
+	default:
+		userID, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || userID < 1 {
+			return f, errors.New("Invalid format for 'assignee'. Use a user ID, 'me' or 'none'.")
+		}
+		f.AssignedTo = &userID
+	}
+
+	return f, nil
+}
+
+// GetAllInquiries retrieves one page of inquiries matching the filters, the total number of matches
+// and the status counts over all inquiries.
 func (h *InquiryHandler) GetAllInquiries(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseInquiryFilter(r)
+	if err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+
+	h.writeInquiryList(w, r, filter)
+}
+
+// GetMyInquiries is GetAllInquiries restricted to the inquiries assigned to the authenticated user.
+func (h *InquiryHandler) GetMyInquiries(w http.ResponseWriter, r *http.Request) {
+	authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+	if !ok {
+		h.errorLog.Println("ERROR_01_GetMyInquiries: authentication claims not found in context.")
+		utils.Unauthorized(w, errors.New("authentication context missing. Please log in again."))
+		return
+	}
+
+	filter, err := parseInquiryFilter(r)
+	if err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+	filter.AssignedTo = &authClaims.ID
+	filter.Unassigned = false
+
+	h.writeInquiryList(w, r, filter)
+}
+
+// writeInquiryList writes one page of the inquiry list with the status counts.
+func (h *InquiryHandler) writeInquiryList(w http.ResponseWriter, r *http.Request, filter models.InquiryFilter) {
 	ctx := r.Context()
 
-	// 1. Fetch the List
-	inquiries, err := h.DB.InquiryRepo.GetAll(ctx)
+	// 1. Fetch the page
+	inquiries, total, err := h.DB.InquiryRepo.GetAll(ctx, filter)
 	if err != nil {
 		h.errorLog.Println("ERROR_01_GetAllInquiries: db error (list):", err)
 		utils.ServerError(w, errors.New("failed to retrieve inquiries"))
@@ -98,18 +345,24 @@ func (h *InquiryHandler) GetAllInquiries(w http.ResponseWriter, r *http.Request)
 	// 3. Construct the Combined Response
 	// We define an anonymous struct here to shape the JSON
 	resp := struct {
-		Inquiries []models.Inquiry `json:"inquiries"`
-		Counts    map[string]int   `json:"counts"`
+		Inquiries  []models.Inquiry `json:"inquiries"`
+		Counts     map[string]int   `json:"counts"`
+		Total      int              `json:"total"`
+		PageIndex  int              `json:"pageIndex"`
+		PageLength int              `json:"pageLength"`
 	}{
-		Inquiries: inquiries,
-		Counts:    counts,
+		Inquiries:  inquiries,
+		Counts:     counts,
+		Total:      total,
+		PageIndex:  filter.PageIndex,
+		PageLength: filter.PageLength,
 	}
 
 	// 4. Send JSON
 	utils.WriteJSON(w, http.StatusOK, resp)
 }
 
-// GetInquiry retrieves a single inquiry by ID.
+// GetInquiry retrieves a single inquiry by ID together with its conversation thread, history and attachments.
 func (h *InquiryHandler) GetInquiry(w http.ResponseWriter, r *http.Request) {
 	// Assuming chi router is used for URL params, user standard approach otherwise
 	idStr := chi.URLParam(r, "id")
@@ -122,16 +375,223 @@ func (h *InquiryHandler) GetInquiry(w http.ResponseWriter, r *http.Request) {
 	inquiry, err := h.DB.InquiryRepo.GetByID(r.Context(), id)
 	if err != nil {
 		h.errorLog.Println("ERROR_01_GetInquiry: db error:", err)
-		// Distinguish between not found and server error if possible, defaulting to bad request/not found for simplicity
-		utils.BadRequest(w, errors.New("inquiry not found"))
+		utils.NotFound(w, "inquiry not found")
+		return
+	}
+
+	messages, err := h.DB.InquiryMessageRepo.GetByInquiry(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_02_GetInquiry: db error (messages):", err)
+		utils.ServerError(w, errors.New("failed to retrieve inquiry messages"))
+		return
+	}
+
+	assignments, err := h.DB.InquiryRepo.GetAssignments(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_03_GetInquiry: db error (assignments):", err)
+		utils.ServerError(w, errors.New("failed to retrieve inquiry assignments"))
+		return
+	}
+
+	history, err := h.DB.InquiryRepo.GetStatusHistory(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_04_GetInquiry: db error (status history):", err)
+		utils.ServerError(w, errors.New("failed to retrieve inquiry status history"))
+		return
+	}
+
+	attachments, err := h.DB.InquiryAttachmentRepo.GetByInquiry(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_05_GetInquiry: db error (attachments):", err)
+		utils.ServerError(w, errors.New("failed to retrieve inquiry attachments"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error         bool                         `json:"error"`
+		Inquiry       *models.Inquiry              `json:"inquiry"`
+		ReferenceNo   string                       `json:"referenceNo"`
+		Messages      []models.InquiryMessage      `json:"messages"`
+		Assignments   []models.InquiryAssignment   `json:"assignments"`
+		StatusHistory []models.InquiryStatusChange `json:"statusHistory"`
+		Attachments   []models.InquiryAttachment   `json:"attachments"`
+	}{
+		Error:         false,
+		Inquiry:       inquiry,
+		ReferenceNo:   inquiry.ReferenceNo(),
+		Messages:      messages,
+		Assignments:   assignments,
+		StatusHistory: history,
+		Attachments:   attachments,
+	})
+}
+
+// AddInquiryNote adds an internal note (never sent to the customer) to the inquiry in query parameter id.
+func (h *InquiryHandler) AddInquiryNote(w http.ResponseWriter, r *http.Request) {
+	inquiry, authorID, body, ok := h.readThreadMessage(w, r, "AddInquiryNote")
+	if !ok {
+		return
+	}
+
+	msg := &models.InquiryMessage{
+		InquiryID: inquiry.ID,
+		Kind:      models.InquiryMessageNote,
+		Body:      body,
+		AuthorID:  &authorID,
+	}
+	if err := h.DB.InquiryMessageRepo.Create(r.Context(), msg); err != nil {
+		h.errorLog.Println("ERROR_04_AddInquiryNote: db error:", err)
+		utils.ServerError(w, errors.New("failed to save note"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, struct {
+		Error   bool                   `json:"error"`
+		Message string                 `json:"message"`
+		Data    *models.InquiryMessage `json:"data"`
+	}{
+		Error:   false,
+		Message: "Note added successfully",
+		Data:    msg,
+	})
+}
+
+// SendInquiryReply emails a reply to the customer of the inquiry in query parameter id and adds it to the thread.
+// The reply is kept even if the email fails; its email_status tells whether it was delivered.
+func (h *InquiryHandler) SendInquiryReply(w http.ResponseWriter, r *http.Request) {
+	inquiry, authorID, body, ok := h.readThreadMessage(w, r, "SendInquiryReply")
+	if !ok {
+		return
+	}
+
+	if _, err := mail.ParseAddress(inquiry.Email); err != nil {
+		utils.BadRequest(w, errors.New("the inquiry has no valid email address to reply to"))
+		return
+	}
+
+	staffName := ""
+	if author, err := h.DB.UserRepo.GetUserByID(r.Context(), authorID); err == nil {
+		staffName = author.Name
+	}
+
+	email, err := h.Mailer.Render(r.Context(), "inquiry_reply", map[string]any{
+		"Inquiry":     inquiry,
+		"ReferenceNo": inquiry.ReferenceNo(),
+		"Body":        body,
+		"StaffName":   staffName,
+	})
+	if err != nil {
+		h.errorLog.Println("ERROR_04_SendInquiryReply: failed to render email:", err)
+		utils.ServerError(w, errors.New("failed to prepare reply email"))
+		return
+	}
+	email.To = []string{inquiry.Email}
+	email.ReplyTo = h.Config.ReplyTo
+
+	msg := &models.InquiryMessage{
+		InquiryID: inquiry.ID,
+		Kind:      models.InquiryMessageReply,
+		Body:      body,
+		AuthorID:  &authorID,
+		Recipient: inquiry.Email,
+	}
+	if err := h.DB.InquiryMessageRepo.Create(r.Context(), msg); err != nil {
+		h.errorLog.Println("ERROR_05_SendInquiryReply: db error:", err)
+		utils.ServerError(w, errors.New("failed to save reply"))
 		return
 	}
 
-	utils.WriteJSON(w, http.StatusOK, inquiry)
+	msg.EmailStatus = models.EmailStatusSent
+	if err := h.Mailer.Send(r.Context(), email); err != nil {
+		h.errorLog.Println("ERROR_06_SendInquiryReply: failed to send email:", err)
+		msg.EmailStatus = models.EmailStatusFailed
+	}
+	if err := h.DB.InquiryMessageRepo.SetEmailStatus(r.Context(), msg.ID, msg.EmailStatus); err != nil {
+		h.errorLog.Println("ERROR_07_SendInquiryReply: db error:", err)
+	}
+
+	if msg.EmailStatus == models.EmailStatusFailed {
+		utils.WriteJSON(w, http.StatusBadGateway, struct {
+			Error   bool                   `json:"error"`
+			Message string                 `json:"message"`
+			Data    *models.InquiryMessage `json:"data"`
+		}{
+			Error:   true,
+			Message: "The reply was saved but the email could not be sent",
+			Data:    msg,
+		})
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, struct {
+		Error   bool                   `json:"error"`
+		Message string                 `json:"message"`
+		Data    *models.InquiryMessage `json:"data"`
+	}{
+		Error:   false,
+		Message: "Reply sent successfully",
+		Data:    msg,
+	})
+}
+
+// readThreadMessage loads the inquiry from query parameter id, the author from the JWT claims and the
+// message body { body } from the request. It writes the error response and returns false on failure.
+func (h *InquiryHandler) readThreadMessage(w http.ResponseWriter, r *http.Request, caller string) (*models.Inquiry, int64, string, bool) {
+	type messageRequest struct {
+		Body string `json:"body"`
+	}
+
+	authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+	if !ok {
+		h.errorLog.Printf("ERROR_01_%s: authentication claims not found in context.", caller)
+		utils.Unauthorized(w, errors.New("authentication context missing. Please log in again."))
+		return nil, 0, "", false
+	}
+
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid inquiry ID"))
+		return nil, 0, "", false
+	}
+
+	var req messageRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Printf("ERROR_02_%s: invalid JSON: %v", caller, err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return nil, 0, "", false
+	}
+	req.Body = strings.TrimSpace(req.Body)
+	if req.Body == "" {
+		utils.BadRequest(w, errors.New("message body is required"))
+		return nil, 0, "", false
+	}
+
+	inquiry, err := h.DB.InquiryRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Printf("ERROR_03_%s: fetch error: %v", caller, err)
+		utils.NotFound(w, "inquiry not found")
+		return nil, 0, "", false
+	}
+
+	return inquiry, authClaims.ID, req.Body, true
 }
 
 // UpdateInquiry handles updating an inquiry's status or details.
+// A status change must be allowed by the workflow and is recorded in the status history together with
+// the optional comment.
 func (h *InquiryHandler) UpdateInquiry(w http.ResponseWriter, r *http.Request) {
+	type updateRequest struct {
+		models.Inquiry
+		Comment string `json:"comment"`
+	}
+
+	authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+	if !ok {
+		h.errorLog.Println("ERROR_01_UpdateInquiry: authentication claims not found in context.")
+		utils.Unauthorized(w, errors.New("authentication context missing. Please log in again."))
+		return
+	}
+
 	idStr := strings.TrimSpace(r.URL.Query().Get("id"))
 	id, err := strconv.ParseInt(idStr, 10, 64)
 	if err != nil {
@@ -142,15 +602,15 @@ func (h *InquiryHandler) UpdateInquiry(w http.ResponseWriter, r *http.Request) {
 	// 1. Fetch existing inquiry
 	existing, err := h.DB.InquiryRepo.GetByID(r.Context(), id)
 	if err != nil {
-		h.errorLog.Println("ERROR_01_UpdateInquiry: fetch error:", err)
+		h.errorLog.Println("ERROR_02_UpdateInquiry: fetch error:", err)
 		utils.BadRequest(w, errors.New("inquiry not found"))
 		return
 	}
 
 	// 2. Decode update payload
-	var req models.Inquiry
+	var req updateRequest
 	if err := utils.ReadJSON(w, r, &req); err != nil {
-		h.errorLog.Println("ERROR_02_UpdateInquiry: invalid JSON:", err)
+		h.errorLog.Println("ERROR_03_UpdateInquiry: invalid JSON:", err)
 		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
 		return
 	}
@@ -172,19 +632,58 @@ func (h *InquiryHandler) UpdateInquiry(w http.ResponseWriter, r *http.Request) {
 	if req.Message != "" {
 		existing.Message = req.Message
 	}
-	if req.Status != "" {
-		// Optional: Add validation for allowed status values here
-		existing.Status = req.Status
+
+	// 4. Validate the status change against the workflow
+	var change *models.InquiryStatusChange
+	status := strings.ToUpper(strings.TrimSpace(req.Status))
+	if status != "" && status != existing.Status {
+		workflow, err := h.DB.InquiryWorkflowRepo.Get(r.Context())
+		if err != nil {
+			h.errorLog.Println("ERROR_04_UpdateInquiry: db error (workflow):", err)
+			utils.ServerError(w, errors.New("failed to update inquiry"))
+			return
+		}
+		if workflow.Status(status) == nil {
+			utils.BadRequest(w, fmt.Errorf("unknown status %q", status))
+			return
+		}
+		if !workflow.Allows(existing.Status, status) {
+			next := workflow.Next(existing.Status)
+			if len(next) == 0 {
+				utils.BadRequest(w, fmt.Errorf("an inquiry in status %s cannot change status", existing.Status))
+				return
+			}
+			utils.BadRequest(w, fmt.Errorf("cannot change status from %s to %s, allowed: %s",
+				existing.Status, status, strings.Join(next, ", ")))
+			return
+		}
+
+		changedBy := authClaims.ID
+		change = &models.InquiryStatusChange{
+			From:      existing.Status,
+			To:        status,
+			ChangedBy: &changedBy,
+			Comment:   strings.TrimSpace(req.Comment),
+		}
 	}
 
-	// 4. Perform Update
-	err = h.DB.InquiryRepo.Update(r.Context(), existing)
+	// 5. Perform Update
+	err = h.DB.InquiryRepo.Update(r.Context(), existing, change)
 	if err != nil {
-		h.errorLog.Println("ERROR_03_UpdateInquiry: update error:", err)
+		if errors.Is(err, dbrepo.ErrStatusConflict) {
+			utils.BadRequest(w, err)
+			return
+		}
+		h.errorLog.Println("ERROR_05_UpdateInquiry: update error:", err)
 		utils.ServerError(w, errors.New("failed to update inquiry"))
 		return
 	}
 
+	if change != nil {
+		change.ChangedByName = authClaims.Name
+		h.publishStatusChange(r.Context(), existing, change)
+	}
+
 	utils.WriteJSON(w, http.StatusOK, struct {
 		Error   bool            `json:"error"`
 		Message string          `json:"message"`
@@ -196,6 +695,17 @@ func (h *InquiryHandler) UpdateInquiry(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// publishStatusChange sends the inquiry.status_changed webhook event.
+func (h *InquiryHandler) publishStatusChange(ctx context.Context, inquiry *models.Inquiry, change *models.InquiryStatusChange) {
+	h.Webhooks.Publish(ctx, models.WebhookEventInquiryStatusChanged, struct {
+		Inquiry *models.Inquiry             `json:"inquiry"`
+		Change  *models.InquiryStatusChange `json:"change"`
+	}{
+		Inquiry: inquiry,
+		Change:  change,
+	})
+}
+
 // DeleteInquiry removes an inquiry from the database.
 func (h *InquiryHandler) DeleteInquiry(w http.ResponseWriter, r *http.Request) {
 	idStr := strings.TrimSpace(r.URL.Query().Get("id"))
@@ -212,6 +722,11 @@ func (h *InquiryHandler) DeleteInquiry(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// The attachment rows are deleted with the inquiry, the files are removed here
+	if err := os.RemoveAll(inquiryAttachmentDir(id)); err != nil {
+		h.errorLog.Println("ERROR_02_DeleteInquiry: remove attachments:", err)
+	}
+
 	utils.WriteJSON(w, http.StatusOK, struct {
 		Error   bool   `json:"error"`
 		Message string `json:"message"`
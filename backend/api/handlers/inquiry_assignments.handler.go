@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+
+	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+)
+
+// AssignInquiry assigns the inquiry in query parameter id to a staff member, or unassigns it when
+// assignedTo is null. The change is kept in the inquiry's assignment history and the new assignee is emailed.
+func (h *InquiryHandler) AssignInquiry(w http.ResponseWriter, r *http.Request) {
+	type assignRequest struct {
+		AssignedTo *int64 `json:"assignedTo"`
+		Note       string `json:"note"`
+	}
+
+	authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+	if !ok {
+		h.errorLog.Println("ERROR_01_AssignInquiry: authentication claims not found in context.")
+		utils.Unauthorized(w, errors.New("authentication context missing. Please log in again."))
+		return
+	}
+
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid inquiry ID"))
+		return
+	}
+
+	var req assignRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_02_AssignInquiry: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+	req.Note = strings.TrimSpace(req.Note)
+
+	// The assignee must be an active user who can see inquiries
+	var assignee *models.User
+	if req.AssignedTo != nil {
+		assignee, err = h.DB.UserRepo.GetUserByID(r.Context(), *req.AssignedTo)
+		if err != nil {
+			utils.BadRequest(w, errors.New("assignee not found"))
+			return
+		}
+		if assignee.Status == "Inactive" || !models.HasPermission(assignee.Role, models.PermInquiryRead) {
+			utils.BadRequest(w, errors.New("inquiries can only be assigned to active users who can view them"))
+			return
+		}
+	}
+
+	inquiry, err := h.DB.InquiryRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_03_AssignInquiry: fetch error:", err)
+		utils.NotFound(w, "inquiry not found")
+		return
+	}
+
+	assignment, err := h.DB.InquiryRepo.Assign(r.Context(), id, req.AssignedTo, authClaims.ID, req.Note)
+	if err != nil {
+		if errors.Is(err, dbrepo.ErrAssignmentUnchanged) {
+			utils.BadRequest(w, err)
+			return
+		}
+		h.errorLog.Println("ERROR_04_AssignInquiry: db error:", err)
+		utils.ServerError(w, errors.New("failed to assign inquiry"))
+		return
+	}
+
+	if assignee != nil && assignee.ID != authClaims.ID {
+		h.notifyAssignee(r, inquiry, assignee, authClaims.Name, req.Note)
+	}
+
+	message := "Inquiry unassigned successfully"
+	if assignee != nil {
+		assignment.AssigneeName = assignee.Name
+		message = "Inquiry assigned to " + assignee.Name
+	}
+	assignment.AssignedByName = authClaims.Name
+
+	inquiry.AssignedTo = req.AssignedTo
+	inquiry.AssigneeName = assignment.AssigneeName
+	h.Webhooks.Publish(r.Context(), models.WebhookEventInquiryAssigned, struct {
+		Inquiry    *models.Inquiry           `json:"inquiry"`
+		Assignment *models.InquiryAssignment `json:"assignment"`
+	}{
+		Inquiry:    inquiry,
+		Assignment: assignment,
+	})
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error   bool                      `json:"error"`
+		Message string                    `json:"message"`
+		Data    *models.InquiryAssignment `json:"data"`
+	}{
+		Error:   false,
+		Message: message,
+		Data:    assignment,
+	})
+}
+
+// notifyAssignee tells the new assignee about the inquiry in the background.
+func (h *InquiryHandler) notifyAssignee(r *http.Request, inquiry *models.Inquiry, assignee *models.User, assignedBy, note string) {
+	if _, err := mail.ParseAddress(assignee.Email); err != nil {
+		return
+	}
+
+	link := ""
+	if h.Config.AdminURL != "" {
+		link = fmt.Sprintf("%s?inquiry=%d", h.Config.AdminURL, inquiry.ID)
+	}
+
+	err := h.Mailer.SendTemplateAsync(r.Context(), "inquiry_assigned", []string{assignee.Email}, map[string]any{
+		"Inquiry":     inquiry,
+		"ReferenceNo": inquiry.ReferenceNo(),
+		"Assignee":    assignee.Name,
+		"AssignedBy":  assignedBy,
+		"Note":        note,
+		"Link":        link,
+	})
+	if err != nil {
+		h.errorLog.Println("ERROR_01_notifyAssignee: failed to render email:", err)
+	}
+}
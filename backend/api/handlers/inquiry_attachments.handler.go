@@ -0,0 +1,205 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+)
+
+// Limits of the files sent with the contact form
+const (
+	maxInquiryAttachments   = 5
+	maxInquiryAttachment    = 10 << 20 // per file
+	maxInquiryUploadSize    = 25 << 20 // whole request
+	inquiryFormMemoryBuffer = 1 << 20  // larger parts are buffered in temporary files
+)
+
+// allowedAttachmentTypes maps the sniffed content types that are accepted to the extension used on disk.
+var allowedAttachmentTypes = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/gif":       ".gif",
+	"image/webp":      ".webp",
+}
+
+// pendingAttachment is an uploaded file that passed the checks and waits for the inquiry to be saved.
+type pendingAttachment struct {
+	header      *multipart.FileHeader
+	contentType string
+}
+
+// inquiryAttachmentDir is the directory holding the files of an inquiry, next to data/images.
+func inquiryAttachmentDir(inquiryID int64) string {
+	return filepath.Join("data", "attachments", "inquiries", strconv.FormatInt(inquiryID, 10))
+}
+
+// readInquiryForm parses a multipart contact form into req and returns its files (field "attachments").
+// The caller must call r.MultipartForm.RemoveAll once the files are saved.
+func readInquiryForm(w http.ResponseWriter, r *http.Request, req *createInquiryRequest) ([]*multipart.FileHeader, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxInquiryUploadSize)
+	if err := r.ParseMultipartForm(inquiryFormMemoryBuffer); err != nil {
+		return nil, err
+	}
+
+	req.Name = r.FormValue("name")
+	req.Mobile = r.FormValue("mobile")
+	req.Email = r.FormValue("email")
+	req.Subject = r.FormValue("subject")
+	req.Message = r.FormValue("message")
+	req.Website = r.FormValue("website")
+	req.FormToken = r.FormValue("form_token")
+
+	return r.MultipartForm.File["attachments"], nil
+}
+
+// checkAttachments enforces the number, size and type limits. The type is detected from the content,
+// not trusted from the client.
+func checkAttachments(files []*multipart.FileHeader) ([]pendingAttachment, error) {
+	if len(files) > maxInquiryAttachments {
+		return nil, fmt.Errorf("at most %d attachments are allowed", maxInquiryAttachments)
+	}
+
+	pending := make([]pendingAttachment, 0, len(files))
+	for _, fh := range files {
+		if fh.Size > maxInquiryAttachment {
+			return nil, fmt.Errorf("%s is larger than %d MB", attachmentName(fh.Filename), maxInquiryAttachment>>20)
+		}
+		if fh.Size == 0 {
+			return nil, fmt.Errorf("%s is empty", attachmentName(fh.Filename))
+		}
+
+		f, err := fh.Open()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s", attachmentName(fh.Filename))
+		}
+		head := make([]byte, 512)
+		n, _ := io.ReadFull(f, head)
+		f.Close()
+
+		contentType := http.DetectContentType(head[:n])
+		if _, ok := allowedAttachmentTypes[contentType]; !ok {
+			return nil, fmt.Errorf("%s is not allowed, only PDF and image files (JPEG, PNG, GIF, WebP) are accepted", attachmentName(fh.Filename))
+		}
+		pending = append(pending, pendingAttachment{header: fh, contentType: contentType})
+	}
+
+	return pending, nil
+}
+
+// saveAttachments writes the files of a new inquiry to disk and records them.
+// Failures are logged only: the inquiry is already saved and the customer should not submit it twice.
+func (h *InquiryHandler) saveAttachments(ctx context.Context, inquiryID int64, pending []pendingAttachment) {
+	if len(pending) == 0 {
+		return
+	}
+
+	dir := inquiryAttachmentDir(inquiryID)
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		h.errorLog.Println("ERROR_01_saveAttachments: mkdir:", err)
+		return
+	}
+
+	for _, p := range pending {
+		token, err := utils.GenerateOpaqueToken(16)
+		if err != nil {
+			h.errorLog.Println("ERROR_02_saveAttachments: file name:", err)
+			return
+		}
+		a := &models.InquiryAttachment{
+			InquiryID:   inquiryID,
+			FileName:    attachmentName(p.header.Filename),
+			StoredName:  token + allowedAttachmentTypes[p.contentType],
+			ContentType: p.contentType,
+			Size:        p.header.Size,
+		}
+
+		if err := saveUploadedFile(p.header, filepath.Join(dir, a.StoredName)); err != nil {
+			h.errorLog.Println("ERROR_03_saveAttachments: save file:", err)
+			continue
+		}
+		if err := h.DB.InquiryAttachmentRepo.Create(ctx, a); err != nil {
+			h.errorLog.Println("ERROR_04_saveAttachments: db error:", err)
+			os.Remove(filepath.Join(dir, a.StoredName))
+		}
+	}
+}
+
+// saveUploadedFile copies an uploaded file to path.
+func saveUploadedFile(fh *multipart.FileHeader, path string) error {
+	src, err := fh.Open()
+	if err != nil {
+		return err
+	}
+	defer src.Close()
+
+	dst, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(dst, src); err != nil {
+		dst.Close()
+		os.Remove(path)
+		return err
+	}
+	return dst.Close()
+}
+
+// attachmentName reduces a client supplied file name to its base name, as shown to staff.
+func attachmentName(name string) string {
+	name = filepath.Base(strings.ReplaceAll(name, "\\", "/"))
+	name = strings.TrimSpace(strings.Map(func(r rune) rune {
+		if r < 0x20 || r == 0x7f {
+			return -1
+		}
+		return r
+	}, name))
+	if name == "" || name == "." || name == "/" {
+		name = "attachment"
+	}
+	if len(name) > 255 {
+		name = name[len(name)-255:]
+	}
+	return name
+}
+
+// GetInquiryAttachment downloads the attachment {id}. Attachments are never served publicly.
+func (h *InquiryHandler) GetInquiryAttachment(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid attachment ID"))
+		return
+	}
+
+	a, err := h.DB.InquiryAttachmentRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_01_GetInquiryAttachment: db error:", err)
+		utils.NotFound(w, "attachment not found")
+		return
+	}
+
+	f, err := os.Open(filepath.Join(inquiryAttachmentDir(a.InquiryID), a.StoredName))
+	if err != nil {
+		h.errorLog.Println("ERROR_02_GetInquiryAttachment: open file:", err)
+		utils.NotFound(w, "attachment file not found")
+		return
+	}
+	defer f.Close()
+
+	w.Header().Set("Content-Type", a.ContentType)
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": a.FileName}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", a.CreatedAt, f)
+}
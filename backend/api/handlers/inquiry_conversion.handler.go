@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+)
+
+// ConvertInquiry creates a client from the inquiry in query parameter id and marks the inquiry CONVERTED.
+// The body may override the client fields taken from the inquiry; area is required because the contact
+// form does not ask for it. Body: { name, area, service_name, service_date, status, note, comment }.
+func (h *InquiryHandler) ConvertInquiry(w http.ResponseWriter, r *http.Request) {
+	type convertRequest struct {
+		Name        string `json:"name"`
+		Area        string `json:"area"`
+		ServiceName string `json:"service_name"`
+		ServiceDate string `json:"service_date"`
+		Status      string `json:"status"`
+		Note        string `json:"note"`
+		Comment     string `json:"comment"`
+	}
+
+	authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+	if !ok {
+		h.errorLog.Println("ERROR_01_ConvertInquiry: authentication claims not found in context.")
+		utils.Unauthorized(w, errors.New("authentication context missing. Please log in again."))
+		return
+	}
+
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid inquiry ID"))
+		return
+	}
+
+	var req convertRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_02_ConvertInquiry: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	inquiry, err := h.DB.InquiryRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_03_ConvertInquiry: fetch error:", err)
+		utils.NotFound(w, "inquiry not found")
+		return
+	}
+	if inquiry.ClientID != nil {
+		utils.BadRequest(w, dbrepo.ErrInquiryConverted)
+		return
+	}
+
+	workflow, err := h.DB.InquiryWorkflowRepo.Get(r.Context())
+	if err != nil {
+		h.errorLog.Println("ERROR_04_ConvertInquiry: db error (workflow):", err)
+		utils.ServerError(w, errors.New("failed to convert inquiry"))
+		return
+	}
+	if workflow.Status(models.InquiryStatusConverted) == nil {
+		utils.BadRequest(w, fmt.Errorf("the workflow has no %s status", models.InquiryStatusConverted))
+		return
+	}
+	if !workflow.Allows(inquiry.Status, models.InquiryStatusConverted) {
+		utils.BadRequest(w, fmt.Errorf("an inquiry in status %s cannot be converted to a client", inquiry.Status))
+		return
+	}
+
+	// Client details default to the inquiry's
+	client := &models.Client{
+		Name:        firstNonEmpty(req.Name, inquiry.Name),
+		Area:        strings.TrimSpace(req.Area),
+		ServiceName: firstNonEmpty(req.ServiceName, inquiry.Subject),
+		ServiceDate: strings.TrimSpace(req.ServiceDate),
+		Status:      firstNonEmpty(req.Status, "Active"),
+		Note:        firstNonEmpty(req.Note, "Converted from inquiry "+inquiry.ReferenceNo()),
+	}
+	if client.Area == "" {
+		utils.BadRequest(w, errors.New("area is required"))
+		return
+	}
+
+	changedBy := authClaims.ID
+	change := &models.InquiryStatusChange{
+		From:      inquiry.Status,
+		To:        models.InquiryStatusConverted,
+		ChangedBy: &changedBy,
+		Comment:   firstNonEmpty(req.Comment, "Converted to client"),
+	}
+
+	if err := h.DB.InquiryRepo.ConvertToClient(r.Context(), id, client, change); err != nil {
+		if errors.Is(err, dbrepo.ErrInquiryConverted) || errors.Is(err, dbrepo.ErrStatusConflict) {
+			utils.BadRequest(w, err)
+			return
+		}
+		h.errorLog.Println("ERROR_05_ConvertInquiry: db error:", err)
+		utils.ServerError(w, errors.New("failed to convert inquiry"))
+		return
+	}
+
+	inquiry.ClientID = &client.ID
+	inquiry.Status = change.To
+	change.ChangedByName = authClaims.Name
+
+	h.publishStatusChange(r.Context(), inquiry, change)
+	h.Webhooks.Publish(r.Context(), models.WebhookEventClientCreated, client)
+	h.Webhooks.Publish(r.Context(), models.WebhookEventInquiryConverted, struct {
+		Inquiry *models.Inquiry `json:"inquiry"`
+		Client  *models.Client  `json:"client"`
+	}{
+		Inquiry: inquiry,
+		Client:  client,
+	})
+
+	utils.WriteJSON(w, http.StatusCreated, struct {
+		Error   bool            `json:"error"`
+		Message string          `json:"message"`
+		Client  *models.Client  `json:"client"`
+		Inquiry *models.Inquiry `json:"inquiry"`
+	}{
+		Error:   false,
+		Message: "Inquiry converted to client successfully",
+		Client:  client,
+		Inquiry: inquiry,
+	})
+}
+
+// firstNonEmpty returns the first of values that is not blank, trimmed.
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v = strings.TrimSpace(v); v != "" {
+			return v
+		}
+	}
+	return ""
+}
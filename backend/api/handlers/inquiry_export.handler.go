@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+	"github.com/projuktisheba/ajfses/backend/internal/xlsx"
+)
+
+// inquiryExportColumns are the column titles of the export, in the order of inquiryExportValues.
+var inquiryExportColumns = []string{
+	"ID", "Reference No", "Inquiry Date", "Name", "Mobile", "Email", "Subject", "Message", "Status",
+	"Assigned To", "Client ID", "Spam Score", "Created At", "Updated At",
+	"Status Changes", "Last Status Change", "Last Changed By", "Status History",
+}
+
+// inquiryExportValues returns the cells of one export row. Times are time.Time and numbers int64/int,
+// so the XLSX writer can type them; the CSV writer formats them as text.
+func inquiryExportValues(row *models.InquiryExportRow) []any {
+	var clientID, lastChange any
+	if row.ClientID != nil {
+		clientID = *row.ClientID
+	}
+	if row.LastStatusChange != nil {
+		lastChange = *row.LastStatusChange
+	}
+	return []any{
+		row.ID, row.ReferenceNo(), row.InquiryDate, row.Name, row.Mobile, row.Email, row.Subject, row.Message, row.Status,
+		row.AssigneeName, clientID, row.SpamScore, row.CreatedAt, row.UpdatedAt,
+		row.StatusChanges, lastChange, row.LastChangedBy, row.StatusHistory,
+	}
+}
+
+// ExportInquiries streams every inquiry matching the list filters (status, from, to, search, assignee)
+// as a spreadsheet. Query parameter format is csv (default) or xlsx; paging parameters are ignored.
+func (h *InquiryHandler) ExportInquiries(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseInquiryFilter(r)
+	if err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+
+	format := strings.ToLower(strings.TrimSpace(r.URL.Query().Get("format")))
+	if format == "" {
+		format = "csv"
+	}
+	if format != "csv" && format != "xlsx" {
+		utils.BadRequest(w, errors.New("format must be csv or xlsx"))
+		return
+	}
+
+	fileName := fmt.Sprintf("inquiries-%s.%s", time.Now().Format("20060102"), format)
+	w.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, fileName))
+
+	// Once rows are streamed the status code is sent, so later errors can only be logged
+	if format == "xlsx" {
+		w.Header().Set("Content-Type", "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet")
+		err = h.exportXLSX(w, r, filter)
+	} else {
+		w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+		err = h.exportCSV(w, r, filter)
+	}
+	if err != nil {
+		h.errorLog.Println("ERROR_01_ExportInquiries: export failed:", err)
+	}
+}
+
+func (h *InquiryHandler) exportCSV(w http.ResponseWriter, r *http.Request, filter models.InquiryFilter) error {
+	// The byte order mark makes Excel read the file as UTF-8
+	if _, err := w.Write([]byte("\xEF\xBB\xBF")); err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	if err := cw.Write(inquiryExportColumns); err != nil {
+		return err
+	}
+
+	err := h.DB.InquiryRepo.Export(r.Context(), filter, func(row *models.InquiryExportRow) error {
+		values := inquiryExportValues(row)
+		record := make([]string, len(values))
+		for i, v := range values {
+			record[i] = csvCell(v)
+		}
+		return cw.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+func (h *InquiryHandler) exportXLSX(w http.ResponseWriter, r *http.Request, filter models.InquiryFilter) error {
+	xw, err := xlsx.NewWriter(w, "Inquiries")
+	if err != nil {
+		return err
+	}
+	if err := xw.WriteHeader(inquiryExportColumns...); err != nil {
+		return err
+	}
+
+	err = h.DB.InquiryRepo.Export(r.Context(), filter, func(row *models.InquiryExportRow) error {
+		return xw.WriteRow(inquiryExportValues(row)...)
+	})
+	if err != nil {
+		return err
+	}
+
+	return xw.Close()
+}
+
+// csvCell formats a value for CSV. Text that a spreadsheet would run as a formula is prefixed with an
+// apostrophe, since the exported fields come from the public contact form.
+func csvCell(v any) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+	case time.Time:
+		return v.Format("2006-01-02 15:04")
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case string:
+		if v != "" && strings.ContainsRune("=+-@\t\r", rune(v[0])) {
+			return "'" + v
+		}
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+)
+
+// Range of the inquiry analytics report
+const (
+	defaultStatsDays = 90
+	maxStatsDays     = 3 * 366
+)
+
+// GetInquiryStats returns inquiry volume per day, week and month, the average time to first response and
+// to resolution, and a breakdown by subject. Query parameters from and to (YYYY-MM-DD, inclusive) default
+// to the last 90 days; includeSpam=true counts SPAM inquiries too. Other parameters are rejected.
+func (h *InquiryHandler) GetInquiryStats(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+	// The report covers every inquiry in the range; list filters such as status are refused rather than ignored
+	for key := range queryParams {
+		if key != "from" && key != "to" && key != "includeSpam" {
+			utils.BadRequest(w, fmt.Errorf("unsupported parameter '%s'. Only from, to and includeSpam are allowed.", key))
+			return
+		}
+	}
+
+	now := time.Now()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local).AddDate(0, 0, 1)
+	if v := strings.TrimSpace(queryParams.Get("to")); v != "" {
+		date, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			utils.BadRequest(w, errors.New("Invalid format for 'to'. Use YYYY-MM-DD."))
+			return
+		}
+		// inclusive: everything before the start of the next day
+		to = date.AddDate(0, 0, 1)
+	}
+	from := to.AddDate(0, 0, -defaultStatsDays)
+	if v := strings.TrimSpace(queryParams.Get("from")); v != "" {
+		date, err := time.ParseInLocation("2006-01-02", v, time.Local)
+		if err != nil {
+			utils.BadRequest(w, errors.New("Invalid format for 'from'. Use YYYY-MM-DD."))
+			return
+		}
+		from = date
+	}
+	if !from.Before(to) {
+		utils.BadRequest(w, errors.New("'from' must not be after 'to'."))
+		return
+	}
+	if to.Sub(from) > maxStatsDays*24*time.Hour {
+		utils.BadRequest(w, errors.New("the range must not be longer than 3 years"))
+		return
+	}
+
+	includeSpam := false
+	if v := queryParams.Get("includeSpam"); v != "" {
+		var err error
+		if includeSpam, err = strconv.ParseBool(v); err != nil {
+			utils.BadRequest(w, errors.New("Invalid format for 'includeSpam'. Use true or false."))
+			return
+		}
+	}
+
+	stats, err := h.DB.InquiryRepo.GetStats(r.Context(), from, to, includeSpam)
+	if err != nil {
+		h.errorLog.Println("ERROR_01_GetInquiryStats: db error:", err)
+		utils.ServerError(w, errors.New("failed to retrieve inquiry statistics"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error bool                 `json:"error"`
+		Stats *models.InquiryStats `json:"stats"`
+	}{
+		Error: false,
+		Stats: stats,
+	})
+}
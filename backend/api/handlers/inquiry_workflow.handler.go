@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+)
+
+// maxStatusCodeLength matches inquiries.status VARCHAR(20).
+const maxStatusCodeLength = 20
+
+// GetInquiryWorkflow returns the configured inquiry statuses and allowed transitions.
+func (h *InquiryHandler) GetInquiryWorkflow(w http.ResponseWriter, r *http.Request) {
+	workflow, err := h.DB.InquiryWorkflowRepo.Get(r.Context())
+	if err != nil {
+		h.errorLog.Println("ERROR_01_GetInquiryWorkflow: db error:", err)
+		utils.ServerError(w, errors.New("failed to retrieve inquiry workflow"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error    bool                    `json:"error"`
+		Workflow *models.InquiryWorkflow `json:"workflow"`
+	}{
+		Error:    false,
+		Workflow: workflow,
+	})
+}
+
+// UpdateInquiryWorkflow replaces the inquiry statuses and transitions with the ones in the request.
+// Statuses left out are removed, which is refused while inquiries still use them.
+func (h *InquiryHandler) UpdateInquiryWorkflow(w http.ResponseWriter, r *http.Request) {
+	var req models.InquiryWorkflow
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_01_UpdateInquiryWorkflow: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	if err := normalizeWorkflow(&req); err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+
+	if err := h.DB.InquiryWorkflowRepo.Replace(r.Context(), &req); err != nil {
+		if errors.Is(err, dbrepo.ErrStatusInUse) {
+			utils.BadRequest(w, err)
+			return
+		}
+		h.errorLog.Println("ERROR_02_UpdateInquiryWorkflow: db error:", err)
+		utils.ServerError(w, errors.New("failed to update inquiry workflow"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error    bool                    `json:"error"`
+		Message  string                  `json:"message"`
+		Workflow *models.InquiryWorkflow `json:"workflow"`
+	}{
+		Error:    false,
+		Message:  "Inquiry workflow updated successfully",
+		Workflow: &req,
+	})
+}
+
+// normalizeWorkflow upper-cases the status codes and checks that the workflow is usable: unique codes,
+// exactly one initial status, a SPAM status for flagged submissions and transitions between known, distinct statuses.
+func normalizeWorkflow(wf *models.InquiryWorkflow) error {
+	if len(wf.Statuses) == 0 {
+		return errors.New("at least one status is required")
+	}
+
+	seen := map[string]bool{}
+	initial := 0
+	for i := range wf.Statuses {
+		s := &wf.Statuses[i]
+		s.Code = strings.ToUpper(strings.TrimSpace(s.Code))
+		s.Label = strings.TrimSpace(s.Label)
+		if s.Code == "" {
+			return errors.New("status code is required")
+		}
+		if len(s.Code) > maxStatusCodeLength {
+			return fmt.Errorf("status code %q is longer than %d characters", s.Code, maxStatusCodeLength)
+		}
+		if seen[s.Code] {
+			return fmt.Errorf("duplicate status %s", s.Code)
+		}
+		seen[s.Code] = true
+		if s.Label == "" {
+			s.Label = s.Code
+		}
+		if s.IsInitial {
+			initial++
+		}
+	}
+	if initial != 1 {
+		return errors.New("exactly one status must be the initial status")
+	}
+	if !seen[models.InquiryStatusSpam] {
+		return fmt.Errorf("the %s status is required for submissions flagged by the spam checks", models.InquiryStatusSpam)
+	}
+
+	pairs := map[models.InquiryTransition]bool{}
+	transitions := make([]models.InquiryTransition, 0, len(wf.Transitions))
+	for _, t := range wf.Transitions {
+		t.From = strings.ToUpper(strings.TrimSpace(t.From))
+		t.To = strings.ToUpper(strings.TrimSpace(t.To))
+		if !seen[t.From] || !seen[t.To] {
+			return fmt.Errorf("transition %s -> %s uses an unknown status", t.From, t.To)
+		}
+		if t.From == t.To {
+			return fmt.Errorf("transition %s -> %s does not change the status", t.From, t.To)
+		}
+		if pairs[t] {
+			continue
+		}
+		pairs[t] = true
+		transitions = append(transitions, t)
+	}
+	wf.Transitions = transitions
+
+	return nil
+}
@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"io"
@@ -15,17 +16,20 @@ import (
 	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
 	"github.com/projuktisheba/ajfses/backend/internal/models"
 	"github.com/projuktisheba/ajfses/backend/internal/utils"
+	"github.com/projuktisheba/ajfses/backend/internal/webhook"
 )
 
 type MemberHandler struct {
 	DB       *dbrepo.DBRepository
+	Webhooks *webhook.Dispatcher
 	infoLog  *log.Logger
 	errorLog *log.Logger
 }
 
-func newMemberHandler(db *dbrepo.DBRepository, infoLog, errorLog *log.Logger) MemberHandler {
+func newMemberHandler(db *dbrepo.DBRepository, hooks *webhook.Dispatcher, infoLog, errorLog *log.Logger) MemberHandler {
 	return MemberHandler{
 		DB:       db,
+		Webhooks: hooks,
 		infoLog:  infoLog,
 		errorLog: errorLog,
 	}
@@ -79,6 +83,8 @@ func (h *MemberHandler) CreateMember(w http.ResponseWriter, r *http.Request) {
 		utils.ServerError(w, errors.New("failed to save member info"))
 		return
 	}
+	// The member exists from here on, whether or not the image is saved
+	defer h.publishMember(r.Context(), models.WebhookEventMemberCreated, id)
 
 	// 4. STEP TWO: Save Image to File System
 	file, header, err := r.FormFile("profileImage")
@@ -447,6 +453,8 @@ func (h *MemberHandler) UpdateMember(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	h.Webhooks.Publish(r.Context(), models.WebhookEventMemberUpdated, existing)
+
 	utils.WriteJSON(w, http.StatusOK, struct {
 		Error   bool           `json:"error"`
 		Message string         `json:"message"`
@@ -484,6 +492,8 @@ func (h *MemberHandler) DeleteMember(w http.ResponseWriter, r *http.Request) {
 	//silently delete the image from the filesystem
 	os.Remove(filepath.Join("data", "images", member.ImageLink))
 
+	h.Webhooks.Publish(r.Context(), models.WebhookEventMemberDeleted, member)
+
 	utils.WriteJSON(w, http.StatusOK, struct {
 		Error   bool   `json:"error"`
 		Message string `json:"message"`
@@ -493,6 +503,16 @@ func (h *MemberHandler) DeleteMember(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// publishMember sends a webhook event with the member as stored, including the image link saved last.
+func (h *MemberHandler) publishMember(ctx context.Context, event string, id int64) {
+	member, err := h.DB.MemberRepo.GetByID(ctx, id)
+	if err != nil {
+		h.errorLog.Println("ERROR_publishMember_01: fetch error:", err)
+		return
+	}
+	h.Webhooks.Publish(ctx, event, member)
+}
+
 func (h *MemberHandler) respondSuccess(w http.ResponseWriter, id int64, msg string) {
 	utils.WriteJSON(w, http.StatusCreated, struct {
 		Error   bool   `json:"error"`
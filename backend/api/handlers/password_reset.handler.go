@@ -0,0 +1,149 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+)
+
+// resetRequestsPerHour caps the reset emails sent to one account
+const resetRequestsPerHour = 3
+
+// ForgotPassword emails a single-use, time-limited reset link to the account with the given email.
+// The response is the same whether or not the account exists, so it cannot be used to probe for users.
+func (h *AuthHandler) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	type forgotRequest struct {
+		Email string `json:"email"`
+	}
+
+	var req forgotRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_01_ForgotPassword: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	req.Email = strings.TrimSpace(req.Email)
+	if req.Email == "" || !strings.Contains(req.Email, "@") {
+		utils.BadRequest(w, errors.New("a valid email is required"))
+		return
+	}
+
+	if h.ipThrottled(w, r) {
+		return
+	}
+
+	resp := models.Response{
+		Error:   false,
+		Message: "If an account exists for this email, a password reset link has been sent.",
+	}
+
+	user, err := h.DB.UserRepo.GetUserByUsername(r.Context(), req.Email)
+	if err != nil || !strings.EqualFold(user.Email, req.Email) || user.Status == "Inactive" {
+		h.errorLog.Println("ERROR_02_ForgotPassword: no active account for email:", req.Email)
+		utils.WriteJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	recent, err := h.DB.PasswordResetRepo.CountRecent(r.Context(), user.ID, time.Now().Add(-time.Hour))
+	if err != nil {
+		h.errorLog.Println("ERROR_03_ForgotPassword: db error:", err)
+		utils.ServerError(w, errors.New("failed to process request"))
+		return
+	}
+	if recent >= resetRequestsPerHour {
+		h.errorLog.Println("ERROR_04_ForgotPassword: too many reset requests for user:", user.ID)
+		utils.WriteJSON(w, http.StatusOK, resp)
+		return
+	}
+
+	token, err := utils.GenerateOpaqueToken(32)
+	if err != nil {
+		h.errorLog.Println("ERROR_05_ForgotPassword: failed to generate token:", err)
+		utils.ServerError(w, errors.New("failed to generate token"))
+		return
+	}
+
+	reset := &models.PasswordResetToken{
+		UserID:    user.ID,
+		TokenHash: utils.HashToken(token),
+		ExpiresAt: time.Now().Add(h.LoginConfig.ResetTokenTTL),
+		IPAddress: utils.ClientIP(r),
+	}
+	if err := h.DB.PasswordResetRepo.Create(r.Context(), reset); err != nil {
+		h.errorLog.Println("ERROR_06_ForgotPassword: db error:", err)
+		utils.ServerError(w, errors.New("failed to process request"))
+		return
+	}
+
+	// Send in the background so response time does not reveal whether the account exists
+	data := map[string]any{
+		"Name":             user.Name,
+		"ResetLink":        h.PasswordResetURL + "?token=" + url.QueryEscape(token),
+		"ExpiresInMinutes": int(h.LoginConfig.ResetTokenTTL.Minutes()),
+	}
+	if err := h.Mailer.SendTemplateAsync(r.Context(), "password_reset", []string{user.Email}, data); err != nil {
+		h.errorLog.Println("ERROR_07_ForgotPassword: failed to render email:", err)
+	}
+
+	h.infoLog.Printf("Password reset requested for user ID %d.", user.ID)
+	utils.WriteJSON(w, http.StatusOK, resp)
+}
+
+// ResetPassword sets a new password using a token from ForgotPassword.
+// The token is single-use; on success every session of the user is revoked and a lock is lifted.
+func (h *AuthHandler) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	type resetRequest struct {
+		Token       string `json:"token"`
+		NewPassword string `json:"newPassword"`
+	}
+
+	var req resetRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_01_ResetPassword: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	req.Token = strings.TrimSpace(req.Token)
+	req.NewPassword = strings.TrimSpace(req.NewPassword)
+	if req.Token == "" {
+		utils.BadRequest(w, errors.New("reset token is required"))
+		return
+	}
+	if len(req.NewPassword) < 6 {
+		utils.BadRequest(w, errors.New("new password must be at least 6 characters long"))
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.NewPassword)
+	if err != nil {
+		h.errorLog.Println("ERROR_02_ResetPassword: failed to hash password:", err)
+		utils.ServerError(w, errors.New("internal server error during password hashing"))
+		return
+	}
+
+	userID, err := h.DB.PasswordResetRepo.Consume(r.Context(), utils.HashToken(req.Token), hashedPassword)
+	if err != nil {
+		if errors.Is(err, dbrepo.ErrResetTokenInvalid) {
+			utils.BadRequest(w, errors.New("the reset link is invalid or has expired"))
+			return
+		}
+		h.errorLog.Println("ERROR_03_ResetPassword: db error:", err)
+		utils.ServerError(w, errors.New("failed to reset password"))
+		return
+	}
+
+	h.infoLog.Printf("User ID %d reset their password by email.", userID)
+	utils.WriteJSON(w, http.StatusOK, models.Response{
+		Error:   false,
+		Message: "Password has been reset. Please sign in with your new password.",
+	})
+}
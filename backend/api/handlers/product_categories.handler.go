@@ -0,0 +1,328 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+)
+
+// Category images and brand logos are served by the /api/v1/images file server.
+var (
+	categoryImageDir = filepath.Join("data", "images", "categories")
+	brandLogoDir     = filepath.Join("data", "images", "brands")
+)
+
+// slugPattern is the form of category and brand slugs, e.g. "fire-detection-system".
+var slugPattern = regexp.MustCompile(`^[a-z0-9]+(-[a-z0-9]+)*$`)
+
+// slugify derives a slug from a name: lower case letters and digits separated by single dashes.
+func slugify(name string) string {
+	var b strings.Builder
+	dash := false
+	for _, c := range strings.ToLower(name) {
+		if (c >= 'a' && c <= 'z') || (c >= '0' && c <= '9') {
+			if dash && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			b.WriteRune(c)
+			dash = false
+			continue
+		}
+		dash = true
+	}
+	return b.String()
+}
+
+// validSlug checks the slug of a category or brand.
+func validSlug(slug string) error {
+	if slug == "" || len(slug) > 100 || !slugPattern.MatchString(slug) {
+		return errors.New("slug must be at most 100 lower case letters and digits separated by dashes")
+	}
+	return nil
+}
+
+// readCatalogueImage returns the uploaded image of field name, or nil when none was sent.
+func readCatalogueImage(r *http.Request, name string) (*pendingAttachment, error) {
+	files := r.MultipartForm.File[name]
+	if len(files) == 0 {
+		return nil, nil
+	}
+
+	fh := files[0]
+	contentType, err := checkProductUpload(fh, maxProductImage)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := productImageTypes[contentType]; !ok {
+		return nil, fmt.Errorf("%s is not allowed, only JPEG, PNG, GIF and WebP images are accepted", attachmentName(fh.Filename))
+	}
+	return &pendingAttachment{header: fh, contentType: contentType}, nil
+}
+
+// saveCatalogueImage stores an uploaded category image or brand logo in dir and returns its file name.
+func saveCatalogueImage(img *pendingAttachment, dir, slug string) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", err
+	}
+	token, err := utils.GenerateOpaqueToken(8)
+	if err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s_%s%s", slug, token, productImageTypes[img.contentType])
+	if err := saveUploadedFile(img.header, filepath.Join(dir, name)); err != nil {
+		return "", err
+	}
+	return name, nil
+}
+
+// readCategoryForm parses a multipart category form and applies the fields that were sent to c.
+// Fields: name, slug (derived from the name when empty), parent_id (empty for a top-level category),
+// description, sort_order; file: image.
+func readCategoryForm(w http.ResponseWriter, r *http.Request, c *models.ProductCategory) (*pendingAttachment, error) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxProductImage+productFormMemoryBuffer)
+	if err := r.ParseMultipartForm(productFormMemoryBuffer); err != nil {
+		return nil, errors.New("invalid form data or image too large")
+	}
+
+	if v, ok := formField(r, "name"); ok {
+		c.Name = v
+	}
+	if v, ok := formField(r, "slug"); ok {
+		c.Slug = strings.ToLower(v)
+	}
+	if v, ok := formField(r, "parent_id"); ok {
+		id, err := optionalID(v)
+		if err != nil {
+			return nil, errors.New("invalid parent_id")
+		}
+		c.ParentID = id
+	}
+	if v, ok := formField(r, "description"); ok {
+		c.Description = v
+	}
+	if v, ok := formField(r, "sort_order"); ok && v != "" {
+		order, err := strconv.Atoi(v)
+		if err != nil {
+			return nil, errors.New("sort_order must be a whole number")
+		}
+		c.SortOrder = order
+	}
+
+	if c.Name == "" {
+		return nil, errors.New("name is required")
+	}
+	if len(c.Name) > 100 {
+		return nil, errors.New("name is limited to 100 characters")
+	}
+	if c.Slug == "" {
+		c.Slug = slugify(c.Name)
+	}
+	if err := validSlug(c.Slug); err != nil {
+		return nil, err
+	}
+
+	return readCatalogueImage(r, "image")
+}
+
+// GetProductCategories returns the category tree.
+func (h *ProductHandler) GetProductCategories(w http.ResponseWriter, r *http.Request) {
+	categories, err := h.DB.ProductCategoryRepo.GetTree(r.Context())
+	if err != nil {
+		h.errorLog.Println("ERROR_GetProductCategories_01: db error:", err)
+		utils.ServerError(w, errors.New("failed to retrieve categories"))
+		return
+	}
+
+	var response struct {
+		Error      bool                      `json:"error"`
+		Message    string                    `json:"message"`
+		Categories []*models.ProductCategory `json:"categories"`
+	}
+	response.Error = false
+	response.Message = "Categories fetched successfully"
+	response.Categories = categories
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetProductsByCategory lists the active products of category {slug} and its subcategories.
+// Query parameters: brand, search, page, limit (all optional).
+func (h *ProductHandler) GetProductsByCategory(w http.ResponseWriter, r *http.Request) {
+	category, err := h.DB.ProductCategoryRepo.GetBySlug(r.Context(), chi.URLParam(r, "slug"))
+	if err != nil {
+		h.errorLog.Println("ERROR_GetProductsByCategory_01: db error:", err)
+		utils.NotFound(w, "category not found")
+		return
+	}
+
+	h.writeProductList(w, r, false, category, nil)
+}
+
+// CreateProductCategory adds a category from a multipart form (see readCategoryForm).
+func (h *ProductHandler) CreateProductCategory(w http.ResponseWriter, r *http.Request) {
+	category := &models.ProductCategory{}
+	image, err := readCategoryForm(w, r, category)
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+	if err != nil {
+		h.errorLog.Println("ERROR_CreateProductCategory_01: invalid form:", err)
+		utils.BadRequest(w, err)
+		return
+	}
+
+	if image != nil {
+		category.ImageLink, err = saveCatalogueImage(image, categoryImageDir, category.Slug)
+		if err != nil {
+			h.errorLog.Println("ERROR_CreateProductCategory_02: save image:", err)
+			utils.ServerError(w, errors.New("failed to save image"))
+			return
+		}
+	}
+
+	if err := h.DB.ProductCategoryRepo.Create(r.Context(), category); err != nil {
+		if category.ImageLink != "" {
+			os.Remove(filepath.Join(categoryImageDir, category.ImageLink))
+		}
+		if errors.Is(err, dbrepo.ErrCategorySlugTaken) || errors.Is(err, dbrepo.ErrCategoryParentMissing) {
+			utils.BadRequest(w, err)
+			return
+		}
+		h.errorLog.Println("ERROR_CreateProductCategory_03: db create:", err)
+		utils.ServerError(w, errors.New("failed to save category"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, struct {
+		Error   bool                    `json:"error"`
+		Message string                  `json:"message"`
+		Data    *models.ProductCategory `json:"data"`
+	}{
+		Error:   false,
+		Message: "Category created successfully",
+		Data:    category,
+	})
+}
+
+// UpdateProductCategory changes the fields sent in the form and replaces the image when a new one is uploaded.
+func (h *ProductHandler) UpdateProductCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid category ID"))
+		return
+	}
+
+	category, err := h.DB.ProductCategoryRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_UpdateProductCategory_01: fetch error:", err)
+		utils.NotFound(w, "category not found")
+		return
+	}
+	oldImageLink := category.ImageLink
+
+	image, err := readCategoryForm(w, r, category)
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+	if err != nil {
+		h.errorLog.Println("ERROR_UpdateProductCategory_02: invalid form:", err)
+		utils.BadRequest(w, err)
+		return
+	}
+
+	if category.ParentID != nil {
+		cycle, err := h.DB.ProductCategoryRepo.WouldCycle(r.Context(), id, *category.ParentID)
+		if err != nil {
+			h.errorLog.Println("ERROR_UpdateProductCategory_03: check tree:", err)
+			utils.ServerError(w, errors.New("failed to update category"))
+			return
+		}
+		if cycle {
+			utils.BadRequest(w, errors.New("a category cannot be moved under itself or one of its subcategories"))
+			return
+		}
+	}
+
+	if image != nil {
+		category.ImageLink, err = saveCatalogueImage(image, categoryImageDir, category.Slug)
+		if err != nil {
+			h.errorLog.Println("ERROR_UpdateProductCategory_04: save image:", err)
+			utils.ServerError(w, errors.New("failed to save image"))
+			return
+		}
+	}
+
+	if err := h.DB.ProductCategoryRepo.Update(r.Context(), category); err != nil {
+		if image != nil {
+			os.Remove(filepath.Join(categoryImageDir, category.ImageLink))
+		}
+		if errors.Is(err, dbrepo.ErrCategorySlugTaken) || errors.Is(err, dbrepo.ErrCategoryParentMissing) {
+			utils.BadRequest(w, err)
+			return
+		}
+		h.errorLog.Println("ERROR_UpdateProductCategory_05: db update:", err)
+		utils.ServerError(w, errors.New("failed to update category"))
+		return
+	}
+
+	if image != nil && oldImageLink != "" {
+		os.Remove(filepath.Join(categoryImageDir, oldImageLink))
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error   bool                    `json:"error"`
+		Message string                  `json:"message"`
+		Data    *models.ProductCategory `json:"data"`
+	}{
+		Error:   false,
+		Message: "Category updated successfully",
+		Data:    category,
+	})
+}
+
+// DeleteProductCategory removes a category without subcategories; its products are kept without a category.
+func (h *ProductHandler) DeleteProductCategory(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid category ID"))
+		return
+	}
+
+	category, err := h.DB.ProductCategoryRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_DeleteProductCategory_01: fetch error:", err)
+		utils.NotFound(w, "category not found")
+		return
+	}
+
+	if err := h.DB.ProductCategoryRepo.Delete(r.Context(), id); err != nil {
+		if errors.Is(err, dbrepo.ErrCategoryHasChildren) {
+			utils.BadRequest(w, err)
+			return
+		}
+		h.errorLog.Println("ERROR_DeleteProductCategory_02: db error:", err)
+		utils.ServerError(w, errors.New("failed to delete category"))
+		return
+	}
+
+	if category.ImageLink != "" {
+		os.Remove(filepath.Join(categoryImageDir, category.ImageLink))
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error   bool   `json:"error"`
+		Message string `json:"message"`
+	}{
+		Error:   false,
+		Message: "Category deleted successfully",
+	})
+}
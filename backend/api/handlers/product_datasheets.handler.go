@@ -0,0 +1,320 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+)
+
+// pdfHeader is the version line every PDF file starts with, e.g. "%PDF-1.7".
+var pdfHeader = regexp.MustCompile(`^%PDF-[12]\.[0-9]`)
+
+// pdfTrailerWindow is how far from the end the %%EOF marker is looked for; writers may append
+// whitespace or a few bytes of garbage after it.
+const pdfTrailerWindow = 1024
+
+// checkPDF accepts only complete PDF documents, whatever the file's name or declared type:
+// the content must start with the %PDF- version header and end with the %%EOF marker.
+func checkPDF(fh *multipart.FileHeader) error {
+	name := attachmentName(fh.Filename)
+	if fh.Size > maxProductDatasheet {
+		return fmt.Errorf("%s is larger than %d MB", name, maxProductDatasheet>>20)
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return fmt.Errorf("failed to read %s", name)
+	}
+	defer f.Close()
+
+	head := make([]byte, 8)
+	if _, err := f.ReadAt(head, 0); err != nil || !pdfHeader.Match(head) {
+		return fmt.Errorf("%s is not a PDF file", name)
+	}
+
+	tailSize := int64(pdfTrailerWindow)
+	if fh.Size < tailSize {
+		tailSize = fh.Size
+	}
+	tail := make([]byte, tailSize)
+	if _, err := f.ReadAt(tail, fh.Size-tailSize); err != nil && err != io.EOF {
+		return fmt.Errorf("failed to read %s", name)
+	}
+	if !bytes.Contains(tail, []byte("%%EOF")) {
+		return fmt.Errorf("%s is incomplete or damaged: the PDF end marker is missing", name)
+	}
+
+	return nil
+}
+
+// requestUserID returns the ID of the signed-in user, or nil on public routes.
+func requestUserID(r *http.Request) *int64 {
+	claims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+	if !ok {
+		return nil
+	}
+	return &claims.ID
+}
+
+// storeDatasheet saves an uploaded spec sheet (already checked with checkPDF) as the product's new current version.
+func (h *ProductHandler) storeDatasheet(ctx context.Context, productID int64, fh *multipart.FileHeader, uploadedBy *int64) (*models.ProductDatasheet, error) {
+	if err := os.MkdirAll(productDatasheetDir, 0755); err != nil {
+		return nil, err
+	}
+	token, err := utils.GenerateOpaqueToken(8)
+	if err != nil {
+		return nil, err
+	}
+
+	sheet := &models.ProductDatasheet{
+		ProductID:  productID,
+		FileName:   attachmentName(fh.Filename),
+		StoredName: fmt.Sprintf("%d_%s.pdf", productID, token),
+		Size:       fh.Size,
+		UploadedBy: uploadedBy,
+	}
+	path := filepath.Join(productDatasheetDir, sheet.StoredName)
+	if err := saveUploadedFile(fh, path); err != nil {
+		return nil, err
+	}
+	if err := h.DB.ProductDatasheetRepo.Add(ctx, sheet); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	return sheet, nil
+}
+
+// datasheetDownloadName is the file name a spec sheet is served under, e.g. "101 Smoke Detector datasheet.pdf".
+// Versions downloaded by staff carry their number.
+func datasheetDownloadName(p *models.Product, d *models.ProductDatasheet, withVersion bool) string {
+	name := strings.NewReplacer("/", "-", "\\", "-").Replace(p.Code + " " + p.Name + " datasheet")
+	if withVersion {
+		name += fmt.Sprintf(" v%d", d.Version)
+	}
+	return attachmentName(name + ".pdf")
+}
+
+// serveDatasheet writes the spec sheet as a PDF, shown in the browser unless the query parameter download is set.
+func serveDatasheet(w http.ResponseWriter, r *http.Request, p *models.Product, d *models.ProductDatasheet, withVersion bool) error {
+	f, err := os.Open(filepath.Join(productDatasheetDir, d.StoredName))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	disposition := "inline"
+	if r.URL.Query().Has("download") {
+		disposition = "attachment"
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": datasheetDownloadName(p, d, withVersion)}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", d.CreatedAt, f)
+	return nil
+}
+
+// setDatasheetLink fills the staff download path of a datasheet version.
+func setDatasheetLink(d *models.ProductDatasheet) {
+	d.URL = fmt.Sprintf("/api/v1/product/datasheets/file/%d", d.ID)
+}
+
+// GetProductDatasheets lists every version of the spec sheet of product {id}, newest first.
+func (h *ProductHandler) GetProductDatasheets(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid product ID"))
+		return
+	}
+
+	if _, err := h.DB.ProductRepo.GetByID(r.Context(), id); err != nil {
+		h.errorLog.Println("ERROR_GetProductDatasheets_01: fetch error:", err)
+		utils.NotFound(w, "product not found")
+		return
+	}
+
+	sheets, err := h.DB.ProductDatasheetRepo.GetByProduct(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetProductDatasheets_02: db error:", err)
+		utils.ServerError(w, errors.New("failed to retrieve datasheets"))
+		return
+	}
+	for i := range sheets {
+		setDatasheetLink(&sheets[i])
+	}
+
+	var response struct {
+		Error      bool                      `json:"error"`
+		Message    string                    `json:"message"`
+		Datasheets []models.ProductDatasheet `json:"datasheets"`
+	}
+	response.Error = false
+	response.Message = "Datasheets fetched successfully"
+	response.Datasheets = sheets
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetProductDatasheetFile serves any kept version {id} of a spec sheet to staff.
+func (h *ProductHandler) GetProductDatasheetFile(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid datasheet ID"))
+		return
+	}
+
+	sheet, err := h.DB.ProductDatasheetRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetProductDatasheetFile_01: db error:", err)
+		utils.NotFound(w, "datasheet not found")
+		return
+	}
+	product, err := h.DB.ProductRepo.GetByID(r.Context(), sheet.ProductID)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetProductDatasheetFile_02: fetch product:", err)
+		utils.NotFound(w, "product not found")
+		return
+	}
+
+	if err := serveDatasheet(w, r, product, sheet, true); err != nil {
+		h.errorLog.Println("ERROR_GetProductDatasheetFile_03: open file:", err)
+		utils.NotFound(w, "datasheet file not found")
+	}
+}
+
+// UploadProductDatasheet adds a new version of the spec sheet of a product (multipart file "datasheet")
+// and makes it the current one. Earlier versions are kept.
+func (h *ProductHandler) UploadProductDatasheet(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid product ID"))
+		return
+	}
+
+	if _, err := h.DB.ProductRepo.GetByID(r.Context(), id); err != nil {
+		h.errorLog.Println("ERROR_UploadProductDatasheet_01: fetch error:", err)
+		utils.NotFound(w, "product not found")
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxProductDatasheet+productFormMemoryBuffer)
+	if err := r.ParseMultipartForm(productFormMemoryBuffer); err != nil {
+		h.errorLog.Println("ERROR_UploadProductDatasheet_02: parse form:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid form data or file larger than %d MB", maxProductDatasheet>>20))
+		return
+	}
+	defer r.MultipartForm.RemoveAll()
+
+	files := r.MultipartForm.File["datasheet"]
+	if len(files) == 0 {
+		utils.BadRequest(w, errors.New("datasheet file is required"))
+		return
+	}
+	if err := checkPDF(files[0]); err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+
+	sheet, err := h.storeDatasheet(r.Context(), id, files[0], requestUserID(r))
+	if err != nil {
+		h.errorLog.Println("ERROR_UploadProductDatasheet_03: save datasheet:", err)
+		utils.ServerError(w, errors.New("failed to save datasheet"))
+		return
+	}
+	setDatasheetLink(sheet)
+
+	h.publishProduct(r.Context(), models.WebhookEventProductUpdated, id)
+
+	utils.WriteJSON(w, http.StatusCreated, struct {
+		Error   bool                     `json:"error"`
+		Message string                   `json:"message"`
+		Data    *models.ProductDatasheet `json:"data"`
+	}{
+		Error:   false,
+		Message: fmt.Sprintf("Datasheet version %d uploaded successfully", sheet.Version),
+		Data:    sheet,
+	})
+}
+
+// SetCurrentProductDatasheet makes a kept version {id} the product's current spec sheet again.
+func (h *ProductHandler) SetCurrentProductDatasheet(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid datasheet ID"))
+		return
+	}
+
+	if err := h.DB.ProductDatasheetRepo.SetCurrent(r.Context(), id); err != nil {
+		h.errorLog.Println("ERROR_SetCurrentProductDatasheet_01: db error:", err)
+		utils.NotFound(w, "datasheet not found")
+		return
+	}
+
+	sheet, err := h.DB.ProductDatasheetRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_SetCurrentProductDatasheet_02: fetch error:", err)
+		utils.ServerError(w, errors.New("failed to load datasheet"))
+		return
+	}
+	setDatasheetLink(sheet)
+
+	h.publishProduct(r.Context(), models.WebhookEventProductUpdated, sheet.ProductID)
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error   bool                     `json:"error"`
+		Message string                   `json:"message"`
+		Data    *models.ProductDatasheet `json:"data"`
+	}{
+		Error:   false,
+		Message: fmt.Sprintf("Datasheet version %d is now current", sheet.Version),
+		Data:    sheet,
+	})
+}
+
+// DeleteProductDatasheet removes a version {id} of a spec sheet and its file. Deleting the current version
+// leaves the product without a spec sheet until another version is made current.
+func (h *ProductHandler) DeleteProductDatasheet(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid datasheet ID"))
+		return
+	}
+
+	sheet, err := h.DB.ProductDatasheetRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_DeleteProductDatasheet_01: fetch error:", err)
+		utils.NotFound(w, "datasheet not found")
+		return
+	}
+
+	if err := h.DB.ProductDatasheetRepo.Delete(r.Context(), id); err != nil {
+		h.errorLog.Println("ERROR_DeleteProductDatasheet_02: db error:", err)
+		utils.ServerError(w, errors.New("failed to delete datasheet"))
+		return
+	}
+	os.Remove(filepath.Join(productDatasheetDir, sheet.StoredName))
+
+	h.publishProduct(r.Context(), models.WebhookEventProductUpdated, sheet.ProductID)
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error   bool   `json:"error"`
+		Message string `json:"message"`
+	}{
+		Error:   false,
+		Message: "Datasheet deleted successfully",
+	})
+}
@@ -0,0 +1,609 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+	"github.com/projuktisheba/ajfses/backend/internal/webhook"
+)
+
+// Limits of the product form
+const (
+	maxProductImages        = 10
+	maxProductImage         = 5 << 20  // per image
+	maxProductDatasheet     = 20 << 20 // spec sheet PDF
+	maxProductUploadSize    = 80 << 20 // whole request
+	maxProductSpecs         = 50
+	productFormMemoryBuffer = 1 << 20 // larger parts are buffered in temporary files
+)
+
+// productImageTypes maps the sniffed content types accepted as product images to the extension used on disk.
+var productImageTypes = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// productCodePattern restricts codes to characters that are safe in URLs and file names.
+var productCodePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// Product files live next to the other uploads; images are served by the /api/v1/images file server,
+// spec sheets by GetProductDatasheet.
+var (
+	productImageDir     = filepath.Join("data", "images", "products")
+	productDatasheetDir = filepath.Join("data", "datasheets", "products")
+)
+
+// ProductHandler serves the product catalogue.
+type ProductHandler struct {
+	DB       *dbrepo.DBRepository
+	Webhooks *webhook.Dispatcher
+	infoLog  *log.Logger
+	errorLog *log.Logger
+}
+
+func newProductHandler(db *dbrepo.DBRepository, hooks *webhook.Dispatcher, infoLog, errorLog *log.Logger) ProductHandler {
+	return ProductHandler{
+		DB:       db,
+		Webhooks: hooks,
+		infoLog:  infoLog,
+		errorLog: errorLog,
+	}
+}
+
+// productFiles are the uploads of a product form that passed the checks.
+type productFiles struct {
+	images    []pendingAttachment
+	datasheet *multipart.FileHeader
+}
+
+// readProductForm parses a multipart product form and applies the fields that were sent to p, so an update
+// can clear a field by sending it empty. Once the form is parsed the caller must call r.MultipartForm.RemoveAll,
+// even when an error is returned.
+//
+// Fields: code, name, category_id, brand_id (empty for none), description, specifications (JSON [{ label, value }]),
+// is_active, sort_order; files: images (repeatable) and datasheet (PDF).
+func readProductForm(w http.ResponseWriter, r *http.Request, p *models.Product) (productFiles, error) {
+	var files productFiles
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxProductUploadSize)
+	if err := r.ParseMultipartForm(productFormMemoryBuffer); err != nil {
+		return files, errors.New("invalid form data or files too large")
+	}
+
+	if v, ok := formField(r, "code"); ok {
+		p.Code = v
+	}
+	if v, ok := formField(r, "name"); ok {
+		p.Name = v
+	}
+	if v, ok := formField(r, "category_id"); ok {
+		id, err := optionalID(v)
+		if err != nil {
+			return files, errors.New("invalid category_id")
+		}
+		p.CategoryID = id
+	}
+	if v, ok := formField(r, "brand_id"); ok {
+		id, err := optionalID(v)
+		if err != nil {
+			return files, errors.New("invalid brand_id")
+		}
+		p.BrandID = id
+	}
+	if v, ok := formField(r, "description"); ok {
+		p.Description = v
+	}
+	if v, ok := formField(r, "specifications"); ok {
+		specs, err := parseProductSpecs(v)
+		if err != nil {
+			return files, err
+		}
+		p.Specifications = specs
+	}
+	if v, ok := formField(r, "is_active"); ok && v != "" {
+		active, err := strconv.ParseBool(v)
+		if err != nil {
+			return files, errors.New("is_active must be true or false")
+		}
+		p.IsActive = active
+	}
+	if v, ok := formField(r, "sort_order"); ok && v != "" {
+		order, err := strconv.Atoi(v)
+		if err != nil {
+			return files, errors.New("sort_order must be a whole number")
+		}
+		p.SortOrder = order
+	}
+
+	if err := validateProduct(p); err != nil {
+		return files, err
+	}
+
+	images := r.MultipartForm.File["images"]
+	if len(images) > maxProductImages {
+		return files, fmt.Errorf("at most %d images can be uploaded at once", maxProductImages)
+	}
+	for _, fh := range images {
+		contentType, err := checkProductUpload(fh, maxProductImage)
+		if err != nil {
+			return files, err
+		}
+		if _, ok := productImageTypes[contentType]; !ok {
+			return files, fmt.Errorf("%s is not allowed, only JPEG, PNG, GIF and WebP images are accepted", attachmentName(fh.Filename))
+		}
+		files.images = append(files.images, pendingAttachment{header: fh, contentType: contentType})
+	}
+
+	if sheets := r.MultipartForm.File["datasheet"]; len(sheets) > 0 {
+		if err := checkPDF(sheets[0]); err != nil {
+			return files, err
+		}
+		files.datasheet = sheets[0]
+	}
+
+	return files, nil
+}
+
+// parseProductSpecs decodes the specifications field, dropping empty rows.
+func parseProductSpecs(v string) ([]models.ProductSpec, error) {
+	specs := []models.ProductSpec{}
+	if v == "" {
+		return specs, nil
+	}
+
+	var rows []models.ProductSpec
+	if err := json.Unmarshal([]byte(v), &rows); err != nil {
+		return nil, errors.New("specifications must be a JSON list of { label, value }")
+	}
+	for _, s := range rows {
+		s.Label = strings.TrimSpace(s.Label)
+		s.Value = strings.TrimSpace(s.Value)
+		if s.Label == "" && s.Value == "" {
+			continue
+		}
+		if s.Label == "" {
+			return nil, errors.New("every specification needs a label")
+		}
+		if len(s.Label) > 100 || len(s.Value) > 500 {
+			return nil, errors.New("specification labels are limited to 100 characters and values to 500")
+		}
+		specs = append(specs, s)
+	}
+	if len(specs) > maxProductSpecs {
+		return nil, fmt.Errorf("at most %d specifications are allowed", maxProductSpecs)
+	}
+
+	return specs, nil
+}
+
+// validateProduct checks the fields of a product about to be saved.
+func validateProduct(p *models.Product) error {
+	switch {
+	case p.Code == "" || p.Name == "":
+		return errors.New("code and name are required")
+	case len(p.Code) > 50 || !productCodePattern.MatchString(p.Code):
+		return errors.New("code must be at most 50 letters, digits, dots, dashes or underscores")
+	case len(p.Name) > 255:
+		return errors.New("name is limited to 255 characters")
+	}
+	return nil
+}
+
+// formField returns the trimmed value of a multipart form field and whether it was sent at all.
+func formField(r *http.Request, name string) (string, bool) {
+	v, ok := r.MultipartForm.Value[name]
+	if !ok || len(v) == 0 {
+		return "", false
+	}
+	return strings.TrimSpace(v[0]), true
+}
+
+// optionalID parses an optional reference to another record; an empty value means none.
+func optionalID(v string) (*int64, error) {
+	if v == "" {
+		return nil, nil
+	}
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil || id < 1 {
+		return nil, errors.New("invalid ID")
+	}
+	return &id, nil
+}
+
+// checkProductUpload enforces the size limit and returns the content type detected from the file's content.
+func checkProductUpload(fh *multipart.FileHeader, maxSize int64) (string, error) {
+	if fh.Size > maxSize {
+		return "", fmt.Errorf("%s is larger than %d MB", attachmentName(fh.Filename), maxSize>>20)
+	}
+	if fh.Size == 0 {
+		return "", fmt.Errorf("%s is empty", attachmentName(fh.Filename))
+	}
+
+	f, err := fh.Open()
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s", attachmentName(fh.Filename))
+	}
+	defer f.Close()
+
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(f, head)
+	return http.DetectContentType(head[:n]), nil
+}
+
+// saveProductFiles stores the new images and the spec sheet of product p and records them.
+// The spec sheet becomes the product's current version.
+func (h *ProductHandler) saveProductFiles(ctx context.Context, p *models.Product, files productFiles, uploadedBy *int64) error {
+	for _, img := range files.images {
+		if err := os.MkdirAll(productImageDir, 0755); err != nil {
+			return err
+		}
+		token, err := utils.GenerateOpaqueToken(8)
+		if err != nil {
+			return err
+		}
+		image := &models.ProductImage{
+			ProductID: p.ID,
+			ImageLink: fmt.Sprintf("%d_%s%s", p.ID, token, productImageTypes[img.contentType]),
+			AltText:   p.Name,
+		}
+		path := filepath.Join(productImageDir, image.ImageLink)
+		if err := saveUploadedFile(img.header, path); err != nil {
+			return err
+		}
+		if err := h.DB.ProductRepo.AddImage(ctx, image); err != nil {
+			os.Remove(path)
+			return err
+		}
+	}
+
+	if files.datasheet != nil {
+		if _, err := h.storeDatasheet(ctx, p.ID, files.datasheet, uploadedBy); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// setProductLinks fills the public download path of the product's current spec sheet.
+func setProductLinks(p *models.Product) {
+	if p.Datasheet != nil {
+		p.Datasheet.URL = fmt.Sprintf("/api/v1/product/datasheet/%d", p.ID)
+	}
+}
+
+// GetAllProducts lists the active products for the public catalogue.
+// Query parameters: category and brand (slugs), search, page, limit (all optional).
+func (h *ProductHandler) GetAllProducts(w http.ResponseWriter, r *http.Request) {
+	h.writeProductList(w, r, false, nil, nil)
+}
+
+// GetAllProductsAdmin lists every product, including inactive ones, for the admin panel.
+func (h *ProductHandler) GetAllProductsAdmin(w http.ResponseWriter, r *http.Request) {
+	h.writeProductList(w, r, true, nil, nil)
+}
+
+// writeProductList writes a page of products. A category or brand, when given, restricts the list
+// and is included in the response.
+func (h *ProductHandler) writeProductList(w http.ResponseWriter, r *http.Request, includeInactive bool, category *models.ProductCategory, brand *models.Brand) {
+	queryParams := r.URL.Query()
+
+	filter := models.ProductFilter{
+		Category:        strings.TrimSpace(queryParams.Get("category")),
+		Brand:           strings.TrimSpace(queryParams.Get("brand")),
+		Search:          strings.TrimSpace(queryParams.Get("search")),
+		IncludeInactive: includeInactive,
+		Page:            1,
+		Limit:           50,
+	}
+	if v := queryParams.Get("page"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil || val < 1 {
+			utils.BadRequest(w, errors.New("Invalid format for 'page'. Must be a positive integer."))
+			return
+		}
+		filter.Page = val
+	}
+	if v := queryParams.Get("limit"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil || val < 1 || val > 100 {
+			utils.BadRequest(w, errors.New("Invalid format for 'limit'. Must be between 1 and 100."))
+			return
+		}
+		filter.Limit = val
+	}
+	if category != nil {
+		filter.Category = category.Slug
+	}
+	if brand != nil {
+		filter.Brand = brand.Slug
+	}
+
+	products, total, err := h.DB.ProductRepo.GetAll(r.Context(), filter)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetAllProducts_01: db error:", err)
+		utils.ServerError(w, errors.New("failed to retrieve products"))
+		return
+	}
+	for _, p := range products {
+		setProductLinks(p)
+	}
+
+	var response struct {
+		Error    bool                    `json:"error"`
+		Message  string                  `json:"message"`
+		Category *models.ProductCategory `json:"category,omitempty"`
+		Brand    *models.Brand           `json:"brand,omitempty"`
+		Products []*models.Product       `json:"products"`
+		Total    int                     `json:"total"`
+		Page     int                     `json:"page"`
+		Limit    int                     `json:"limit"`
+	}
+	response.Error = false
+	response.Message = "Products fetched successfully"
+	response.Category = category
+	response.Brand = brand
+	response.Products = products
+	response.Total = total
+	response.Page = filter.Page
+	response.Limit = filter.Limit
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetProduct returns an active product with its images and specifications.
+func (h *ProductHandler) GetProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid product ID"))
+		return
+	}
+
+	product, err := h.DB.ProductRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetProduct_01: db error:", err)
+		utils.NotFound(w, "product not found")
+		return
+	}
+	if !product.IsActive {
+		utils.NotFound(w, "product not found")
+		return
+	}
+	setProductLinks(product)
+
+	utils.WriteJSON(w, http.StatusOK, product)
+}
+
+// GetProductDatasheet serves the current spec sheet of an active product, shown in the browser
+// unless the query parameter download is set.
+func (h *ProductHandler) GetProductDatasheet(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid product ID"))
+		return
+	}
+
+	product, err := h.DB.ProductRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetProductDatasheet_01: db error:", err)
+		utils.NotFound(w, "product not found")
+		return
+	}
+	if !product.IsActive || product.Datasheet == nil {
+		utils.NotFound(w, "datasheet not found")
+		return
+	}
+
+	if err := serveDatasheet(w, r, product, product.Datasheet, false); err != nil {
+		h.errorLog.Println("ERROR_GetProductDatasheet_02: open file:", err)
+		utils.NotFound(w, "datasheet file not found")
+	}
+}
+
+// CreateProduct adds a product from a multipart form (see readProductForm) with its images and spec sheet.
+func (h *ProductHandler) CreateProduct(w http.ResponseWriter, r *http.Request) {
+	product := &models.Product{IsActive: true, Specifications: []models.ProductSpec{}}
+	files, err := readProductForm(w, r, product)
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+	if err != nil {
+		h.errorLog.Println("ERROR_CreateProduct_01: invalid form:", err)
+		utils.BadRequest(w, err)
+		return
+	}
+
+	if err := h.DB.ProductRepo.Create(r.Context(), product); err != nil {
+		if errors.Is(err, dbrepo.ErrProductCodeTaken) || errors.Is(err, dbrepo.ErrProductLinkMissing) {
+			utils.BadRequest(w, err)
+			return
+		}
+		h.errorLog.Println("ERROR_CreateProduct_02: db create:", err)
+		utils.ServerError(w, errors.New("failed to save product"))
+		return
+	}
+	// The product exists from here on, whether or not its files are saved
+	defer h.publishProduct(r.Context(), models.WebhookEventProductCreated, product.ID)
+
+	if err := h.saveProductFiles(r.Context(), product, files, requestUserID(r)); err != nil {
+		h.errorLog.Println("ERROR_CreateProduct_03: save files:", err)
+		utils.ServerError(w, errors.New("product saved, but its files could not be stored"))
+		return
+	}
+
+	h.respondProduct(w, r, http.StatusCreated, product.ID, "Product created successfully")
+}
+
+// UpdateProduct changes the fields sent in the form, appends the uploaded images and replaces the spec sheet.
+func (h *ProductHandler) UpdateProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid product ID"))
+		return
+	}
+
+	product, err := h.DB.ProductRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_UpdateProduct_01: fetch error:", err)
+		utils.NotFound(w, "product not found")
+		return
+	}
+
+	files, err := readProductForm(w, r, product)
+	if r.MultipartForm != nil {
+		defer r.MultipartForm.RemoveAll()
+	}
+	if err != nil {
+		h.errorLog.Println("ERROR_UpdateProduct_02: invalid form:", err)
+		utils.BadRequest(w, err)
+		return
+	}
+
+	if err := h.DB.ProductRepo.Update(r.Context(), product); err != nil {
+		if errors.Is(err, dbrepo.ErrProductCodeTaken) || errors.Is(err, dbrepo.ErrProductLinkMissing) {
+			utils.BadRequest(w, err)
+			return
+		}
+		h.errorLog.Println("ERROR_UpdateProduct_03: db update:", err)
+		utils.ServerError(w, errors.New("failed to update product"))
+		return
+	}
+	defer h.publishProduct(r.Context(), models.WebhookEventProductUpdated, product.ID)
+
+	if err := h.saveProductFiles(r.Context(), product, files, requestUserID(r)); err != nil {
+		h.errorLog.Println("ERROR_UpdateProduct_04: save files:", err)
+		utils.ServerError(w, errors.New("product updated, but its files could not be stored"))
+		return
+	}
+
+	h.respondProduct(w, r, http.StatusOK, product.ID, "Product updated successfully")
+}
+
+// DeleteProduct removes the product with its images and every version of its spec sheet.
+func (h *ProductHandler) DeleteProduct(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid product ID"))
+		return
+	}
+
+	product, err := h.DB.ProductRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_DeleteProduct_01: fetch error:", err)
+		utils.NotFound(w, "product not found")
+		return
+	}
+
+	sheets, err := h.DB.ProductDatasheetRepo.GetByProduct(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_DeleteProduct_02: fetch datasheets:", err)
+		utils.ServerError(w, errors.New("failed to delete product"))
+		return
+	}
+
+	if err := h.DB.ProductRepo.Delete(r.Context(), id); err != nil {
+		h.errorLog.Println("ERROR_DeleteProduct_03: db error:", err)
+		utils.ServerError(w, errors.New("failed to delete product"))
+		return
+	}
+
+	// Silently delete the files
+	for _, img := range product.Images {
+		os.Remove(filepath.Join(productImageDir, img.ImageLink))
+	}
+	for _, d := range sheets {
+		os.Remove(filepath.Join(productDatasheetDir, d.StoredName))
+	}
+
+	setProductLinks(product)
+	h.Webhooks.Publish(r.Context(), models.WebhookEventProductDeleted, product)
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error   bool   `json:"error"`
+		Message string `json:"message"`
+	}{
+		Error:   false,
+		Message: "Product deleted successfully",
+	})
+}
+
+// DeleteProductImage removes one image {id} of a product.
+func (h *ProductHandler) DeleteProductImage(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid image ID"))
+		return
+	}
+
+	img, err := h.DB.ProductRepo.GetImage(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_DeleteProductImage_01: fetch error:", err)
+		utils.NotFound(w, "image not found")
+		return
+	}
+
+	if err := h.DB.ProductRepo.DeleteImage(r.Context(), id); err != nil {
+		h.errorLog.Println("ERROR_DeleteProductImage_02: db error:", err)
+		utils.ServerError(w, errors.New("failed to delete image"))
+		return
+	}
+	os.Remove(filepath.Join(productImageDir, img.ImageLink))
+
+	h.publishProduct(r.Context(), models.WebhookEventProductUpdated, img.ProductID)
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error   bool   `json:"error"`
+		Message string `json:"message"`
+	}{
+		Error:   false,
+		Message: "Image deleted successfully",
+	})
+}
+
+// publishProduct sends a webhook event with the product as stored, including the files saved last.
+func (h *ProductHandler) publishProduct(ctx context.Context, event string, id int64) {
+	product, err := h.DB.ProductRepo.GetByID(ctx, id)
+	if err != nil {
+		h.errorLog.Println("ERROR_publishProduct_01: fetch error:", err)
+		return
+	}
+	setProductLinks(product)
+	h.Webhooks.Publish(ctx, event, product)
+}
+
+// respondProduct writes the product as stored after a create or update.
+func (h *ProductHandler) respondProduct(w http.ResponseWriter, r *http.Request, status int, id int64, msg string) {
+	product, err := h.DB.ProductRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_respondProduct_01: fetch error:", err)
+		utils.ServerError(w, errors.New("failed to load product"))
+		return
+	}
+	setProductLinks(product)
+
+	utils.WriteJSON(w, status, struct {
+		Error   bool            `json:"error"`
+		Message string          `json:"message"`
+		Data    *models.Product `json:"data"`
+	}{
+		Error:   false,
+		Message: msg,
+		Data:    product,
+	})
+}
@@ -0,0 +1,751 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"mime"
+	"net/http"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
+	"github.com/projuktisheba/ajfses/backend/internal/mailer"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/pdf"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+	"github.com/projuktisheba/ajfses/backend/internal/webhook"
+)
+
+// quotationDir is where the rendered quotation PDFs are stored, named after the quotation number.
+var quotationDir = filepath.Join("data", "quotations")
+
+// Limits of a quotation
+const (
+	maxQuotationItems     = 100
+	maxQuotationQuantity  = 1000000
+	maxQuotationUnitPrice = models.Money(100000000 * 100) // 100 million per unit keeps every total within int64
+)
+
+type QuotationHandler struct {
+	DB       *dbrepo.DBRepository
+	Config   models.QuotationConfig
+	Company  models.CompanyConfig
+	ReplyTo  string // Reply-To of quotation emails, the same as for inquiry replies
+	Mailer   *mailer.Mailer
+	Webhooks *webhook.Dispatcher
+	infoLog  *log.Logger
+	errorLog *log.Logger
+}
+
+func newQuotationHandler(db *dbrepo.DBRepository, cfg models.Config, mail *mailer.Mailer, hooks *webhook.Dispatcher, infoLog, errorLog *log.Logger) QuotationHandler {
+	return QuotationHandler{
+		DB:       db,
+		Config:   cfg.Quotation,
+		Company:  cfg.Company,
+		ReplyTo:  cfg.Inquiry.ReplyTo,
+		Mailer:   mail,
+		Webhooks: hooks,
+		infoLog:  infoLog,
+		errorLog: errorLog,
+	}
+}
+
+// quotationRequest is the quotation form of the admin panel, used to create and to update a draft.
+// Empty customer fields are taken from the linked RFQ or inquiry; rates, validity, currency and terms
+// default to the configured ones.
+type quotationRequest struct {
+	RfqID           *int64       `json:"rfq_id"`
+	InquiryID       *int64       `json:"inquiry_id"`
+	CustomerName    string       `json:"customer_name"`
+	CustomerCompany string       `json:"customer_company"`
+	CustomerEmail   string       `json:"customer_email"`
+	CustomerMobile  string       `json:"customer_mobile"`
+	CustomerAddress string       `json:"customer_address"`
+	Subject         string       `json:"subject"`
+	Reference       string       `json:"reference"`
+	IssueDate       string       `json:"issue_date"`  // YYYY-MM-DD, today when empty
+	ValidUntil      string       `json:"valid_until"` // YYYY-MM-DD, issue date + QUOTATION_VALIDITY_DAYS when empty
+	Currency        string       `json:"currency"`
+	DiscountPercent float64      `json:"discount_percent"`
+	Discount        models.Money `json:"discount"` // fixed discount, ignored when discount_percent is set
+	VatRate         *float64     `json:"vat_rate"`
+	Notes           string       `json:"notes"`
+	Terms           *string      `json:"terms"`
+	Items           []struct {
+		ProductID   *int64       `json:"product_id"`
+		ProductCode string       `json:"product_code"`
+		Description string       `json:"description"`
+		Quantity    int          `json:"quantity"`
+		Unit        string       `json:"unit"`
+		UnitPrice   models.Money `json:"unit_price"`
+	} `json:"items"`
+}
+
+// buildQuotation turns the request into a quotation with calculated totals. Errors are meant for the user.
+// Without items, the products and quantities of the linked RFQ are copied at a unit price of zero.
+func (h *QuotationHandler) buildQuotation(ctx context.Context, req *quotationRequest) (*models.Quotation, error) {
+	q := &models.Quotation{
+		RfqID:           req.RfqID,
+		InquiryID:       req.InquiryID,
+		CustomerName:    strings.TrimSpace(req.CustomerName),
+		CustomerCompany: strings.TrimSpace(req.CustomerCompany),
+		CustomerEmail:   strings.TrimSpace(req.CustomerEmail),
+		CustomerMobile:  strings.TrimSpace(req.CustomerMobile),
+		CustomerAddress: strings.TrimSpace(req.CustomerAddress),
+		Subject:         strings.TrimSpace(req.Subject),
+		Reference:       strings.TrimSpace(req.Reference),
+		Currency:        strings.ToUpper(strings.TrimSpace(req.Currency)),
+		DiscountPercent: req.DiscountPercent,
+		Discount:        req.Discount,
+		VatRate:         h.Config.VatRate,
+		Notes:           strings.TrimSpace(req.Notes),
+		Terms:           h.Config.Terms,
+	}
+	if req.VatRate != nil {
+		q.VatRate = *req.VatRate
+	}
+	if req.Terms != nil {
+		q.Terms = strings.TrimSpace(*req.Terms)
+	}
+	if q.Currency == "" {
+		q.Currency = h.Config.Currency
+	}
+
+	// Customer details from the RFQ or inquiry being answered
+	var rfq *models.Rfq
+	if q.RfqID != nil {
+		var err error
+		if rfq, err = h.DB.RfqRepo.GetByID(ctx, *q.RfqID); err != nil {
+			return nil, errors.New("the linked request for quotation does not exist")
+		}
+		fillEmpty(&q.CustomerName, rfq.Name)
+		fillEmpty(&q.CustomerCompany, rfq.Company)
+		fillEmpty(&q.CustomerEmail, rfq.Email)
+		fillEmpty(&q.CustomerMobile, rfq.Mobile)
+		fillEmpty(&q.Reference, rfq.ReferenceNo())
+	}
+	if q.InquiryID != nil {
+		inquiry, err := h.DB.InquiryRepo.GetByID(ctx, *q.InquiryID)
+		if err != nil {
+			return nil, errors.New("the linked inquiry does not exist")
+		}
+		fillEmpty(&q.CustomerName, inquiry.Name)
+		fillEmpty(&q.CustomerEmail, inquiry.Email)
+		fillEmpty(&q.CustomerMobile, inquiry.Mobile)
+		fillEmpty(&q.Subject, inquiry.Subject)
+		fillEmpty(&q.Reference, inquiry.ReferenceNo())
+	}
+
+	if q.CustomerName == "" {
+		return nil, errors.New("customer_name is required")
+	}
+	if len(q.CustomerName) > 100 || len(q.CustomerCompany) > 150 || len(q.CustomerMobile) > 20 {
+		return nil, errors.New("customer name is limited to 100, company to 150 and mobile to 20 characters")
+	}
+	if q.CustomerEmail != "" && !utils.ValidEmail(q.CustomerEmail) {
+		return nil, errors.New("please enter a valid customer email address")
+	}
+	if len(q.Subject) > 255 || len(q.Reference) > 50 {
+		return nil, errors.New("subject is limited to 255 and reference to 50 characters")
+	}
+	if len(q.Currency) != 3 || strings.Trim(q.Currency, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") != "" {
+		return nil, errors.New("currency must be a three-letter code such as BDT or USD")
+	}
+
+	// Dates
+	now := time.Now()
+	q.IssueDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if v := strings.TrimSpace(req.IssueDate); v != "" {
+		date, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return nil, errors.New("issue_date must be a date in the format YYYY-MM-DD")
+		}
+		q.IssueDate = date
+	}
+	if v := strings.TrimSpace(req.ValidUntil); v != "" {
+		date, err := time.Parse("2006-01-02", v)
+		if err != nil {
+			return nil, errors.New("valid_until must be a date in the format YYYY-MM-DD")
+		}
+		if date.Before(q.IssueDate) {
+			return nil, errors.New("valid_until cannot be before the issue date")
+		}
+		q.ValidUntil = &date
+	} else if h.Config.ValidityDays > 0 {
+		date := q.IssueDate.AddDate(0, 0, h.Config.ValidityDays)
+		q.ValidUntil = &date
+	}
+
+	// Rates
+	if q.DiscountPercent < 0 || q.DiscountPercent > 100 || q.VatRate < 0 || q.VatRate > 100 {
+		return nil, errors.New("discount_percent and vat_rate must be between 0 and 100")
+	}
+	// The rates are stored as NUMERIC(5,2), so the totals must be computed from the stored values
+	var ok bool
+	if q.DiscountPercent, ok = roundRate(q.DiscountPercent); !ok {
+		return nil, errors.New("discount_percent can have at most two decimals")
+	}
+	if q.VatRate, ok = roundRate(q.VatRate); !ok {
+		return nil, errors.New("vat_rate can have at most two decimals")
+	}
+	if q.Discount < 0 {
+		return nil, errors.New("discount cannot be negative")
+	}
+
+	// Items
+	if len(req.Items) == 0 && rfq != nil {
+		for _, it := range rfq.Items {
+			q.Items = append(q.Items, models.QuotationItem{
+				ProductID:   it.ProductID,
+				ProductCode: it.ProductCode,
+				Description: it.ProductName,
+				Quantity:    it.Quantity,
+			})
+		}
+	}
+	for _, it := range req.Items {
+		item := models.QuotationItem{
+			ProductID:   it.ProductID,
+			ProductCode: strings.TrimSpace(it.ProductCode),
+			Description: strings.TrimSpace(it.Description),
+			Quantity:    it.Quantity,
+			Unit:        strings.TrimSpace(it.Unit),
+			UnitPrice:   it.UnitPrice,
+		}
+		if item.ProductID != nil && (item.ProductCode == "" || item.Description == "") {
+			product, err := h.DB.ProductRepo.GetByID(ctx, *item.ProductID)
+			if err != nil {
+				return nil, fmt.Errorf("product %d does not exist", *item.ProductID)
+			}
+			fillEmpty(&item.ProductCode, product.Code)
+			fillEmpty(&item.Description, product.Name)
+		}
+		q.Items = append(q.Items, item)
+	}
+	if len(q.Items) == 0 {
+		return nil, errors.New("add at least one item to the quotation")
+	}
+	if len(q.Items) > maxQuotationItems {
+		return nil, fmt.Errorf("a quotation can hold at most %d items", maxQuotationItems)
+	}
+	for _, item := range q.Items {
+		if item.Description == "" {
+			return nil, errors.New("every item needs a description")
+		}
+		if len(item.ProductCode) > 50 || len(item.Unit) > 20 {
+			return nil, errors.New("product codes are limited to 50 and units to 20 characters")
+		}
+		if item.Quantity < 1 || item.Quantity > maxQuotationQuantity {
+			return nil, fmt.Errorf("quantity must be between 1 and %d", maxQuotationQuantity)
+		}
+		if item.UnitPrice < 0 || item.UnitPrice > maxQuotationUnitPrice {
+			return nil, fmt.Errorf("unit_price must be between 0 and %s", maxQuotationUnitPrice)
+		}
+	}
+
+	// The PDF uses the standard fonts, which cannot print scripts such as Bangla; refuse rather than
+	// send the customer a document full of question marks
+	fields := [][2]string{
+		{"customer_name", q.CustomerName}, {"customer_company", q.CustomerCompany}, {"customer_email", q.CustomerEmail},
+		{"customer_mobile", q.CustomerMobile}, {"customer_address", q.CustomerAddress}, {"subject", q.Subject},
+		{"reference", q.Reference}, {"notes", q.Notes}, {"terms", q.Terms},
+	}
+	for _, item := range q.Items {
+		fields = append(fields, [2]string{"item description", item.Description},
+			[2]string{"product_code", item.ProductCode}, [2]string{"unit", item.Unit})
+	}
+	for _, f := range fields {
+		if r, bad := pdf.Unsupported(f[1]); bad {
+			return nil, fmt.Errorf("%s contains %q, which the quotation PDF cannot print; please use English (Latin) text", f[0], r)
+		}
+	}
+
+	q.Calculate()
+	if q.Discount > q.Subtotal {
+		return nil, fmt.Errorf("the discount cannot be more than the subtotal of %s", q.Subtotal)
+	}
+
+	return q, nil
+}
+
+// roundRate returns the percentage rounded to two decimals, and false when it had more.
+func roundRate(p float64) (float64, bool) {
+	hundredths := math.Round(p * 100)
+	return hundredths / 100, math.Abs(p*100-hundredths) < 1e-6
+}
+
+// fillEmpty sets *dst to v when it is empty.
+func fillEmpty(dst *string, v string) {
+	if *dst == "" {
+		*dst = strings.TrimSpace(v)
+	}
+}
+
+// quotationWriteError writes the response for a failed create or update.
+func (h *QuotationHandler) quotationWriteError(w http.ResponseWriter, caller string, err error) {
+	switch {
+	case errors.Is(err, dbrepo.ErrQuotationLinkMissing), errors.Is(err, dbrepo.ErrQuotationNotDraft):
+		utils.BadRequest(w, err)
+	default:
+		h.errorLog.Printf("ERROR_%s: db error: %v", caller, err)
+		utils.ServerError(w, errors.New("failed to save quotation"))
+	}
+}
+
+// renderQuotationPDF renders the quotation to data/quotations/<quotation_no>.pdf, replacing the file
+// only once it is complete, and records the file name.
+func (h *QuotationHandler) renderQuotationPDF(ctx context.Context, q *models.Quotation) error {
+	if err := os.MkdirAll(quotationDir, 0755); err != nil {
+		return err
+	}
+
+	var b bytes.Buffer
+	if err := pdf.WriteQuotation(&b, h.Company, q); err != nil {
+		return err
+	}
+	name := attachmentName(q.QuotationNo + ".pdf")
+	tmp := filepath.Join(quotationDir, name+".tmp")
+	if err := os.WriteFile(tmp, b.Bytes(), 0644); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, filepath.Join(quotationDir, name)); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	if q.PdfFile != name {
+		if err := h.DB.QuotationRepo.SetPdfFile(ctx, q.ID, name); err != nil {
+			return err
+		}
+		q.PdfFile = name
+	}
+	return nil
+}
+
+// quotationPDF returns the rendered PDF of the quotation, rendering it again when the file is missing.
+func (h *QuotationHandler) quotationPDF(ctx context.Context, q *models.Quotation) ([]byte, error) {
+	if q.PdfFile != "" {
+		data, err := os.ReadFile(filepath.Join(quotationDir, q.PdfFile))
+		if err == nil {
+			return data, nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+	}
+	if err := h.renderQuotationPDF(ctx, q); err != nil {
+		return nil, err
+	}
+	return os.ReadFile(filepath.Join(quotationDir, q.PdfFile))
+}
+
+// readQuotationID reads the quotation ID from the path parameter {id} or, on update routes, the query parameter id.
+func readQuotationID(r *http.Request) (int64, error) {
+	v := chi.URLParam(r, "id")
+	if v == "" {
+		v = strings.TrimSpace(r.URL.Query().Get("id"))
+	}
+	id, err := strconv.ParseInt(v, 10, 64)
+	if err != nil {
+		return 0, errors.New("invalid quotation ID")
+	}
+	return id, nil
+}
+
+// CreateQuotation stores a new draft quotation with the next quotation number and renders its PDF.
+func (h *QuotationHandler) CreateQuotation(w http.ResponseWriter, r *http.Request) {
+	var req quotationRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_CreateQuotation_01: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	q, err := h.buildQuotation(r.Context(), &req)
+	if err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+	q.CreatedBy = requestUserID(r)
+
+	if err := h.DB.QuotationRepo.Create(r.Context(), q, h.Config.Prefix); err != nil {
+		h.quotationWriteError(w, "CreateQuotation_02", err)
+		return
+	}
+	if err := h.renderQuotationPDF(r.Context(), q); err != nil {
+		// the PDF is rendered again when it is first requested
+		h.errorLog.Println("ERROR_CreateQuotation_03: render pdf:", err)
+	}
+
+	h.Webhooks.Publish(r.Context(), models.WebhookEventQuotationCreated, q)
+
+	utils.WriteJSON(w, http.StatusCreated, struct {
+		Error   bool              `json:"error"`
+		Message string            `json:"message"`
+		Data    *models.Quotation `json:"data"`
+	}{
+		Error:   false,
+		Message: fmt.Sprintf("Quotation %s created successfully", q.QuotationNo),
+		Data:    q,
+	})
+}
+
+// UpdateQuotation replaces the content of the draft quotation in query parameter id and renders its PDF
+// again. Sent quotations cannot be changed; create a new one instead.
+func (h *QuotationHandler) UpdateQuotation(w http.ResponseWriter, r *http.Request) {
+	id, err := readQuotationID(r)
+	if err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+
+	existing, err := h.DB.QuotationRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_UpdateQuotation_01: fetch error:", err)
+		utils.NotFound(w, "quotation not found")
+		return
+	}
+	if existing.Status != models.QuotationStatusDraft {
+		utils.BadRequest(w, dbrepo.ErrQuotationNotDraft)
+		return
+	}
+
+	var req quotationRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_UpdateQuotation_02: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	q, err := h.buildQuotation(r.Context(), &req)
+	if err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+	q.ID = existing.ID
+	q.QuotationNo = existing.QuotationNo
+	q.Status = existing.Status
+	q.PdfFile = existing.PdfFile
+	q.CreatedBy = existing.CreatedBy
+	q.CreatedByName = existing.CreatedByName
+	q.CreatedAt = existing.CreatedAt
+
+	if err := h.DB.QuotationRepo.Update(r.Context(), q); err != nil {
+		h.quotationWriteError(w, "UpdateQuotation_03", err)
+		return
+	}
+	if err := h.renderQuotationPDF(r.Context(), q); err != nil {
+		h.errorLog.Println("ERROR_UpdateQuotation_04: render pdf:", err)
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error   bool              `json:"error"`
+		Message string            `json:"message"`
+		Data    *models.Quotation `json:"data"`
+	}{
+		Error:   false,
+		Message: "Quotation updated successfully",
+		Data:    q,
+	})
+}
+
+// parseQuotationFilter reads the quotation list query parameters: pageIndex (1-based), pageLength, status,
+// rfq_id, inquiry_id and search.
+func parseQuotationFilter(r *http.Request) (models.QuotationFilter, error) {
+	queryParams := r.URL.Query()
+	f := models.QuotationFilter{PageIndex: 1, PageLength: 20}
+
+	if v := queryParams.Get("pageIndex"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil || val < 1 {
+			return f, errors.New("Invalid format for 'pageIndex'. Must be a positive integer.")
+		}
+		f.PageIndex = val
+	}
+	if v := queryParams.Get("pageLength"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil || val < 1 || val > 200 {
+			return f, errors.New("Invalid format for 'pageLength'. Must be between 1 and 200.")
+		}
+		f.PageLength = val
+	}
+	if v := queryParams.Get("rfq_id"); v != "" {
+		val, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return f, errors.New("Invalid format for 'rfq_id'.")
+		}
+		f.RfqID = &val
+	}
+	if v := queryParams.Get("inquiry_id"); v != "" {
+		val, err := strconv.ParseInt(v, 10, 64)
+		if err != nil {
+			return f, errors.New("Invalid format for 'inquiry_id'.")
+		}
+		f.InquiryID = &val
+	}
+
+	f.Status = strings.ToUpper(strings.TrimSpace(queryParams.Get("status")))
+	if f.Status != "" && f.Status != models.QuotationStatusDraft && f.Status != models.QuotationStatusSent {
+		return f, fmt.Errorf("unknown status %q, use %s or %s", f.Status, models.QuotationStatusDraft, models.QuotationStatusSent)
+	}
+	f.Search = strings.TrimSpace(queryParams.Get("search"))
+
+	return f, nil
+}
+
+// GetAllQuotations retrieves one page of quotations matching the filters, without their items.
+func (h *QuotationHandler) GetAllQuotations(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseQuotationFilter(r)
+	if err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+
+	quotations, total, err := h.DB.QuotationRepo.GetAll(r.Context(), filter)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetAllQuotations_01: db error:", err)
+		utils.ServerError(w, errors.New("failed to retrieve quotations"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Quotations []models.Quotation `json:"quotations"`
+		Total      int                `json:"total"`
+		PageIndex  int                `json:"pageIndex"`
+		PageLength int                `json:"pageLength"`
+	}{
+		Quotations: quotations,
+		Total:      total,
+		PageIndex:  filter.PageIndex,
+		PageLength: filter.PageLength,
+	})
+}
+
+// GetQuotation retrieves a single quotation with its items.
+func (h *QuotationHandler) GetQuotation(w http.ResponseWriter, r *http.Request) {
+	id, err := readQuotationID(r)
+	if err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+
+	q, err := h.DB.QuotationRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetQuotation_01: db error:", err)
+		utils.NotFound(w, "quotation not found")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error     bool              `json:"error"`
+		Quotation *models.Quotation `json:"quotation"`
+		PdfURL    string            `json:"pdfUrl"`
+	}{
+		Error:     false,
+		Quotation: q,
+		PdfURL:    fmt.Sprintf("/api/v1/quotation/pdf/%d", q.ID),
+	})
+}
+
+// GetQuotationPDF serves the PDF of quotation {id}, shown in the browser unless the query parameter download is set.
+func (h *QuotationHandler) GetQuotationPDF(w http.ResponseWriter, r *http.Request) {
+	id, err := readQuotationID(r)
+	if err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+
+	q, err := h.DB.QuotationRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetQuotationPDF_01: db error:", err)
+		utils.NotFound(w, "quotation not found")
+		return
+	}
+
+	data, err := h.quotationPDF(r.Context(), q)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetQuotationPDF_02: render pdf:", err)
+		utils.ServerError(w, errors.New("failed to prepare quotation PDF"))
+		return
+	}
+
+	disposition := "inline"
+	if r.URL.Query().Has("download") {
+		disposition = "attachment"
+	}
+
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", mime.FormatMediaType(disposition, map[string]string{"filename": q.PdfFile}))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, "", q.UpdatedAt, bytes.NewReader(data))
+}
+
+// SendQuotation emails the quotation in query parameter id with its PDF attached, to the customer or the
+// address in { to }, with an optional covering { message }. The quotation is then marked SENT and can no
+// longer be edited; a NEW request for quotation it answers moves to QUOTED. It can be sent again.
+func (h *QuotationHandler) SendQuotation(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		To      string `json:"to"`
+		Message string `json:"message"`
+	}
+
+	authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+	if !ok {
+		h.errorLog.Println("ERROR_SendQuotation_01: authentication claims not found in context.")
+		utils.Unauthorized(w, errors.New("authentication context missing. Please log in again."))
+		return
+	}
+
+	id, err := readQuotationID(r)
+	if err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+
+	q, err := h.DB.QuotationRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_SendQuotation_02: fetch error:", err)
+		utils.NotFound(w, "quotation not found")
+		return
+	}
+
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_SendQuotation_03: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	to := strings.TrimSpace(req.To)
+	if to == "" {
+		to = q.CustomerEmail
+	}
+	if _, err := mail.ParseAddress(to); err != nil {
+		utils.BadRequest(w, errors.New("the quotation has no valid email address to send to"))
+		return
+	}
+
+	data, err := h.quotationPDF(r.Context(), q)
+	if err != nil {
+		h.errorLog.Println("ERROR_SendQuotation_04: render pdf:", err)
+		utils.ServerError(w, errors.New("failed to prepare quotation PDF"))
+		return
+	}
+
+	email, err := h.Mailer.Render(r.Context(), "quotation", map[string]any{
+		"Quotation": q,
+		"Message":   strings.TrimSpace(req.Message),
+		"StaffName": authClaims.Name,
+	})
+	if err != nil {
+		h.errorLog.Println("ERROR_SendQuotation_05: failed to render email:", err)
+		utils.ServerError(w, errors.New("failed to prepare quotation email"))
+		return
+	}
+	email.To = []string{to}
+	email.ReplyTo = h.ReplyTo
+	email.Attachments = []mailer.Attachment{{FileName: q.PdfFile, ContentType: "application/pdf", Data: data}}
+
+	if err := h.Mailer.Send(r.Context(), email); err != nil {
+		h.errorLog.Println("ERROR_SendQuotation_06: failed to send email:", err)
+		utils.WriteJSON(w, http.StatusBadGateway, struct {
+			Error   bool   `json:"error"`
+			Message string `json:"message"`
+		}{
+			Error:   true,
+			Message: "The quotation email could not be sent",
+		})
+		return
+	}
+
+	if err := h.DB.QuotationRepo.MarkSent(r.Context(), q, to); err != nil {
+		h.errorLog.Println("ERROR_SendQuotation_07: db error:", err)
+		utils.ServerError(w, errors.New("the quotation was sent but could not be marked as sent"))
+		return
+	}
+	if q.RfqID != nil {
+		h.markRfqQuoted(r.Context(), *q.RfqID, q, authClaims)
+	}
+
+	h.Webhooks.Publish(r.Context(), models.WebhookEventQuotationSent, q)
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error   bool              `json:"error"`
+		Message string            `json:"message"`
+		Data    *models.Quotation `json:"data"`
+	}{
+		Error:   false,
+		Message: fmt.Sprintf("Quotation %s sent to %s", q.QuotationNo, to),
+		Data:    q,
+	})
+}
+
+// markRfqQuoted moves a NEW request for quotation to QUOTED once a quotation for it was sent.
+// Requests in any other status are left alone.
+func (h *QuotationHandler) markRfqQuoted(ctx context.Context, rfqID int64, q *models.Quotation, claims models.JWT) {
+	rfq, err := h.DB.RfqRepo.GetByID(ctx, rfqID)
+	if err != nil {
+		h.errorLog.Println("ERROR_01_markRfqQuoted: fetch error:", err)
+		return
+	}
+	if rfq.Status != models.RfqStatusNew || !models.RfqAllows(rfq.Status, models.RfqStatusQuoted) {
+		return
+	}
+
+	changedBy := claims.ID
+	change := &models.RfqStatusChange{
+		From:      rfq.Status,
+		To:        models.RfqStatusQuoted,
+		ChangedBy: &changedBy,
+		Comment:   fmt.Sprintf("Quotation %s sent to %s", q.QuotationNo, q.SentTo),
+	}
+	if err := h.DB.RfqRepo.UpdateStatus(ctx, rfq, change); err != nil {
+		h.errorLog.Println("ERROR_02_markRfqQuoted: update error:", err)
+		return
+	}
+
+	change.ChangedByName = claims.Name
+	h.Webhooks.Publish(ctx, models.WebhookEventRfqStatusChanged, struct {
+		Rfq    *models.Rfq             `json:"rfq"`
+		Change *models.RfqStatusChange `json:"change"`
+	}{rfq, change})
+}
+
+// DeleteQuotation removes the draft quotation in query parameter id and its PDF. Its number is not reused.
+func (h *QuotationHandler) DeleteQuotation(w http.ResponseWriter, r *http.Request) {
+	id, err := readQuotationID(r)
+	if err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+
+	pdfFile, err := h.DB.QuotationRepo.Delete(r.Context(), id)
+	if err != nil {
+		if errors.Is(err, dbrepo.ErrQuotationNotDraft) {
+			utils.BadRequest(w, err)
+			return
+		}
+		h.errorLog.Println("ERROR_DeleteQuotation_01: db error:", err)
+		utils.NotFound(w, "quotation not found")
+		return
+	}
+	if pdfFile != "" {
+		os.Remove(filepath.Join(quotationDir, pdfFile))
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error   bool   `json:"error"`
+		Message string `json:"message"`
+	}{
+		Error:   false,
+		Message: "Quotation deleted successfully",
+	})
+}
@@ -0,0 +1,428 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/mail"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/spam"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+)
+
+// Limits of a request for quotation
+const (
+	maxRfqItems    = 50
+	maxRfqQuantity = 1000000
+)
+
+// createRfqRequest is the quotation cart sent by the public site.
+type createRfqRequest struct {
+	Name    string `json:"name"`
+	Company string `json:"company"`
+	Email   string `json:"email"`
+	Mobile  string `json:"mobile"`
+	Message string `json:"message"`
+	Items   []struct {
+		ProductID int64  `json:"product_id"`
+		Quantity  int    `json:"quantity"`
+		Note      string `json:"note"`
+	} `json:"items"`
+	Website   string `json:"website"`    // honeypot, hidden from humans
+	FormToken string `json:"form_token"` // from GET /api/v1/inquiry/form-token
+}
+
+// validate trims the request and turns it into an RFQ with its items.
+func (req *createRfqRequest) validate() (*models.Rfq, error) {
+	q := &models.Rfq{
+		Name:    strings.TrimSpace(req.Name),
+		Company: strings.TrimSpace(req.Company),
+		Email:   strings.TrimSpace(req.Email),
+		Mobile:  strings.TrimSpace(req.Mobile),
+		Message: strings.TrimSpace(req.Message),
+		Status:  models.RfqStatusNew,
+	}
+
+	if q.Name == "" || q.Email == "" || q.Mobile == "" {
+		return nil, errors.New("name, email and mobile are required")
+	}
+	if len(q.Name) > 100 || len(q.Company) > 150 {
+		return nil, errors.New("name is limited to 100 and company to 150 characters")
+	}
+	if !utils.ValidEmail(q.Email) {
+		return nil, errors.New("please enter a valid email address")
+	}
+	if !utils.ValidPhone(q.Mobile) {
+		return nil, errors.New("please enter a valid mobile number")
+	}
+
+	if len(req.Items) == 0 {
+		return nil, errors.New("add at least one product to the request")
+	}
+	if len(req.Items) > maxRfqItems {
+		return nil, fmt.Errorf("a request can hold at most %d products", maxRfqItems)
+	}
+	seen := make(map[int64]bool, len(req.Items))
+	for _, it := range req.Items {
+		if it.ProductID < 1 {
+			return nil, errors.New("every item needs a product_id")
+		}
+		if seen[it.ProductID] {
+			return nil, errors.New("each product can appear only once, change its quantity instead")
+		}
+		seen[it.ProductID] = true
+		if it.Quantity < 1 || it.Quantity > maxRfqQuantity {
+			return nil, fmt.Errorf("quantity must be between 1 and %d", maxRfqQuantity)
+		}
+		note := strings.TrimSpace(it.Note)
+		if len(note) > 500 {
+			return nil, errors.New("item notes are limited to 500 characters")
+		}
+		productID := it.ProductID
+		q.Items = append(q.Items, models.RfqItem{ProductID: &productID, Quantity: it.Quantity, Note: note})
+	}
+
+	return q, nil
+}
+
+// CreateRfq handles the submission of a request for quotation from the public product catalogue.
+// It goes through the same spam checks as the contact form; flagged requests are stored as REJECTED spam
+// without any email and the response is the same.
+func (h *InquiryHandler) CreateRfq(w http.ResponseWriter, r *http.Request) {
+	var req createRfqRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_01_CreateRfq: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	rfq, err := req.validate()
+	if err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+
+	// Spam checks
+	submission := &spam.Submission{
+		Name:     rfq.Name,
+		Email:    rfq.Email,
+		Mobile:   rfq.Mobile,
+		Subject:  "Request for quotation",
+		Message:  rfq.Message,
+		IP:       utils.ClientIP(r),
+		Honeypot: req.Website,
+	}
+	submission.TokenAge, submission.TokenErr = h.FormTokens.Verify(strings.TrimSpace(req.FormToken), time.Now())
+	result, err := h.Spam.Score(r.Context(), submission)
+	if err != nil {
+		h.errorLog.Println("ERROR_02_CreateRfq: spam scorer error:", err)
+	}
+
+	rfq.SpamScore = result.Score
+	rfq.SpamReasons = strings.Join(result.Reasons, "; ")
+	rfq.IPAddress = submission.IP
+	if result.Score >= h.Config.SpamThreshold {
+		rfq.IsSpam = true
+		rfq.Status = models.RfqStatusRejected
+	}
+
+	if err := h.DB.RfqRepo.Create(r.Context(), rfq); err != nil {
+		if errors.Is(err, dbrepo.ErrRfqProductUnavailable) {
+			utils.BadRequest(w, err)
+			return
+		}
+		h.errorLog.Println("ERROR_03_CreateRfq: db error:", err)
+		utils.ServerError(w, errors.New("failed to submit request"))
+		return
+	}
+
+	if rfq.IsSpam {
+		h.infoLog.Printf("RFQ %d from %s stored as spam (score %d): %s", rfq.ID, rfq.IPAddress, rfq.SpamScore, rfq.SpamReasons)
+	} else {
+		h.notifyStaffOfRfq(r.Context(), rfq)
+		h.acknowledgeRfq(r.Context(), rfq)
+		h.Webhooks.Publish(r.Context(), models.WebhookEventRfqCreated, rfq)
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, struct {
+		Error       bool   `json:"error"`
+		Message     string `json:"message"`
+		ID          int64  `json:"id"`
+		ReferenceNo string `json:"referenceNo"`
+	}{
+		Error:       false,
+		Message:     "Request for quotation submitted successfully",
+		ID:          rfq.ID,
+		ReferenceNo: rfq.ReferenceNo(),
+	})
+}
+
+// notifyStaffOfRfq emails the new request to the inquiry recipients in the background.
+// Replies go straight to the customer.
+func (h *InquiryHandler) notifyStaffOfRfq(ctx context.Context, rfq *models.Rfq) {
+	if len(h.Config.NotifyRecipients) == 0 {
+		return
+	}
+
+	link := ""
+	if h.Config.AdminURL != "" {
+		link = fmt.Sprintf("%s?rfq=%d", h.Config.AdminURL, rfq.ID)
+	}
+
+	msg, err := h.Mailer.Render(ctx, "rfq_staff_notification", map[string]any{
+		"Rfq":         rfq,
+		"ReferenceNo": rfq.ReferenceNo(),
+		"Link":        link,
+	})
+	if err != nil {
+		h.errorLog.Println("ERROR_01_notifyStaffOfRfq: failed to render email:", err)
+		return
+	}
+	msg.To = h.Config.NotifyRecipients
+	if _, err := mail.ParseAddress(rfq.Email); err == nil {
+		msg.ReplyTo = rfq.Email
+	}
+
+	h.Mailer.SendAsync(msg)
+}
+
+// acknowledgeRfq emails the customer a confirmation with the reference number and the requested products.
+func (h *InquiryHandler) acknowledgeRfq(ctx context.Context, rfq *models.Rfq) {
+	if _, err := mail.ParseAddress(rfq.Email); err != nil {
+		h.errorLog.Println("ERROR_01_acknowledgeRfq: invalid email, no acknowledgement sent:", rfq.Email)
+		return
+	}
+
+	err := h.Mailer.SendTemplateAsync(ctx, "rfq_acknowledgement", []string{rfq.Email}, map[string]any{
+		"Rfq":         rfq,
+		"ReferenceNo": rfq.ReferenceNo(),
+	})
+	if err != nil {
+		h.errorLog.Println("ERROR_02_acknowledgeRfq: failed to render email:", err)
+	}
+}
+
+// parseRfqFilter reads the RFQ list query parameters: pageIndex (1-based), pageLength, status, search
+// and spam (true lists only the requests flagged as spam).
+func parseRfqFilter(r *http.Request) (models.RfqFilter, error) {
+	queryParams := r.URL.Query()
+	f := models.RfqFilter{PageIndex: 1, PageLength: 20}
+
+	if v := queryParams.Get("pageIndex"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil || val < 1 {
+			return f, errors.New("Invalid format for 'pageIndex'. Must be a positive integer.")
+		}
+		f.PageIndex = val
+	}
+	if v := queryParams.Get("pageLength"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil || val < 1 || val > 200 {
+			return f, errors.New("Invalid format for 'pageLength'. Must be between 1 and 200.")
+		}
+		f.PageLength = val
+	}
+	if v := queryParams.Get("spam"); v != "" {
+		val, err := strconv.ParseBool(v)
+		if err != nil {
+			return f, errors.New("Invalid format for 'spam'. Use true or false.")
+		}
+		f.Spam = val
+	}
+
+	f.Status = strings.ToUpper(strings.TrimSpace(queryParams.Get("status")))
+	if f.Status != "" && models.RfqNextStatuses(f.Status) == nil {
+		return f, fmt.Errorf("unknown status %q", f.Status)
+	}
+	f.Search = strings.TrimSpace(queryParams.Get("search"))
+
+	return f, nil
+}
+
+// GetAllRfqs retrieves one page of requests for quotation matching the filters, the total number of
+// matches and the status counts over all requests that are not spam.
+func (h *InquiryHandler) GetAllRfqs(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseRfqFilter(r)
+	if err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+
+	rfqs, total, err := h.DB.RfqRepo.GetAll(r.Context(), filter)
+	if err != nil {
+		h.errorLog.Println("ERROR_01_GetAllRfqs: db error (list):", err)
+		utils.ServerError(w, errors.New("failed to retrieve requests for quotation"))
+		return
+	}
+
+	counts, err := h.DB.RfqRepo.GetStatusCounts(r.Context())
+	if err != nil {
+		h.errorLog.Println("ERROR_02_GetAllRfqs: db error (counts):", err)
+		utils.ServerError(w, errors.New("failed to retrieve request stats"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Rfqs       []models.Rfq   `json:"rfqs"`
+		Counts     map[string]int `json:"counts"`
+		Total      int            `json:"total"`
+		PageIndex  int            `json:"pageIndex"`
+		PageLength int            `json:"pageLength"`
+	}{
+		Rfqs:       rfqs,
+		Counts:     counts,
+		Total:      total,
+		PageIndex:  filter.PageIndex,
+		PageLength: filter.PageLength,
+	})
+}
+
+// GetRfq retrieves a single request with its items, status history and the statuses it may move to.
+func (h *InquiryHandler) GetRfq(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid request ID"))
+		return
+	}
+
+	rfq, err := h.DB.RfqRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_01_GetRfq: db error:", err)
+		utils.NotFound(w, "request for quotation not found")
+		return
+	}
+
+	history, err := h.DB.RfqRepo.GetStatusHistory(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_02_GetRfq: db error (status history):", err)
+		utils.ServerError(w, errors.New("failed to retrieve request status history"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error         bool                     `json:"error"`
+		Rfq           *models.Rfq              `json:"rfq"`
+		ReferenceNo   string                   `json:"referenceNo"`
+		NextStatuses  []string                 `json:"nextStatuses"`
+		StatusHistory []models.RfqStatusChange `json:"statusHistory"`
+	}{
+		Error:         false,
+		Rfq:           rfq,
+		ReferenceNo:   rfq.ReferenceNo(),
+		NextStatuses:  models.RfqNextStatuses(rfq.Status),
+		StatusHistory: history,
+	})
+}
+
+// UpdateRfqStatus moves a request to another status of the RFQ workflow and records the change.
+func (h *InquiryHandler) UpdateRfqStatus(w http.ResponseWriter, r *http.Request) {
+	var req struct {
+		Status  string `json:"status"`
+		Comment string `json:"comment"`
+	}
+
+	authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+	if !ok {
+		h.errorLog.Println("ERROR_01_UpdateRfqStatus: authentication claims not found in context.")
+		utils.Unauthorized(w, errors.New("authentication context missing. Please log in again."))
+		return
+	}
+
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid request ID"))
+		return
+	}
+
+	rfq, err := h.DB.RfqRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_02_UpdateRfqStatus: fetch error:", err)
+		utils.NotFound(w, "request for quotation not found")
+		return
+	}
+
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_03_UpdateRfqStatus: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	status := strings.ToUpper(strings.TrimSpace(req.Status))
+	if models.RfqNextStatuses(status) == nil {
+		utils.BadRequest(w, fmt.Errorf("unknown status %q, use one of %s", status, strings.Join(models.RfqStatuses, ", ")))
+		return
+	}
+	if !models.RfqAllows(rfq.Status, status) {
+		next := models.RfqNextStatuses(rfq.Status)
+		if len(next) == 0 {
+			utils.BadRequest(w, fmt.Errorf("a request in status %s cannot change status", rfq.Status))
+			return
+		}
+		utils.BadRequest(w, fmt.Errorf("cannot change status from %s to %s, allowed: %s",
+			rfq.Status, status, strings.Join(next, ", ")))
+		return
+	}
+
+	changedBy := authClaims.ID
+	change := &models.RfqStatusChange{
+		From:      rfq.Status,
+		To:        status,
+		ChangedBy: &changedBy,
+		Comment:   strings.TrimSpace(req.Comment),
+	}
+	if err := h.DB.RfqRepo.UpdateStatus(r.Context(), rfq, change); err != nil {
+		if errors.Is(err, dbrepo.ErrRfqStatusConflict) {
+			utils.BadRequest(w, err)
+			return
+		}
+		h.errorLog.Println("ERROR_04_UpdateRfqStatus: update error:", err)
+		utils.ServerError(w, errors.New("failed to update request"))
+		return
+	}
+
+	change.ChangedByName = authClaims.Name
+	h.Webhooks.Publish(r.Context(), models.WebhookEventRfqStatusChanged, struct {
+		Rfq    *models.Rfq             `json:"rfq"`
+		Change *models.RfqStatusChange `json:"change"`
+	}{rfq, change})
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error   bool        `json:"error"`
+		Message string      `json:"message"`
+		Data    *models.Rfq `json:"data"`
+	}{
+		Error:   false,
+		Message: "Request updated successfully",
+		Data:    rfq,
+	})
+}
+
+// DeleteRfq removes a request with its items and history.
+func (h *InquiryHandler) DeleteRfq(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid request ID"))
+		return
+	}
+
+	if err := h.DB.RfqRepo.Delete(r.Context(), id); err != nil {
+		h.errorLog.Println("ERROR_01_DeleteRfq: db error:", err)
+		utils.ServerError(w, errors.New("failed to delete request"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error   bool   `json:"error"`
+		Message string `json:"message"`
+	}{
+		Error:   false,
+		Message: "Request deleted successfully",
+	})
+}
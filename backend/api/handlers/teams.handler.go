@@ -12,17 +12,20 @@ import (
 	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
 	"github.com/projuktisheba/ajfses/backend/internal/models"
 	"github.com/projuktisheba/ajfses/backend/internal/utils"
+	"github.com/projuktisheba/ajfses/backend/internal/webhook"
 )
 
 type TeamHandler struct {
 	DB       *dbrepo.DBRepository
+	Webhooks *webhook.Dispatcher
 	infoLog  *log.Logger
 	errorLog *log.Logger
 }
 
-func newTeamHandler(db *dbrepo.DBRepository, infoLog, errorLog *log.Logger) TeamHandler {
+func newTeamHandler(db *dbrepo.DBRepository, hooks *webhook.Dispatcher, infoLog, errorLog *log.Logger) TeamHandler {
 	return TeamHandler{
 		DB:       db,
+		Webhooks: hooks,
 		infoLog:  infoLog,
 		errorLog: errorLog,
 	}
@@ -50,6 +53,12 @@ func (h *TeamHandler) CreateTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if team, err := h.DB.TeamRepo.GetByID(r.Context(), id); err != nil {
+		h.errorLog.Println("ERROR_CreateTeam_03: fetch error:", err)
+	} else {
+		h.Webhooks.Publish(r.Context(), models.WebhookEventTeamCreated, team)
+	}
+
 	utils.WriteJSON(w, http.StatusCreated, struct {
 		Error   bool   `json:"error"`
 		Message string `json:"message"`
@@ -153,6 +162,8 @@ func (h *TeamHandler) UpdateTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.Webhooks.Publish(r.Context(), models.WebhookEventTeamUpdated, existing)
+
 	utils.WriteJSON(w, http.StatusOK, struct {
 		Error   bool         `json:"error"`
 		Message string       `json:"message"`
@@ -173,13 +184,23 @@ func (h *TeamHandler) DeleteTeam(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Fetched first so the webhook event carries the deleted team
+	team, err := h.DB.TeamRepo.GetByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_DeleteTeam_01: fetch error:", err)
+		utils.BadRequest(w, errors.New("team not found"))
+		return
+	}
+
 	err = h.DB.TeamRepo.Delete(r.Context(), id)
 	if err != nil {
-		h.errorLog.Println("ERROR_DeleteTeam_01: db error:", err)
+		h.errorLog.Println("ERROR_DeleteTeam_02: db error:", err)
 		utils.ServerError(w, errors.New("failed to delete team"))
 		return
 	}
 
+	h.Webhooks.Publish(r.Context(), models.WebhookEventTeamDeleted, team)
+
 	utils.WriteJSON(w, http.StatusOK, struct {
 		Error   bool   `json:"error"`
 		Message string `json:"message"`
@@ -0,0 +1,381 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+)
+
+const (
+	// challengeTokenTTL is how long a user has to enter the TOTP code after the password step
+	challengeTokenTTL = 5 * time.Minute
+	// recoveryCodeCount is the number of one-time recovery codes issued when 2FA is enabled
+	recoveryCodeCount = 10
+	// totpIssuer is shown as the account name prefix in authenticator apps
+	totpIssuer = "AJFSES Admin"
+)
+
+// writeTwoFactorChallenge answers a correct password for a 2FA-enabled account with a challenge token
+// instead of the access token. The token is exchanged by VerifyTwoFactor.
+func (h *AuthHandler) writeTwoFactorChallenge(w http.ResponseWriter, user *models.User) {
+	challenge, err := utils.GenerateChallengeJWT(user.ID, h.JWTConfig, challengeTokenTTL)
+	if err != nil {
+		h.errorLog.Println("ERROR_01_writeTwoFactorChallenge: failed to generate challenge:", err)
+		utils.ServerError(w, errors.New("failed to generate token"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error             bool   `json:"error"`
+		TwoFactorRequired bool   `json:"twoFactorRequired"`
+		ChallengeToken    string `json:"challengeToken"`
+		ExpiresIn         int64  `json:"expiresIn"`
+	}{
+		Error:             false,
+		TwoFactorRequired: true,
+		ChallengeToken:    challenge,
+		ExpiresIn:         int64(challengeTokenTTL.Seconds()),
+	})
+}
+
+// VerifyTwoFactor completes a two-factor sign-in: it takes the challenge token returned by Signin
+// and either a TOTP code or a one-time recovery code, and issues the access and refresh tokens.
+func (h *AuthHandler) VerifyTwoFactor(w http.ResponseWriter, r *http.Request) {
+	type verifyRequest struct {
+		ChallengeToken string `json:"challengeToken"`
+		Code           string `json:"code"`
+		RecoveryCode   string `json:"recoveryCode"`
+	}
+
+	var req verifyRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_01_VerifyTwoFactor: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	req.Code = strings.TrimSpace(req.Code)
+	req.RecoveryCode = utils.NormalizeRecoveryCode(req.RecoveryCode)
+	if req.Code == "" && req.RecoveryCode == "" {
+		utils.BadRequest(w, errors.New("code or recovery code is required"))
+		return
+	}
+
+	if h.ipThrottled(w, r) {
+		return
+	}
+
+	// 1. Validate the challenge token
+	userID, err := utils.VerifyChallengeJWT(strings.TrimSpace(req.ChallengeToken), h.JWTConfig)
+	if err != nil {
+		h.errorLog.Println("ERROR_02_VerifyTwoFactor: invalid challenge:", err)
+		utils.Unauthorized(w, errors.New("sign-in session expired. Please sign in again"))
+		return
+	}
+
+	user, err := h.DB.UserRepo.GetUserByID(r.Context(), userID)
+	if err != nil {
+		h.errorLog.Println("ERROR_03_VerifyTwoFactor: user not found:", err)
+		utils.Unauthorized(w, errors.New("sign-in session expired. Please sign in again"))
+		return
+	}
+	if user.Status == "Inactive" || !user.TOTPEnabled || len(models.PermissionsFor(user.Role)) == 0 {
+		h.errorLog.Println("ERROR_04_VerifyTwoFactor: user can no longer sign in:", user.ID)
+		utils.Unauthorized(w, errors.New("sign-in session expired. Please sign in again"))
+		return
+	}
+
+	if until, locked := accountLockedUntil(user); locked {
+		utils.Locked(w, lockedError(until), until)
+		return
+	}
+
+	// 2. Check the second factor; failures count towards the account lock
+	if !h.checkSecondFactor(r, user, req.Code, req.RecoveryCode) {
+		h.errorLog.Println("ERROR_05_VerifyTwoFactor: invalid code for user:", user.ID)
+		if until := h.registerFailedLogin(r, user.Email, user); until != nil {
+			utils.Locked(w, lockedError(*until), *until)
+			return
+		}
+		utils.BadRequest(w, errors.New("invalid verification code"))
+		return
+	}
+
+	// 3. Issue tokens
+	h.completeSignin(r, user.Email, user)
+	h.issueTokens(w, r, user, "")
+}
+
+// checkSecondFactor validates either a TOTP code (rejecting replays) or an unused recovery code.
+func (h *AuthHandler) checkSecondFactor(r *http.Request, user *models.User, code, recoveryCode string) bool {
+	if code != "" {
+		step, ok := utils.ValidateTOTP(user.TOTPSecret, code, time.Now())
+		if !ok {
+			return false
+		}
+		fresh, err := h.DB.UserRepo.ConsumeTOTPStep(r.Context(), user.ID, step)
+		if err != nil {
+			h.errorLog.Println("ERROR_01_checkSecondFactor: db error:", err)
+			return false
+		}
+		return fresh
+	}
+
+	used, err := h.DB.UserRepo.UseRecoveryCode(r.Context(), user.ID, utils.HashToken(recoveryCode))
+	if err != nil {
+		h.errorLog.Println("ERROR_02_checkSecondFactor: db error:", err)
+		return false
+	}
+	if used {
+		h.infoLog.Printf("User ID %d signed in with a recovery code.", user.ID)
+	}
+	return used
+}
+
+// EnrollTwoFactor generates a new TOTP secret for the authenticated user and returns the provisioning URI.
+// Two-factor is not active until the user confirms a code with EnableTwoFactor.
+func (h *AuthHandler) EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+	if !ok {
+		h.errorLog.Println("ERROR_01_EnrollTwoFactor: authentication claims not found in context.")
+		utils.Unauthorized(w, errors.New("authentication context missing. Please log in again."))
+		return
+	}
+
+	user, err := h.DB.UserRepo.GetUserByID(r.Context(), authClaims.ID)
+	if err != nil {
+		h.errorLog.Println("ERROR_02_EnrollTwoFactor: user not found:", err)
+		utils.NotFound(w, "user not found")
+		return
+	}
+	if user.TOTPEnabled {
+		utils.BadRequest(w, errors.New("two-factor authentication is already enabled"))
+		return
+	}
+
+	secret, err := utils.GenerateTOTPSecret()
+	if err != nil {
+		h.errorLog.Println("ERROR_03_EnrollTwoFactor: failed to generate secret:", err)
+		utils.ServerError(w, errors.New("failed to generate secret"))
+		return
+	}
+
+	if err := h.DB.UserRepo.SetTOTPSecret(r.Context(), user.ID, secret); err != nil {
+		h.errorLog.Println("ERROR_04_EnrollTwoFactor: db error:", err)
+		utils.ServerError(w, errors.New("failed to save secret"))
+		return
+	}
+
+	account := user.Email
+	if account == "" {
+		account = user.Mobile
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error           bool   `json:"error"`
+		Message         string `json:"message"`
+		Secret          string `json:"secret"`
+		ProvisioningURI string `json:"provisioningUri"`
+	}{
+		Error:           false,
+		Message:         "Scan the code with your authenticator app, then confirm a code to enable two-factor authentication",
+		Secret:          secret,
+		ProvisioningURI: utils.TOTPProvisioningURI(totpIssuer, account, secret),
+	})
+}
+
+// EnableTwoFactor confirms enrollment with a code from the authenticator app,
+// switches two-factor on and returns one-time recovery codes. They are shown only once.
+func (h *AuthHandler) EnableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	type enableRequest struct {
+		Code string `json:"code"`
+	}
+
+	authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+	if !ok {
+		h.errorLog.Println("ERROR_01_EnableTwoFactor: authentication claims not found in context.")
+		utils.Unauthorized(w, errors.New("authentication context missing. Please log in again."))
+		return
+	}
+
+	var req enableRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_02_EnableTwoFactor: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	user, err := h.DB.UserRepo.GetUserByID(r.Context(), authClaims.ID)
+	if err != nil {
+		h.errorLog.Println("ERROR_03_EnableTwoFactor: user not found:", err)
+		utils.NotFound(w, "user not found")
+		return
+	}
+	if user.TOTPEnabled {
+		utils.BadRequest(w, errors.New("two-factor authentication is already enabled"))
+		return
+	}
+	if user.TOTPSecret == "" {
+		utils.BadRequest(w, errors.New("start the enrollment first"))
+		return
+	}
+
+	step, ok := utils.ValidateTOTP(user.TOTPSecret, req.Code, time.Now())
+	if !ok {
+		utils.BadRequest(w, errors.New("invalid verification code"))
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		h.errorLog.Println("ERROR_04_EnableTwoFactor: failed to generate recovery codes:", err)
+		utils.ServerError(w, errors.New("failed to generate recovery codes"))
+		return
+	}
+
+	if err := h.DB.UserRepo.EnableTOTP(r.Context(), user.ID, step, hashes); err != nil {
+		h.errorLog.Println("ERROR_05_EnableTwoFactor: db error:", err)
+		utils.ServerError(w, errors.New("failed to enable two-factor authentication"))
+		return
+	}
+
+	h.infoLog.Printf("User ID %d enabled two-factor authentication.", user.ID)
+	writeRecoveryCodes(w, "Two-factor authentication enabled. Store the recovery codes in a safe place", codes)
+}
+
+// DisableTwoFactor switches two-factor off for the authenticated user.
+// It requires the password and a current code (or a recovery code).
+func (h *AuthHandler) DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	type disableRequest struct {
+		Password     string `json:"password"`
+		Code         string `json:"code"`
+		RecoveryCode string `json:"recoveryCode"`
+	}
+
+	authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+	if !ok {
+		h.errorLog.Println("ERROR_01_DisableTwoFactor: authentication claims not found in context.")
+		utils.Unauthorized(w, errors.New("authentication context missing. Please log in again."))
+		return
+	}
+
+	var req disableRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_02_DisableTwoFactor: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	user, err := h.DB.UserRepo.GetUserByID(r.Context(), authClaims.ID)
+	if err != nil {
+		h.errorLog.Println("ERROR_03_DisableTwoFactor: user not found:", err)
+		utils.NotFound(w, "user not found")
+		return
+	}
+	if !user.TOTPEnabled {
+		utils.BadRequest(w, errors.New("two-factor authentication is not enabled"))
+		return
+	}
+
+	if !utils.CheckPassword(strings.TrimSpace(req.Password), user.Password) {
+		utils.BadRequest(w, errors.New("invalid password"))
+		return
+	}
+	if !h.checkSecondFactor(r, user, strings.TrimSpace(req.Code), utils.NormalizeRecoveryCode(req.RecoveryCode)) {
+		utils.BadRequest(w, errors.New("invalid verification code"))
+		return
+	}
+
+	if err := h.DB.UserRepo.DisableTOTP(r.Context(), user.ID); err != nil {
+		h.errorLog.Println("ERROR_04_DisableTwoFactor: db error:", err)
+		utils.ServerError(w, errors.New("failed to disable two-factor authentication"))
+		return
+	}
+
+	h.infoLog.Printf("User ID %d disabled two-factor authentication.", user.ID)
+	utils.WriteJSON(w, http.StatusOK, models.Response{
+		Error:   false,
+		Message: "Two-factor authentication disabled.",
+	})
+}
+
+// RegenerateRecoveryCodes replaces the authenticated user's recovery codes. It requires a current TOTP code.
+func (h *AuthHandler) RegenerateRecoveryCodes(w http.ResponseWriter, r *http.Request) {
+	type regenerateRequest struct {
+		Code string `json:"code"`
+	}
+
+	authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+	if !ok {
+		h.errorLog.Println("ERROR_01_RegenerateRecoveryCodes: authentication claims not found in context.")
+		utils.Unauthorized(w, errors.New("authentication context missing. Please log in again."))
+		return
+	}
+
+	var req regenerateRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_02_RegenerateRecoveryCodes: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	user, err := h.DB.UserRepo.GetUserByID(r.Context(), authClaims.ID)
+	if err != nil {
+		h.errorLog.Println("ERROR_03_RegenerateRecoveryCodes: user not found:", err)
+		utils.NotFound(w, "user not found")
+		return
+	}
+	if !user.TOTPEnabled {
+		utils.BadRequest(w, errors.New("two-factor authentication is not enabled"))
+		return
+	}
+	if !h.checkSecondFactor(r, user, strings.TrimSpace(req.Code), "") {
+		utils.BadRequest(w, errors.New("invalid verification code"))
+		return
+	}
+
+	codes, hashes, err := newRecoveryCodes()
+	if err != nil {
+		h.errorLog.Println("ERROR_04_RegenerateRecoveryCodes: failed to generate recovery codes:", err)
+		utils.ServerError(w, errors.New("failed to generate recovery codes"))
+		return
+	}
+
+	if err := h.DB.UserRepo.ReplaceRecoveryCodes(r.Context(), user.ID, hashes); err != nil {
+		h.errorLog.Println("ERROR_05_RegenerateRecoveryCodes: db error:", err)
+		utils.ServerError(w, errors.New("failed to save recovery codes"))
+		return
+	}
+
+	writeRecoveryCodes(w, "New recovery codes generated. The previous codes no longer work", codes)
+}
+
+// newRecoveryCodes generates plain recovery codes together with the hashes to store.
+func newRecoveryCodes() ([]string, []string, error) {
+	codes, err := utils.GenerateRecoveryCodes(recoveryCodeCount)
+	if err != nil {
+		return nil, nil, err
+	}
+	hashes := make([]string, len(codes))
+	for i, c := range codes {
+		hashes[i] = utils.HashToken(utils.NormalizeRecoveryCode(c))
+	}
+	return codes, hashes, nil
+}
+
+func writeRecoveryCodes(w http.ResponseWriter, msg string, codes []string) {
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error         bool     `json:"error"`
+		Message       string   `json:"message"`
+		RecoveryCodes []string `json:"recoveryCodes"`
+	}{
+		Error:         false,
+		Message:       msg,
+		RecoveryCodes: codes,
+	})
+}
@@ -0,0 +1,492 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+)
+
+// UserHandler serves the admin user management endpoints.
+type UserHandler struct {
+	DB       *dbrepo.DBRepository
+	infoLog  *log.Logger
+	errorLog *log.Logger
+}
+
+func newUserHandler(db *dbrepo.DBRepository, infoLog, errorLog *log.Logger) UserHandler {
+	return UserHandler{
+		DB:       db,
+		infoLog:  infoLog,
+		errorLog: errorLog,
+	}
+}
+
+// validUserStatus reports whether an admin may set status directly ("Locked" is only set by the sign-in lockout).
+func validUserStatus(status string) bool {
+	return status == "Active" || status == "Inactive"
+}
+
+// GetAllUsers returns a page of users.
+// Query parameters: page, limit, role, status, search, sortBy, sortOrder (all optional).
+func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+
+	page, limit := 1, 20
+	if v := queryParams.Get("page"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil || val < 1 {
+			utils.BadRequest(w, errors.New("Invalid format for 'page'. Must be a positive integer."))
+			return
+		}
+		page = val
+	}
+	if v := queryParams.Get("limit"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil || val < 1 || val > 100 {
+			utils.BadRequest(w, errors.New("Invalid format for 'limit'. Must be between 1 and 100."))
+			return
+		}
+		limit = val
+	}
+
+	users, total, err := h.DB.UserRepo.PaginatedUserList(r.Context(), page, limit,
+		strings.TrimSpace(queryParams.Get("role")),
+		strings.TrimSpace(queryParams.Get("status")),
+		strings.TrimSpace(queryParams.Get("search")),
+		queryParams.Get("sortBy"),
+		queryParams.Get("sortOrder"),
+	)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetAllUsers_01: db error:", err)
+		utils.ServerError(w, errors.New("failed to retrieve users"))
+		return
+	}
+
+	var response struct {
+		Error   bool           `json:"error"`
+		Message string         `json:"message"`
+		Users   []*models.User `json:"users"`
+		Total   int            `json:"total"`
+		Page    int            `json:"page"`
+		Limit   int            `json:"limit"`
+	}
+	response.Error = false
+	response.Message = "Users fetched successfully"
+	response.Users = users
+	response.Total = total
+	response.Page = page
+	response.Limit = limit
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetRoles lists the available roles and the permissions each one grants.
+func (h *UserHandler) GetRoles(w http.ResponseWriter, r *http.Request) {
+	var response struct {
+		Error       bool                           `json:"error"`
+		Message     string                         `json:"message"`
+		Roles       map[string][]models.Permission `json:"roles"`
+		Permissions []models.Permission            `json:"permissions"`
+	}
+	response.Error = false
+	response.Message = "Roles fetched successfully"
+	response.Roles = models.RolePermissions
+	response.Permissions = models.AllPermissions
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetUser retrieves a single user by ID.
+func (h *UserHandler) GetUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid user ID"))
+		return
+	}
+
+	user, err := h.DB.UserRepo.GetUserByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetUser_01: db error:", err)
+		utils.NotFound(w, "user not found")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, user)
+}
+
+// CreateUser creates a new user account. The password is stored as a bcrypt hash.
+func (h *UserHandler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	type createUserRequest struct {
+		Name     string `json:"name"`
+		Role     string `json:"role"`
+		Status   string `json:"status"`
+		Mobile   string `json:"mobile"`
+		Email    string `json:"email"`
+		Password string `json:"password"`
+		Address  string `json:"address"`
+	}
+
+	var req createUserRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_CreateUser_01: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	// Basic Validation
+	req.Name = strings.TrimSpace(req.Name)
+	req.Role = strings.TrimSpace(req.Role)
+	req.Status = strings.TrimSpace(req.Status)
+	req.Mobile = strings.TrimSpace(req.Mobile)
+	req.Email = strings.TrimSpace(req.Email)
+	req.Password = strings.TrimSpace(req.Password)
+	req.Address = strings.TrimSpace(req.Address)
+
+	if req.Name == "" || req.Mobile == "" {
+		utils.BadRequest(w, errors.New("name and mobile are required"))
+		return
+	}
+	if len(req.Password) < 6 {
+		utils.BadRequest(w, errors.New("password must be at least 6 characters long"))
+		return
+	}
+	if req.Role == "" {
+		req.Role = "Operator"
+	}
+	if _, ok := models.RolePermissions[req.Role]; !ok {
+		utils.BadRequest(w, fmt.Errorf("unknown role: %s", req.Role))
+		return
+	}
+	if req.Status == "" {
+		req.Status = "Active"
+	}
+	if !validUserStatus(req.Status) {
+		utils.BadRequest(w, errors.New("status must be either Active or Inactive"))
+		return
+	}
+
+	hashedPassword, err := utils.HashPassword(req.Password)
+	if err != nil {
+		h.errorLog.Println("ERROR_CreateUser_02: failed to hash password:", err)
+		utils.ServerError(w, errors.New("internal server error during password hashing"))
+		return
+	}
+
+	user := &models.User{
+		Name:     req.Name,
+		Role:     req.Role,
+		Status:   req.Status,
+		Mobile:   req.Mobile,
+		Email:    req.Email,
+		Password: hashedPassword,
+		Address:  req.Address,
+	}
+	if err := h.DB.UserRepo.CreateUser(r.Context(), user); err != nil {
+		h.errorLog.Println("ERROR_CreateUser_03: db create:", err)
+		utils.BadRequest(w, err)
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusCreated, struct {
+		Error   bool         `json:"error"`
+		Message string       `json:"message"`
+		Data    *models.User `json:"data"`
+	}{
+		Error:   false,
+		Message: "User created successfully",
+		Data:    user,
+	})
+}
+
+// UpdateUser edits a user's profile. Query parameter {id}.
+// Changing the role signs the user out, so the new role takes effect immediately.
+func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid user ID"))
+		return
+	}
+
+	type updateUserRequest struct {
+		Name    string `json:"name"`
+		Role    string `json:"role"`
+		Mobile  string `json:"mobile"`
+		Email   string `json:"email"`
+		Address string `json:"address"`
+	}
+
+	var req updateUserRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_UpdateUser_01: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	// 1. Fetch existing user
+	existing, err := h.DB.UserRepo.GetUserByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_UpdateUser_02: fetch error:", err)
+		utils.NotFound(w, "user not found")
+		return
+	}
+	oldRole := existing.Role
+
+	// 2. Update whatever is provided in the request
+	if v := strings.TrimSpace(req.Name); v != "" {
+		existing.Name = v
+	}
+	if v := strings.TrimSpace(req.Role); v != "" {
+		if _, ok := models.RolePermissions[v]; !ok {
+			utils.BadRequest(w, fmt.Errorf("unknown role: %s", v))
+			return
+		}
+		existing.Role = v
+	}
+	if v := strings.TrimSpace(req.Mobile); v != "" {
+		existing.Mobile = v
+	}
+	if v := strings.TrimSpace(req.Email); v != "" {
+		existing.Email = v
+	}
+	if v := strings.TrimSpace(req.Address); v != "" {
+		existing.Address = v
+	}
+
+	// 3. Perform Update
+	if err := h.DB.UserRepo.UpdateUser(r.Context(), existing); err != nil {
+		h.errorLog.Println("ERROR_UpdateUser_03: update error:", err)
+		utils.BadRequest(w, err)
+		return
+	}
+
+	if existing.Role != oldRole {
+		if err := h.DB.SessionRepo.RevokeAllForUser(r.Context(), id); err != nil {
+			h.errorLog.Println("ERROR_UpdateUser_04: revoke sessions:", err)
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error   bool         `json:"error"`
+		Message string       `json:"message"`
+		Data    *models.User `json:"data"`
+	}{
+		Error:   false,
+		Message: "User updated successfully",
+		Data:    existing,
+	})
+}
+
+// UpdateUserStatus activates or deactivates an account. Query parameter {id}.
+// Deactivating an account also revokes all its sessions.
+func (h *UserHandler) UpdateUserStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid user ID"))
+		return
+	}
+
+	type statusRequest struct {
+		Status string `json:"status"`
+	}
+
+	var req statusRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_UpdateUserStatus_01: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	req.Status = strings.TrimSpace(req.Status)
+	if !validUserStatus(req.Status) {
+		utils.BadRequest(w, errors.New("status must be either Active or Inactive"))
+		return
+	}
+
+	// An admin cannot lock themselves out
+	if authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT); ok && authClaims.ID == id && req.Status != "Active" {
+		utils.BadRequest(w, errors.New("you cannot deactivate your own account"))
+		return
+	}
+
+	existing, err := h.DB.UserRepo.GetUserByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_UpdateUserStatus_02: fetch error:", err)
+		utils.NotFound(w, "user not found")
+		return
+	}
+
+	// Activating a locked account is the same as unlocking it
+	if existing.Status == "Locked" && req.Status == "Active" {
+		if err := h.DB.UserRepo.UnlockUser(r.Context(), id); err != nil {
+			h.errorLog.Println("ERROR_UpdateUserStatus_03: unlock:", err)
+			utils.ServerError(w, errors.New("failed to update user status"))
+			return
+		}
+	} else if err := h.DB.UserRepo.UpdateUserStatus(r.Context(), id, existing.Role, req.Status); err != nil {
+		h.errorLog.Println("ERROR_UpdateUserStatus_03: db update:", err)
+		utils.ServerError(w, errors.New("failed to update user status"))
+		return
+	}
+
+	if req.Status != "Active" {
+		if err := h.DB.SessionRepo.RevokeAllForUser(r.Context(), id); err != nil {
+			h.errorLog.Println("ERROR_UpdateUserStatus_04: revoke sessions:", err)
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.Response{
+		Error:   false,
+		Message: fmt.Sprintf("User status updated to %s", req.Status),
+	})
+}
+
+// UnlockUser lifts a sign-in lock caused by repeated failed attempts. Query parameter {id}.
+func (h *UserHandler) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid user ID"))
+		return
+	}
+
+	if err := h.DB.UserRepo.UnlockUser(r.Context(), id); err != nil {
+		h.errorLog.Println("ERROR_UnlockUser_01: db error:", err)
+		utils.NotFound(w, "user not found")
+		return
+	}
+
+	if authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT); ok {
+		h.infoLog.Printf("User ID %d unlocked user ID %d.", authClaims.ID, id)
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.Response{
+		Error:   false,
+		Message: "User unlocked successfully",
+	})
+}
+
+// ResetTwoFactor switches two-factor off for a user who lost their authenticator and recovery codes.
+// Query parameter {id}. All sessions of the user are revoked.
+func (h *UserHandler) ResetTwoFactor(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid user ID"))
+		return
+	}
+
+	if err := h.DB.UserRepo.DisableTOTP(r.Context(), id); err != nil {
+		h.errorLog.Println("ERROR_ResetTwoFactor_01: db error:", err)
+		utils.ServerError(w, errors.New("failed to reset two-factor authentication"))
+		return
+	}
+	if err := h.DB.SessionRepo.RevokeAllForUser(r.Context(), id); err != nil {
+		h.errorLog.Println("ERROR_ResetTwoFactor_02: revoke sessions:", err)
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.Response{
+		Error:   false,
+		Message: "Two-factor authentication has been reset for the user",
+	})
+}
+
+// UpdateUserAvatar uploads a new avatar image for a user. Query parameter {id}, form file "avatar".
+func (h *UserHandler) UpdateUserAvatar(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid user ID"))
+		return
+	}
+
+	// 1. Parse Multipart Form (5MB limit)
+	if err := r.ParseMultipartForm(5 << 20); err != nil {
+		h.errorLog.Println("ERROR_UpdateUserAvatar_01: parsing form:", err)
+		utils.BadRequest(w, errors.New("file too large or invalid form data"))
+		return
+	}
+
+	existing, err := h.DB.UserRepo.GetUserByID(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_UpdateUserAvatar_02: fetch error:", err)
+		utils.NotFound(w, "user not found")
+		return
+	}
+
+	file, header, err := r.FormFile("avatar")
+	if err != nil {
+		h.errorLog.Println("ERROR_UpdateUserAvatar_03: retrieving file:", err)
+		utils.BadRequest(w, errors.New("avatar image is required"))
+		return
+	}
+	defer file.Close()
+
+	// 2. Save Image to File System: id_token.ext
+	ext := strings.ToLower(filepath.Ext(header.Filename))
+	if ext == "" {
+		ext = ".jpg"
+	}
+	switch ext {
+	case ".jpg", ".jpeg", ".png", ".webp", ".gif":
+	default:
+		utils.BadRequest(w, errors.New("avatar must be a jpg, png, webp or gif image"))
+		return
+	}
+	// The name is built from the ID and a random token only, never from user input
+	token, err := utils.GenerateOpaqueToken(8)
+	if err != nil {
+		h.errorLog.Println("ERROR_UpdateUserAvatar_04: token:", err)
+		utils.ServerError(w, errors.New("server storage error"))
+		return
+	}
+	filename := fmt.Sprintf("%d_%s%s", id, token, ext)
+
+	storagePath := filepath.Join("data", "images", "users")
+	if err := os.MkdirAll(storagePath, 0755); err != nil {
+		h.errorLog.Println("ERROR_UpdateUserAvatar_05: mkdir:", err)
+		utils.ServerError(w, errors.New("server storage error"))
+		return
+	}
+
+	dst, err := os.Create(filepath.Join(storagePath, filename))
+	if err != nil {
+		h.errorLog.Println("ERROR_UpdateUserAvatar_06: create file:", err)
+		utils.ServerError(w, errors.New("failed to create image file"))
+		return
+	}
+	defer dst.Close()
+
+	if _, err := io.Copy(dst, file); err != nil {
+		h.errorLog.Println("ERROR_UpdateUserAvatar_07: save file:", err)
+		utils.ServerError(w, errors.New("failed to save image content"))
+		return
+	}
+
+	// 3. Update Database with Avatar Link, then remove the previous file if its name changed
+	if err := h.DB.UserRepo.UpdateUserAvatarLink(r.Context(), id, filename); err != nil {
+		h.errorLog.Println("ERROR_UpdateUserAvatar_08: update link:", err)
+		utils.ServerError(w, errors.New("failed to update avatar"))
+		return
+	}
+	if existing.AvatarLink != "" && existing.AvatarLink != filename && !strings.ContainsAny(existing.AvatarLink, `/\`) {
+		if err := os.Remove(filepath.Join(storagePath, existing.AvatarLink)); err != nil && !os.IsNotExist(err) {
+			h.errorLog.Println("WARNING_UpdateUserAvatar_09: failed to delete old avatar:", err)
+		}
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error      bool   `json:"error"`
+		Message    string `json:"message"`
+		AvatarLink string `json:"avatarLink"`
+	}{
+		Error:      false,
+		Message:    "Avatar updated successfully",
+		AvatarLink: filename,
+	})
+}
@@ -0,0 +1,463 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+	"github.com/projuktisheba/ajfses/backend/internal/webhook"
+)
+
+// WebhookHandler manages the webhook endpoints and serves the delivery log.
+type WebhookHandler struct {
+	DB       *dbrepo.DBRepository
+	Webhooks *webhook.Dispatcher
+	infoLog  *log.Logger
+	errorLog *log.Logger
+}
+
+func newWebhookHandler(db *dbrepo.DBRepository, hooks *webhook.Dispatcher, infoLog, errorLog *log.Logger) WebhookHandler {
+	return WebhookHandler{
+		DB:       db,
+		Webhooks: hooks,
+		infoLog:  infoLog,
+		errorLog: errorLog,
+	}
+}
+
+// webhookEndpointRequest is the body of CreateWebhook and UpdateWebhook.
+type webhookEndpointRequest struct {
+	URL         string   `json:"url"`
+	Description string   `json:"description"`
+	Events      []string `json:"events"`
+	Active      *bool    `json:"active"`
+}
+
+// apply validates the request and copies it onto e. Events are de-duplicated; "*" subscribes to all.
+func (req *webhookEndpointRequest) apply(e *models.WebhookEndpoint) error {
+	rawURL := strings.TrimSpace(req.URL)
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return errors.New("url must be an absolute http or https URL")
+	}
+	if len(rawURL) > 2000 {
+		return errors.New("url is too long")
+	}
+
+	description := strings.TrimSpace(req.Description)
+	if len(description) > 255 {
+		return errors.New("description is too long (max 255 characters)")
+	}
+
+	known := map[string]bool{models.WebhookEventAll: true}
+	for _, ev := range models.WebhookEvents {
+		known[ev] = true
+	}
+	events := []string{}
+	seen := map[string]bool{}
+	for _, ev := range req.Events {
+		ev = strings.ToLower(strings.TrimSpace(ev))
+		if !known[ev] {
+			return fmt.Errorf("unknown event %q", ev)
+		}
+		if !seen[ev] {
+			seen[ev] = true
+			events = append(events, ev)
+		}
+	}
+	if len(events) == 0 {
+		return errors.New("at least one event is required")
+	}
+
+	e.URL = rawURL
+	e.Description = description
+	e.Events = events
+	if req.Active != nil {
+		e.Active = *req.Active
+	}
+	return nil
+}
+
+// GetWebhookEvents lists the events endpoints can subscribe to.
+func (h *WebhookHandler) GetWebhookEvents(w http.ResponseWriter, r *http.Request) {
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error  bool     `json:"error"`
+		Events []string `json:"events"`
+	}{
+		Error:  false,
+		Events: models.WebhookEvents,
+	})
+}
+
+// GetWebhooks lists the registered endpoints.
+func (h *WebhookHandler) GetWebhooks(w http.ResponseWriter, r *http.Request) {
+	endpoints, err := h.DB.WebhookRepo.GetEndpoints(r.Context())
+	if err != nil {
+		h.errorLog.Println("ERROR_GetWebhooks_01: db error:", err)
+		utils.ServerError(w, errors.New("failed to retrieve webhooks"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error     bool                     `json:"error"`
+		Message   string                   `json:"message"`
+		Endpoints []models.WebhookEndpoint `json:"endpoints"`
+	}{
+		Error:     false,
+		Message:   "Webhooks fetched successfully",
+		Endpoints: endpoints,
+	})
+}
+
+// GetWebhook returns a single endpoint.
+func (h *WebhookHandler) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid webhook ID"))
+		return
+	}
+
+	endpoint, err := h.DB.WebhookRepo.GetEndpoint(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetWebhook_01: db error:", err)
+		utils.NotFound(w, "webhook not found")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error bool                    `json:"error"`
+		Data  *models.WebhookEndpoint `json:"data"`
+	}{
+		Error: false,
+		Data:  endpoint,
+	})
+}
+
+// CreateWebhook registers an endpoint. The response carries the signing secret, which is not shown again.
+func (h *WebhookHandler) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	authClaims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+	if !ok {
+		h.errorLog.Println("ERROR_CreateWebhook_01: authentication claims not found in context.")
+		utils.Unauthorized(w, errors.New("authentication context missing. Please log in again."))
+		return
+	}
+
+	var req webhookEndpointRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_CreateWebhook_02: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	endpoint := &models.WebhookEndpoint{Active: true, CreatedBy: &authClaims.ID}
+	if err := req.apply(endpoint); err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		h.errorLog.Println("ERROR_CreateWebhook_03: failed to generate secret:", err)
+		utils.ServerError(w, errors.New("failed to generate secret"))
+		return
+	}
+	endpoint.Secret = secret
+
+	if err := h.DB.WebhookRepo.CreateEndpoint(r.Context(), endpoint); err != nil {
+		h.errorLog.Println("ERROR_CreateWebhook_04: db error:", err)
+		utils.ServerError(w, errors.New("failed to create webhook"))
+		return
+	}
+
+	h.infoLog.Printf("User ID %d registered webhook %d (%s).", authClaims.ID, endpoint.ID, endpoint.URL)
+	writeWebhookSecret(w, http.StatusCreated, "Webhook created. Store the secret now, it is not shown again", endpoint)
+}
+
+// UpdateWebhook changes the URL, description, events or active flag of an endpoint.
+func (h *WebhookHandler) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid webhook ID"))
+		return
+	}
+
+	var req webhookEndpointRequest
+	if err := utils.ReadJSON(w, r, &req); err != nil {
+		h.errorLog.Println("ERROR_UpdateWebhook_01: invalid JSON:", err)
+		utils.BadRequest(w, fmt.Errorf("invalid request payload: %w", err))
+		return
+	}
+
+	endpoint, err := h.DB.WebhookRepo.GetEndpoint(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_UpdateWebhook_02: fetch error:", err)
+		utils.NotFound(w, "webhook not found")
+		return
+	}
+	if err := req.apply(endpoint); err != nil {
+		utils.BadRequest(w, err)
+		return
+	}
+
+	if err := h.DB.WebhookRepo.UpdateEndpoint(r.Context(), endpoint); err != nil {
+		h.errorLog.Println("ERROR_UpdateWebhook_03: db error:", err)
+		utils.ServerError(w, errors.New("failed to update webhook"))
+		return
+	}
+
+	// Deliveries held back while the endpoint was inactive are due again
+	if endpoint.Active {
+		h.Webhooks.Wake()
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error   bool                    `json:"error"`
+		Message string                  `json:"message"`
+		Data    *models.WebhookEndpoint `json:"data"`
+	}{
+		Error:   false,
+		Message: "Webhook updated successfully",
+		Data:    endpoint,
+	})
+}
+
+// RotateWebhookSecret replaces the signing secret of an endpoint and returns the new one.
+// Deliveries sent from now on, including retries, are signed with the new secret.
+func (h *WebhookHandler) RotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid webhook ID"))
+		return
+	}
+
+	endpoint, err := h.DB.WebhookRepo.GetEndpoint(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_RotateWebhookSecret_01: fetch error:", err)
+		utils.NotFound(w, "webhook not found")
+		return
+	}
+
+	secret, err := webhook.GenerateSecret()
+	if err != nil {
+		h.errorLog.Println("ERROR_RotateWebhookSecret_02: failed to generate secret:", err)
+		utils.ServerError(w, errors.New("failed to generate secret"))
+		return
+	}
+	if err := h.DB.WebhookRepo.RotateSecret(r.Context(), id, secret); err != nil {
+		h.errorLog.Println("ERROR_RotateWebhookSecret_03: db error:", err)
+		utils.ServerError(w, errors.New("failed to rotate secret"))
+		return
+	}
+	endpoint.Secret = secret
+
+	writeWebhookSecret(w, http.StatusOK, "Secret rotated. Store the new secret now, it is not shown again", endpoint)
+}
+
+func writeWebhookSecret(w http.ResponseWriter, status int, msg string, endpoint *models.WebhookEndpoint) {
+	utils.WriteJSON(w, status, struct {
+		Error   bool                    `json:"error"`
+		Message string                  `json:"message"`
+		Data    *models.WebhookEndpoint `json:"data"`
+		Secret  string                  `json:"secret"`
+	}{
+		Error:   false,
+		Message: msg,
+		Data:    endpoint,
+		Secret:  endpoint.Secret,
+	})
+}
+
+// DeleteWebhook removes an endpoint together with its delivery log.
+func (h *WebhookHandler) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid webhook ID"))
+		return
+	}
+
+	if err := h.DB.WebhookRepo.DeleteEndpoint(r.Context(), id); err != nil {
+		h.errorLog.Println("ERROR_DeleteWebhook_01: db error:", err)
+		utils.ServerError(w, errors.New("failed to delete webhook"))
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, models.Response{
+		Error:   false,
+		Message: "Webhook deleted successfully",
+	})
+}
+
+// PingWebhook queues a "ping" event for one endpoint to check that it receives and verifies deliveries.
+func (h *WebhookHandler) PingWebhook(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid webhook ID"))
+		return
+	}
+
+	endpoint, err := h.DB.WebhookRepo.GetEndpoint(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_PingWebhook_01: fetch error:", err)
+		utils.NotFound(w, "webhook not found")
+		return
+	}
+	if !endpoint.Active {
+		utils.BadRequest(w, errors.New("the webhook is inactive"))
+		return
+	}
+
+	eventID, payload, err := webhook.NewEnvelope(models.WebhookEventPing, struct {
+		EndpointID int64  `json:"endpointId"`
+		Message    string `json:"message"`
+	}{
+		EndpointID: endpoint.ID,
+		Message:    "Webhook test delivery",
+	})
+	if err != nil {
+		h.errorLog.Println("ERROR_PingWebhook_02:", err)
+		utils.ServerError(w, errors.New("failed to queue test delivery"))
+		return
+	}
+
+	deliveryID, err := h.DB.WebhookRepo.EnqueueTo(r.Context(), endpoint.ID, models.WebhookEventPing, eventID, payload)
+	if err != nil {
+		h.errorLog.Println("ERROR_PingWebhook_03: db error:", err)
+		utils.ServerError(w, errors.New("failed to queue test delivery"))
+		return
+	}
+	h.Webhooks.Wake()
+
+	writeDeliveryQueued(w, "Test delivery queued", deliveryID)
+}
+
+// GetWebhookDeliveries returns a page of the delivery log, newest first.
+func (h *WebhookHandler) GetWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	queryParams := r.URL.Query()
+
+	filter := models.WebhookDeliveryFilter{Page: 1, Limit: 20}
+	if v := queryParams.Get("page"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil || val < 1 {
+			utils.BadRequest(w, errors.New("Invalid format for 'page'. Must be a positive integer."))
+			return
+		}
+		filter.Page = val
+	}
+	if v := queryParams.Get("limit"); v != "" {
+		val, err := strconv.Atoi(v)
+		if err != nil || val < 1 || val > 100 {
+			utils.BadRequest(w, errors.New("Invalid format for 'limit'. Must be between 1 and 100."))
+			return
+		}
+		filter.Limit = val
+	}
+	if v := queryParams.Get("endpoint"); v != "" {
+		val, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || val < 1 {
+			utils.BadRequest(w, errors.New("Invalid format for 'endpoint'. Must be a webhook ID."))
+			return
+		}
+		filter.EndpointID = val
+	}
+
+	filter.Status = strings.ToUpper(strings.TrimSpace(queryParams.Get("status")))
+	switch filter.Status {
+	case "", models.WebhookStatusPending, models.WebhookStatusSucceeded, models.WebhookStatusFailed:
+	default:
+		utils.BadRequest(w, errors.New("Invalid 'status'. Must be PENDING, SUCCEEDED or FAILED."))
+		return
+	}
+	filter.Event = strings.ToLower(strings.TrimSpace(queryParams.Get("event")))
+
+	deliveries, total, err := h.DB.WebhookRepo.GetDeliveries(r.Context(), filter)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetWebhookDeliveries_01: db error:", err)
+		utils.ServerError(w, errors.New("failed to retrieve webhook deliveries"))
+		return
+	}
+
+	var response struct {
+		Error      bool                     `json:"error"`
+		Message    string                   `json:"message"`
+		Deliveries []models.WebhookDelivery `json:"deliveries"`
+		Total      int                      `json:"total"`
+		Page       int                      `json:"page"`
+		Limit      int                      `json:"limit"`
+	}
+	response.Error = false
+	response.Message = "Webhook deliveries fetched successfully"
+	response.Deliveries = deliveries
+	response.Total = total
+	response.Page = filter.Page
+	response.Limit = filter.Limit
+	utils.WriteJSON(w, http.StatusOK, response)
+}
+
+// GetWebhookDelivery returns a delivery with its payload and the endpoint's last response.
+func (h *WebhookHandler) GetWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid delivery ID"))
+		return
+	}
+
+	delivery, err := h.DB.WebhookRepo.GetDelivery(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_GetWebhookDelivery_01: db error:", err)
+		utils.NotFound(w, "delivery not found")
+		return
+	}
+
+	utils.WriteJSON(w, http.StatusOK, struct {
+		Error bool                    `json:"error"`
+		Data  *models.WebhookDelivery `json:"data"`
+	}{
+		Error: false,
+		Data:  delivery,
+	})
+}
+
+// ReplayWebhookDelivery sends a delivery again as a new entry of the log, with the same event ID and payload.
+func (h *WebhookHandler) ReplayWebhookDelivery(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(strings.TrimSpace(r.URL.Query().Get("id")), 10, 64)
+	if err != nil {
+		utils.BadRequest(w, errors.New("invalid delivery ID"))
+		return
+	}
+
+	if _, err := h.DB.WebhookRepo.GetDelivery(r.Context(), id); err != nil {
+		h.errorLog.Println("ERROR_ReplayWebhookDelivery_01: fetch error:", err)
+		utils.NotFound(w, "delivery not found")
+		return
+	}
+
+	deliveryID, err := h.DB.WebhookRepo.Replay(r.Context(), id)
+	if err != nil {
+		h.errorLog.Println("ERROR_ReplayWebhookDelivery_02: db error:", err)
+		utils.ServerError(w, errors.New("failed to replay delivery"))
+		return
+	}
+	h.Webhooks.Wake()
+
+	writeDeliveryQueued(w, "Delivery queued for replay", deliveryID)
+}
+
+func writeDeliveryQueued(w http.ResponseWriter, msg string, deliveryID int64) {
+	utils.WriteJSON(w, http.StatusAccepted, struct {
+		Error      bool   `json:"error"`
+		Message    string `json:"message"`
+		DeliveryID int64  `json:"deliveryId"`
+	}{
+		Error:      false,
+		Message:    msg,
+		DeliveryID: deliveryID,
+	})
+}
@@ -3,11 +3,13 @@ package middlewares
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log"
 	"net/http"
 	"strings"
 
 	// Assuming you use this library
+	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
 	"github.com/projuktisheba/ajfses/backend/internal/models"
 	"github.com/projuktisheba/ajfses/backend/internal/utils"
 )
@@ -16,7 +18,9 @@ import (
 type ContextKey string
 
 // AuthJWT creates a middleware function to validate a JWT and inject claims into the context.
-func AuthJWT(jwtConfig models.JWTConfig, errorLog *log.Logger) func(http.Handler) http.Handler {
+// Besides the signature it checks the token against the database, so logged out tokens,
+// tokens issued before a "revoke all sessions" and tokens of inactive users are rejected.
+func AuthJWT(jwtConfig models.JWTConfig, db *dbrepo.DBRepository, errorLog *log.Logger) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 
@@ -46,20 +50,63 @@ func AuthJWT(jwtConfig models.JWTConfig, errorLog *log.Logger) func(http.Handler
 				return
 			}
 
-			// 4. Role Check (Only Admin is allowed for the Admin endpoints)
-			if claims.Role != "Admin" {
+			// 4. Role Check (roles without any permission cannot use the API;
+			// per-route permissions are enforced by RequirePermission)
+			if len(models.PermissionsFor(claims.Role)) == 0 {
 				errorLog.Printf("ERROR_04_AuthJWT: Access denied for role: %s", claims.Role)
-				utils.Unauthorized(w, errors.New("access denied. Insufficient privileges"))
+				utils.Forbidden(w, errors.New("access denied. Insufficient privileges"))
 				return
 			}
 
-			// 5. Inject Claims into Context
+			// 5. Revocation Check (logout, revoked sessions, deactivated account)
+			state, err := db.SessionRepo.GetState(r.Context(), claims.ID, claims.TokenID)
+			if err != nil {
+				errorLog.Printf("ERROR_05_AuthJWT: session lookup failed: %v", err)
+				utils.Unauthorized(w, errors.New("invalid or expired token"))
+				return
+			}
+			if state.TokenRevoked || state.TokenVersion != claims.TokenVersion {
+				errorLog.Printf("ERROR_06_AuthJWT: revoked token used by user %d", claims.ID)
+				utils.Unauthorized(w, errors.New("session has been revoked. Please sign in again"))
+				return
+			}
+			// A locked account only blocks new sign-ins, so existing sessions survive a lockout attack
+			if state.Status == "Inactive" {
+				errorLog.Printf("ERROR_07_AuthJWT: inactive user %d", claims.ID)
+				utils.Unauthorized(w, errors.New("account is not active"))
+				return
+			}
+
+			// 6. Inject Claims into Context
 			// Use the context key to store the authenticated user claims
 			// The key must match the one used in the handler (ContextKey("authClaims"))
 			ctx := context.WithValue(r.Context(), models.AuthClaimsContextKey, *claims)
 
-			// 6. Serve the next handler with the updated context
+			// 7. Serve the next handler with the updated context
 			next.ServeHTTP(w, r.WithContext(ctx))
 		})
 	}
 }
+
+// RequirePermission creates a middleware that only lets requests through when the authenticated
+// user's role grants the permission p. It must run after AuthJWT.
+func RequirePermission(p models.Permission, errorLog *log.Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			claims, ok := r.Context().Value(models.AuthClaimsContextKey).(models.JWT)
+			if !ok {
+				errorLog.Println("ERROR_01_RequirePermission: authentication claims not found in context.")
+				utils.Unauthorized(w, errors.New("authorization token required"))
+				return
+			}
+
+			if !models.HasPermission(claims.Role, p) {
+				errorLog.Printf("ERROR_02_RequirePermission: role %s lacks permission %s", claims.Role, p)
+				utils.Forbidden(w, fmt.Errorf("access denied. Missing permission: %s", p))
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package middlewares
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+)
+
+// ========================= RATE LIMIT ==============================
+
+// rateWindow counts the requests of one client in the current fixed window.
+type rateWindow struct {
+	start time.Time
+	count int
+}
+
+// RateLimit creates a middleware that allows each client IP at most limit requests per window.
+// Counters are kept in memory, so every instance of the server limits on its own and a restart resets them.
+// A limit of zero or less disables the middleware.
+func RateLimit(limit int, window time.Duration, errorLog *log.Logger) func(http.Handler) http.Handler {
+	var mu sync.Mutex
+	clients := map[string]*rateWindow{}
+	lastSweep := time.Now()
+
+	return func(next http.Handler) http.Handler {
+		if limit <= 0 {
+			return next
+		}
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ip := utils.ClientIP(r)
+			now := time.Now()
+
+			mu.Lock()
+			// Forget clients whose window has ended so the map does not grow without bound
+			if now.Sub(lastSweep) > window {
+				for key, c := range clients {
+					if now.Sub(c.start) >= window {
+						delete(clients, key)
+					}
+				}
+				lastSweep = now
+			}
+
+			c, ok := clients[ip]
+			if !ok || now.Sub(c.start) >= window {
+				c = &rateWindow{start: now}
+				clients[ip] = c
+			}
+			c.count++
+			count, retryAfter := c.count, c.start.Add(window).Sub(now)
+			mu.Unlock()
+
+			if count > limit {
+				errorLog.Printf("ERROR_01_RateLimit: %s exceeded %d requests per %s on %s", ip, limit, window, r.URL.Path)
+				utils.TooManyRequests(w, errors.New("too many requests, please try again later"), retryAfter)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
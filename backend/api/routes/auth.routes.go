@@ -2,6 +2,8 @@ package routes
 
 import (
 	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/api/middlewares"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
 )
 
 // Assuming hRepo, cfg, and errorLog are available to this function.
@@ -10,17 +12,40 @@ func authRoutes() *chi.Mux {
 
 	// ======== Public Route ========
 	mux.Post("/signin", handlerRepo.Auth.Signin)
+	mux.Post("/refresh", handlerRepo.Auth.Refresh)
+	// Second step of a two-factor sign-in: { challengeToken, code | recoveryCode }
+	mux.Post("/2fa/verify", handlerRepo.Auth.VerifyTwoFactor)
+	// Self-service password reset: { email } emails a link, { token, newPassword } redeems it.
+	// Requests are rate limited per IP on top of the per-account cap in the handler
+	mux.With(middlewares.RateLimit(handlerRepo.Auth.LoginConfig.ResetRateLimit, handlerRepo.Auth.LoginConfig.ResetRateWindow, handlerRepo.ErrorLog)).
+		Post("/forgot-password", handlerRepo.Auth.ForgotPassword)
+	mux.Post("/reset-password", handlerRepo.Auth.ResetPassword)
+
+	// ======== Authenticated Routes ========
+	mux.With(authJWT).Post("/logout", handlerRepo.Auth.Logout)
+
+	// ======== Two-Factor Management (own account) ========
+	mux.Route("/2fa", func(r chi.Router) {
+		r.Use(authJWT)
+		r.Post("/enroll", handlerRepo.Auth.EnrollTwoFactor)
+		r.Post("/enable", handlerRepo.Auth.EnableTwoFactor)
+		r.Post("/disable", handlerRepo.Auth.DisableTwoFactor)
+		r.Post("/recovery-codes", handlerRepo.Auth.RegenerateRecoveryCodes)
+	})
 
 	// ======== SECURED ADMIN ROUTES ========
 	// Use a group to apply middleware to multiple secure routes efficiently.
 	mux.Route("/admin", func(r chi.Router) {
 
 		// 1. Define ALL middleware first using r.Use()
-		r.Use(authAdmin)
+		r.Use(authJWT)
 
 		// 2. Define routes after middleware has been applied
 		r.Patch("/reset-password", handlerRepo.Auth.UpdatePassword)
 
+		// Query parameter {id}: sign the user out of every session
+		r.With(requirePermission(models.PermUserManage)).Post("/revoke-sessions", handlerRepo.Auth.RevokeSessions)
+
 		// If you had more admin routes, they go here:
 		// r.Get("/users", hRepo.Admin.ListUsers)
 	})
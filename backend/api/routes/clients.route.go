@@ -1,6 +1,9 @@
 package routes
 
-import "github.com/go-chi/chi/v5"
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
 
 // clientRoutes implements the routing for the ClientHandler.
 func clientRoutes() *chi.Mux {
@@ -17,8 +20,11 @@ func clientRoutes() *chi.Mux {
 	// GET /{id}: Retrieve a single client by ID (ID is expected in the URL path)
 	mux.Get("/profile/{id}", handlerRepo.Client.GetClient)
 
+	// GET /detail/{id}: The client with the inquiry it was converted from (staff only)
+	mux.With(authJWT, requirePermission(models.PermInquiryRead)).Get("/detail/{id}", handlerRepo.Client.GetClientDetail)
+
 	mux.Group(func(r chi.Router) {
-		r.Use(authAdmin)
+		r.Use(authJWT, requirePermission(models.PermClientWrite))
 		// POST /: Create a new client (handles multipart form data with image)
 		r.Post("/", handlerRepo.Client.CreateClient)
 
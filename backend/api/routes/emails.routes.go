@@ -0,0 +1,32 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// emailRoutes implements the routes for the outgoing email log and the email templates.
+func emailRoutes() *chi.Mux {
+	mux := chi.NewRouter()
+
+	// ======== Email Log Routes (email:read) ========
+	mux.Group(func(r chi.Router) {
+		r.Use(authJWT, requirePermission(models.PermEmailRead))
+
+		// Query parameters page, limit, status (SENT|FAILED), template, search (all optional)
+		r.Get("/", handlerRepo.Email.GetAllEmails)
+		r.Get("/templates", handlerRepo.Email.GetTemplates)
+	})
+
+	// ======== Email Template Routes (email:write) ========
+	mux.Group(func(r chi.Router) {
+		r.Use(authJWT, requirePermission(models.PermEmailWrite))
+
+		// Query parameter {name}, body { subject, text, html }
+		r.Put("/templates", handlerRepo.Email.UpdateTemplate)
+		// Query parameter {name}: restore the built-in default
+		r.Delete("/templates", handlerRepo.Email.ResetTemplate)
+	})
+
+	return mux
+}
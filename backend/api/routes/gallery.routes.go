@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
 )
 
 func galleryRoutes() *chi.Mux {
@@ -12,7 +13,7 @@ func galleryRoutes() *chi.Mux {
 
 	// Protected Routes (Apply Auth Middleware here if needed)
 	mux.Group(func(r chi.Router) {
-		r.Use(authAdmin)
+		r.Use(authJWT, requirePermission(models.PermGalleryWrite))
 
 		// Create: POST /gallery
 		r.Post("/", handlerRepo.Gallery.CreateGallery)
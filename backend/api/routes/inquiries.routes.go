@@ -2,23 +2,64 @@ package routes
 
 import (
 	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/api/middlewares"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
 )
 
 func inquiryRoutes() *chi.Mux {
 	mux := chi.NewRouter()
 
 	// ======== Inquiry Routes ========
-	mux.Post("/", handlerRepo.Inquiry.CreateInquiry)
+	// Public contact form: rate limited per IP; the form token is fetched when the form is shown.
+	// Accepts JSON or multipart/form-data with up to 5 files in "attachments"
+	mux.Get("/form-token", handlerRepo.Inquiry.GetFormToken)
+	mux.With(middlewares.RateLimit(handlerRepo.Inquiry.Config.RateLimit, handlerRepo.Inquiry.Config.RateWindow, handlerRepo.ErrorLog)).
+		Post("/", handlerRepo.Inquiry.CreateInquiry)
 
 	mux.Group(func(r chi.Router) {
-		r.Use(authAdmin)
-		//Query parameter pageLength, pageIndex, status (optional)
+		r.Use(authJWT, requirePermission(models.PermInquiryRead))
+		//Query parameter pageLength, pageIndex (1-based), status, from, to (YYYY-MM-DD), search,
+		//assignee (user ID, "me" or "none") (optional)
 		r.Get("/", handlerRepo.Inquiry.GetAllInquiries)
+		// Same query parameters as above; only inquiries assigned to the caller
+		r.Get("/mine", handlerRepo.Inquiry.GetMyInquiries)
+		// Same filters as the list plus format (csv or xlsx); streams every matching inquiry
+		r.Get("/export", handlerRepo.Inquiry.ExportInquiries)
+		//Query parameter from, to (YYYY-MM-DD, default the last 90 days), includeSpam (optional)
+		r.Get("/stats", handlerRepo.Inquiry.GetInquiryStats)
+		// Configured statuses and allowed transitions
+		r.Get("/workflow", handlerRepo.Inquiry.GetInquiryWorkflow)
+		// The inquiry with its conversation thread
+		r.Get("/{id}", handlerRepo.Inquiry.GetInquiry)
+		// Download of a file sent with an inquiry
+		r.Get("/attachments/{id}", handlerRepo.Inquiry.GetInquiryAttachment)
+	})
 
-		//Query parameter {id}
+	mux.Group(func(r chi.Router) {
+		r.Use(authJWT, requirePermission(models.PermInquiryWrite))
+		//Query parameter {id}, body { status, comment, ... }: status changes must follow the workflow
 		r.Patch("/update-status", handlerRepo.Inquiry.UpdateInquiry)
 		//Query parameter {id}
 		r.Delete("/", handlerRepo.Inquiry.DeleteInquiry)
+		//Query parameter {id}, body { assignedTo (null to unassign), note }
+		r.Patch("/assign", handlerRepo.Inquiry.AssignInquiry)
+		//Query parameter {id}, body { body }: internal note
+		r.Post("/notes", handlerRepo.Inquiry.AddInquiryNote)
+		//Query parameter {id}, body { body }: reply emailed to the customer
+		r.Post("/replies", handlerRepo.Inquiry.SendInquiryReply)
+	})
+
+	mux.Group(func(r chi.Router) {
+		r.Use(authJWT, requirePermission(models.PermInquiryWrite), requirePermission(models.PermClientWrite))
+		//Query parameter {id}, body { area, name, service_name, service_date, status, note, comment }:
+		//creates a client from the inquiry and marks it CONVERTED
+		r.Post("/convert", handlerRepo.Inquiry.ConvertInquiry)
+	})
+
+	mux.Group(func(r chi.Router) {
+		r.Use(authJWT, requirePermission(models.PermInquiryWorkflow))
+		// Body { statuses, transitions }: replaces the whole workflow
+		r.Put("/workflow", handlerRepo.Inquiry.UpdateInquiryWorkflow)
 	})
 
 	return mux
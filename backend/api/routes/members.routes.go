@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
 )
 
 func memberRoutes() *chi.Mux {
@@ -17,7 +18,7 @@ func memberRoutes() *chi.Mux {
 	mux.Get("/list", handlerRepo.Member.GetAllMembers)
 
 	mux.Group(func(r chi.Router) {
-		r.Use(authAdmin)
+		r.Use(authJWT, requirePermission(models.PermMemberWrite))
 		r.Post("/", handlerRepo.Member.CreateMember)
 		r.Delete("/", handlerRepo.Member.DeleteMember) //	query parament {id}
 		// mux.Get("/{id}", handlerRepo.Member.GetMember)
@@ -0,0 +1,70 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// productRoutes implements the routes of the product catalogue.
+func productRoutes() *chi.Mux {
+	mux := chi.NewRouter()
+
+	// ======== Public Product Routes ========
+
+	// Query parameters category and brand (slugs), search, page, limit (all optional); active products only
+	mux.Get("/", handlerRepo.Product.GetAllProducts)
+	mux.Get("/categories", handlerRepo.Product.GetProductCategories)
+	mux.Get("/brands", handlerRepo.Product.GetBrands)
+	// Products of the category and its subcategories; query parameters as GET / without category
+	mux.Get("/by-category/{slug}", handlerRepo.Product.GetProductsByCategory)
+	// Products of the brand; query parameters as GET / without brand
+	mux.Get("/by-brand/{slug}", handlerRepo.Product.GetProductsByBrand)
+	mux.Get("/datasheet/{id}", handlerRepo.Product.GetProductDatasheet)
+	mux.Get("/{id}", handlerRepo.Product.GetProduct)
+
+	// ======== Product Admin Routes (product:write) ========
+	mux.Group(func(r chi.Router) {
+		r.Use(authJWT, requirePermission(models.PermProductWrite))
+
+		// Same parameters as GET /, including inactive products
+		r.Get("/manage", handlerRepo.Product.GetAllProductsAdmin)
+
+		// Multipart form: code, name, category_id, brand_id, description, specifications, is_active, sort_order,
+		// files "images" (repeatable) and "datasheet"
+		r.Post("/", handlerRepo.Product.CreateProduct)
+		//Query parameter {id}, same form; fields that are not sent are kept, images are appended and
+		// a datasheet is added as a new version
+		r.Put("/", handlerRepo.Product.UpdateProduct)
+		//Query parameter {id}
+		r.Delete("/", handlerRepo.Product.DeleteProduct)
+		//Query parameter {id} of the image
+		r.Delete("/images", handlerRepo.Product.DeleteProductImage)
+
+		// Every kept version of the product's spec sheet, newest first
+		r.Get("/{id}/datasheets", handlerRepo.Product.GetProductDatasheets)
+		// Download a version {id}
+		r.Get("/datasheets/file/{id}", handlerRepo.Product.GetProductDatasheetFile)
+		//Query parameter {id} of the product, multipart file "datasheet" (PDF); becomes the current version
+		r.Post("/datasheets", handlerRepo.Product.UploadProductDatasheet)
+		//Query parameter {id} of the version to make current again
+		r.Put("/datasheets/current", handlerRepo.Product.SetCurrentProductDatasheet)
+		//Query parameter {id} of the version
+		r.Delete("/datasheets", handlerRepo.Product.DeleteProductDatasheet)
+
+		// Multipart form: name, slug, parent_id, description, sort_order, file "image"
+		r.Post("/categories", handlerRepo.Product.CreateProductCategory)
+		//Query parameter {id}, same form
+		r.Put("/categories", handlerRepo.Product.UpdateProductCategory)
+		//Query parameter {id}
+		r.Delete("/categories", handlerRepo.Product.DeleteProductCategory)
+
+		// Multipart form: name, slug, website, description, sort_order, file "logo"
+		r.Post("/brands", handlerRepo.Product.CreateBrand)
+		//Query parameter {id}, same form
+		r.Put("/brands", handlerRepo.Product.UpdateBrand)
+		//Query parameter {id}
+		r.Delete("/brands", handlerRepo.Product.DeleteBrand)
+	})
+
+	return mux
+}
@@ -0,0 +1,35 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// quotationRoutes implements the routes of the priced quotations sent to customers.
+func quotationRoutes() *chi.Mux {
+	mux := chi.NewRouter()
+
+	mux.Group(func(r chi.Router) {
+		r.Use(authJWT, requirePermission(models.PermQuotationRead))
+		//Query parameter pageLength, pageIndex (1-based), status, rfq_id, inquiry_id, search (all optional)
+		r.Get("/", handlerRepo.Quotation.GetAllQuotations)
+		// The quotation with its items
+		r.Get("/{id}", handlerRepo.Quotation.GetQuotation)
+		// The rendered PDF; query parameter download to save it instead of viewing it
+		r.Get("/pdf/{id}", handlerRepo.Quotation.GetQuotationPDF)
+	})
+
+	mux.Group(func(r chi.Router) {
+		r.Use(authJWT, requirePermission(models.PermQuotationWrite))
+		// Creates a draft with the next quotation number
+		r.Post("/", handlerRepo.Quotation.CreateQuotation)
+		//Query parameter {id}, drafts only
+		r.Put("/", handlerRepo.Quotation.UpdateQuotation)
+		//Query parameter {id}, drafts only
+		r.Delete("/", handlerRepo.Quotation.DeleteQuotation)
+		//Query parameter {id}, body { to, message } (both optional): emails the PDF and marks the quotation SENT
+		r.Post("/send", handlerRepo.Quotation.SendQuotation)
+	})
+
+	return mux
+}
@@ -0,0 +1,37 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/api/middlewares"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// rfqRoutes implements the routes of the requests for quotation. Staff access follows the inquiry permissions.
+func rfqRoutes() *chi.Mux {
+	mux := chi.NewRouter()
+
+	// ======== Public RFQ Routes ========
+	// Quotation cart from the product catalogue: rate limited per IP like the contact form and checked with
+	// the same form token (GET /api/v1/inquiry/form-token)
+	mux.With(middlewares.RateLimit(handlerRepo.Inquiry.Config.RateLimit, handlerRepo.Inquiry.Config.RateWindow, handlerRepo.ErrorLog)).
+		Post("/", handlerRepo.Inquiry.CreateRfq)
+
+	mux.Group(func(r chi.Router) {
+		r.Use(authJWT, requirePermission(models.PermInquiryRead))
+		//Query parameter pageLength, pageIndex (1-based), status, search, spam (all optional)
+		r.Get("/", handlerRepo.Inquiry.GetAllRfqs)
+		// The request with its items and status history
+		r.Get("/{id}", handlerRepo.Inquiry.GetRfq)
+	})
+
+	mux.Group(func(r chi.Router) {
+		r.Use(authJWT, requirePermission(models.PermInquiryWrite))
+		//Query parameter {id}, body { status, comment }: NEW -> QUOTED -> ACCEPTED, NEW or QUOTED -> REJECTED,
+		//REJECTED -> NEW
+		r.Patch("/update-status", handlerRepo.Inquiry.UpdateRfqStatus)
+		//Query parameter {id}
+		r.Delete("/", handlerRepo.Inquiry.DeleteRfq)
+	})
+
+	return mux
+}
@@ -11,14 +11,22 @@ import (
 	"github.com/projuktisheba/ajfses/backend/api/handlers"
 	"github.com/projuktisheba/ajfses/backend/api/middlewares"
 	"github.com/projuktisheba/ajfses/backend/internal/dbrepo"
+	"github.com/projuktisheba/ajfses/backend/internal/mailer"
 	"github.com/projuktisheba/ajfses/backend/internal/models"
 	"github.com/projuktisheba/ajfses/backend/internal/utils"
+	"github.com/projuktisheba/ajfses/backend/internal/webhook"
 )
 
 var handlerRepo *handlers.HandlerRepo
-var authAdmin func(http.Handler) http.Handler
+var authJWT func(http.Handler) http.Handler
 
-func Routes(host, env string, db *dbrepo.DBRepository, jwt models.JWTConfig, infoLogger, errorLogger *log.Logger) http.Handler {
+// requirePermission returns the middleware that guards a route with the given permission.
+// It must be used after authJWT.
+func requirePermission(p models.Permission) func(http.Handler) http.Handler {
+	return middlewares.RequirePermission(p, handlerRepo.ErrorLog)
+}
+
+func Routes(cfg models.Config, db *dbrepo.DBRepository, mail *mailer.Mailer, hooks *webhook.Dispatcher, infoLogger, errorLogger *log.Logger) http.Handler {
 	mux := chi.NewRouter()
 
 	// --- Global middlewares ---
@@ -45,16 +53,16 @@ func Routes(host, env string, db *dbrepo.DBRepository, jwt models.JWTConfig, inf
 			ip = conn.LocalAddr().(*net.UDPAddr).IP.String()
 		}
 		resp := map[string]any{
-			"status":    env,
+			"status":    cfg.Env,
 			"server_ip": ip,
 		}
 		utils.WriteJSON(w, http.StatusOK, resp)
 	})
 
 	//get the handler repo
-	handlerRepo = handlers.NewHandlerRepo(host, db, jwt, infoLogger, errorLogger)
+	handlerRepo = handlers.NewHandlerRepo(cfg, db, mail, hooks, infoLogger, errorLogger)
 	// Initialize the AuthJWT middleware factory
-	authAdmin = middlewares.AuthJWT(handlerRepo.JWT, handlerRepo.ErrorLog)
+	authJWT = middlewares.AuthJWT(handlerRepo.JWT, db, handlerRepo.ErrorLog)
 	// Mount Auth routes
 	mux.Mount("/api/v1/auth", authRoutes())
 
@@ -78,5 +86,23 @@ func Routes(host, env string, db *dbrepo.DBRepository, jwt models.JWTConfig, inf
 	// Mount gallery handler routes
 	mux.Mount("/api/v1/gallery", galleryRoutes())
 
+	// Mount user management routes
+	mux.Mount("/api/v1/users", userRoutes())
+
+	// Mount outgoing email log routes
+	mux.Mount("/api/v1/emails", emailRoutes())
+
+	// Mount webhook endpoint and delivery log routes
+	mux.Mount("/api/v1/webhooks", webhookRoutes())
+
+	// Mount product catalogue routes
+	mux.Mount("/api/v1/product", productRoutes())
+
+	// Mount request-for-quotation routes
+	mux.Mount("/api/v1/rfq", rfqRoutes())
+
+	// Mount quotation routes
+	mux.Mount("/api/v1/quotation", quotationRoutes())
+
 	return mux
 }
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
 )
 
 func teamRoutes() *chi.Mux {
@@ -11,7 +12,7 @@ func teamRoutes() *chi.Mux {
 
 	// The handler we created specifically for multipart/form-data
 	mux.Group(func(r chi.Router) {
-		r.Use(authAdmin)
+		r.Use(authJWT, requirePermission(models.PermTeamWrite))
 		r.Post("/", handlerRepo.Team.CreateTeam)
 	})
 	mux.Get("/list", handlerRepo.Team.GetAllTeams)
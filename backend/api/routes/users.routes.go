@@ -0,0 +1,36 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// userRoutes implements the admin user management routes.
+func userRoutes() *chi.Mux {
+	mux := chi.NewRouter()
+
+	// ======== User Routes (user:manage) ========
+	mux.Group(func(r chi.Router) {
+		r.Use(authJWT, requirePermission(models.PermUserManage))
+
+		// Query parameters page, limit, role, status, search, sortBy, sortOrder (all optional)
+		r.Get("/", handlerRepo.User.GetAllUsers)
+		r.Get("/profile/{id}", handlerRepo.User.GetUser)
+		r.Get("/roles", handlerRepo.User.GetRoles)
+
+		r.Post("/", handlerRepo.User.CreateUser)
+
+		// Query parameter {id}
+		r.Put("/", handlerRepo.User.UpdateUser)
+		// Query parameter {id}
+		r.Patch("/status", handlerRepo.User.UpdateUserStatus)
+		// Query parameter {id}, multipart form with "avatar" file
+		r.Post("/avatar", handlerRepo.User.UpdateUserAvatar)
+		// Query parameter {id}: lift a lock caused by failed sign-ins
+		r.Post("/unlock", handlerRepo.User.UnlockUser)
+		// Query parameter {id}: switch two-factor off (lost authenticator)
+		r.Delete("/2fa", handlerRepo.User.ResetTwoFactor)
+	})
+
+	return mux
+}
@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"github.com/go-chi/chi/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// webhookRoutes implements the routes for webhook endpoints and the delivery log.
+func webhookRoutes() *chi.Mux {
+	mux := chi.NewRouter()
+
+	// ======== Webhook Routes (webhook:manage) ========
+	mux.Group(func(r chi.Router) {
+		r.Use(authJWT, requirePermission(models.PermWebhookManage))
+
+		r.Get("/", handlerRepo.Webhook.GetWebhooks)
+		r.Get("/events", handlerRepo.Webhook.GetWebhookEvents)
+		// Query parameters page, limit, endpoint, status (PENDING|SUCCEEDED|FAILED), event (all optional)
+		r.Get("/deliveries", handlerRepo.Webhook.GetWebhookDeliveries)
+		r.Get("/deliveries/{id}", handlerRepo.Webhook.GetWebhookDelivery)
+		r.Get("/{id}", handlerRepo.Webhook.GetWebhook)
+
+		// Body { url, description, events, active }
+		r.Post("/", handlerRepo.Webhook.CreateWebhook)
+		//Query parameter {id}, body { url, description, events, active }
+		r.Put("/", handlerRepo.Webhook.UpdateWebhook)
+		//Query parameter {id}
+		r.Delete("/", handlerRepo.Webhook.DeleteWebhook)
+		//Query parameter {id}
+		r.Post("/rotate-secret", handlerRepo.Webhook.RotateWebhookSecret)
+		//Query parameter {id}
+		r.Post("/ping", handlerRepo.Webhook.PingWebhook)
+		//Query parameter {id} of the delivery
+		r.Post("/deliveries/replay", handlerRepo.Webhook.ReplayWebhookDelivery)
+	})
+
+	return mux
+}
@@ -3,10 +3,12 @@ package config
 import (
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
 	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
 )
 
 func Load() (models.Config, error) {
@@ -36,6 +38,13 @@ func Load() (models.Config, error) {
 
 	cfg.Env = os.Getenv("ENV")
 
+	// Reverse proxies whose X-Forwarded-For is believed; without any the peer address is the client
+	proxies, err := utils.ParseTrustedProxies(os.Getenv("TRUSTED_PROXIES"))
+	if err != nil {
+		return cfg, err
+	}
+	cfg.TrustedProxies = proxies
+
 	// JWT settings
 	cfg.JWT.SecretKey = os.Getenv("JWT_SECRET_KEY")
 	cfg.JWT.Issuer = os.Getenv("JWT_ISSUER")
@@ -58,6 +67,227 @@ func Load() (models.Config, error) {
 		cfg.JWT.Refresh = dur
 	}
 
+	// Sign-in brute-force protection
+	cfg.Login = models.LoginConfig{
+		MaxAttempts:     5,
+		IPMaxAttempts:   20,
+		Window:          15 * time.Minute,
+		LockDuration:    15 * time.Minute,
+		MaxLockDuration: 24 * time.Hour,
+		ResetTokenTTL:   30 * time.Minute,
+		ResetRateLimit:  5,
+		ResetRateWindow: time.Hour,
+	}
+	if v := os.Getenv("LOGIN_MAX_ATTEMPTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Login.MaxAttempts = n
+	}
+	if v := os.Getenv("LOGIN_IP_MAX_ATTEMPTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Login.IPMaxAttempts = n
+	}
+	if v := os.Getenv("LOGIN_ATTEMPT_WINDOW"); v != "" {
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Login.Window = dur
+	}
+	if v := os.Getenv("LOGIN_LOCK_DURATION"); v != "" {
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Login.LockDuration = dur
+	}
+	if v := os.Getenv("LOGIN_LOCK_MAX_DURATION"); v != "" {
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Login.MaxLockDuration = dur
+	}
+
+	if v := os.Getenv("PASSWORD_RESET_TTL"); v != "" {
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Login.ResetTokenTTL = dur
+	}
+	if v := os.Getenv("PASSWORD_RESET_RATE_LIMIT"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Login.ResetRateLimit = n
+	}
+	if v := os.Getenv("PASSWORD_RESET_RATE_WINDOW"); v != "" {
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Login.ResetRateWindow = dur
+	}
+	cfg.PasswordResetURL = os.Getenv("PASSWORD_RESET_URL")
+
+	// Mail settings
+	cfg.Mail.Transport = os.Getenv("MAIL_TRANSPORT")
+	cfg.Mail.Host = os.Getenv("SMTP_HOST")
+	if v := os.Getenv("SMTP_PORT"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Mail.Port = n
+	}
+	cfg.Mail.Username = os.Getenv("SMTP_USERNAME")
+	cfg.Mail.Password = os.Getenv("SMTP_PASSWORD")
+	cfg.Mail.From = os.Getenv("MAIL_FROM")
+	if cfg.Mail.From == "" {
+		cfg.Mail.From = Email
+	}
+	cfg.Mail.FromName = os.Getenv("MAIL_FROM_NAME")
+	cfg.Mail.OutboxDir = os.Getenv("MAIL_OUTBOX_DIR")
+	cfg.Mail.MaxAttempts = 3
+	if v := os.Getenv("MAIL_MAX_ATTEMPTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Mail.MaxAttempts = n
+	}
+
+	// Company details shown in emails and documents
+	cfg.Company.Name = os.Getenv("COMPANY_NAME")
+	if cfg.Company.Name == "" {
+		cfg.Company.Name = "AJFSES Engineering"
+	}
+	cfg.Company.Email = os.Getenv("COMPANY_EMAIL")
+	if cfg.Company.Email == "" {
+		cfg.Company.Email = Email
+	}
+	cfg.Company.Phone = os.Getenv("COMPANY_PHONE")
+	cfg.Company.Address = os.Getenv("COMPANY_ADDRESS")
+	cfg.Company.Website = os.Getenv("COMPANY_WEBSITE")
+
+	// Inquiry settings
+	for _, addr := range strings.Split(os.Getenv("INQUIRY_NOTIFY_RECIPIENTS"), ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			cfg.Inquiry.NotifyRecipients = append(cfg.Inquiry.NotifyRecipients, addr)
+		}
+	}
+	cfg.Inquiry.AdminURL = os.Getenv("ADMIN_PANEL_URL")
+	cfg.Inquiry.ReplyTo = os.Getenv("INQUIRY_REPLY_TO")
+	if cfg.Inquiry.ReplyTo == "" {
+		cfg.Inquiry.ReplyTo = cfg.Company.Email
+	}
+
+	// Inquiry form spam protection
+	cfg.Inquiry.RateLimit = 5
+	cfg.Inquiry.RateWindow = time.Hour
+	cfg.Inquiry.FormTokenMaxAge = 2 * time.Hour
+	cfg.Inquiry.MinSubmitTime = 3 * time.Second
+	cfg.Inquiry.SpamThreshold = 5
+	if v := os.Getenv("INQUIRY_RATE_LIMIT"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Inquiry.RateLimit = n
+	}
+	if v := os.Getenv("INQUIRY_RATE_WINDOW"); v != "" {
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Inquiry.RateWindow = dur
+	}
+	if v := os.Getenv("INQUIRY_FORM_TOKEN_MAX_AGE"); v != "" {
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Inquiry.FormTokenMaxAge = dur
+	}
+	if v := os.Getenv("INQUIRY_MIN_SUBMIT_TIME"); v != "" {
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Inquiry.MinSubmitTime = dur
+	}
+	if v := os.Getenv("INQUIRY_SPAM_THRESHOLD"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Inquiry.SpamThreshold = n
+	}
+	cfg.Inquiry.FormTokenSecret = os.Getenv("INQUIRY_FORM_TOKEN_SECRET")
+	if cfg.Inquiry.FormTokenSecret == "" {
+		cfg.Inquiry.FormTokenSecret = cfg.JWT.SecretKey
+	}
+
+	// Outgoing webhooks
+	cfg.Webhook.MaxAttempts = 6
+	cfg.Webhook.Timeout = 10 * time.Second
+	cfg.Webhook.RetryDelay = 30 * time.Second
+	if v := os.Getenv("WEBHOOK_MAX_ATTEMPTS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Webhook.MaxAttempts = n
+	}
+	if v := os.Getenv("WEBHOOK_TIMEOUT"); v != "" {
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Webhook.Timeout = dur
+	}
+	if v := os.Getenv("WEBHOOK_RETRY_DELAY"); v != "" {
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Webhook.RetryDelay = dur
+	}
+
+	// Quotation defaults
+	cfg.Quotation.Prefix = os.Getenv("QUOTATION_PREFIX")
+	if cfg.Quotation.Prefix == "" {
+		cfg.Quotation.Prefix = "QT"
+	}
+	cfg.Quotation.Currency = os.Getenv("QUOTATION_CURRENCY")
+	if cfg.Quotation.Currency == "" {
+		cfg.Quotation.Currency = "BDT"
+	}
+	cfg.Quotation.VatRate = 15
+	if v := os.Getenv("QUOTATION_VAT_RATE"); v != "" {
+		rate, err := strconv.ParseFloat(v, 64)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Quotation.VatRate = rate
+	}
+	cfg.Quotation.ValidityDays = 30
+	if v := os.Getenv("QUOTATION_VALIDITY_DAYS"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			return cfg, err
+		}
+		cfg.Quotation.ValidityDays = n
+	}
+	cfg.Quotation.Terms = os.Getenv("QUOTATION_TERMS")
+
 	// DB settings
 	cfg.DB.DSN = os.Getenv("DB_DSN")
 	cfg.DB.DEVDSN = os.Getenv("DB_DSN_DEV")
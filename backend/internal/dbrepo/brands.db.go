@@ -0,0 +1,166 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// ErrBrandSlugTaken is returned when another brand already uses the slug.
+var ErrBrandSlugTaken = errors.New("another brand already uses this slug")
+
+// BrandRepository holds the database pool connection for brand operations.
+type BrandRepository struct {
+	DB *pgxpool.Pool
+}
+
+// newBrandRepository creates a new instance of the repository.
+func newBrandRepository(db *pgxpool.Pool) *BrandRepository {
+	return &BrandRepository{DB: db}
+}
+
+// brandColumns are the columns read by scanBrand, in order.
+const brandColumns = `
+	b.id, b.slug, b.name, b.logo_link, b.website, b.description, b.sort_order,
+	(SELECT COUNT(*) FROM products p WHERE p.brand_id = b.id AND p.is_active),
+	b.created_at, b.updated_at`
+
+func scanBrand(row pgx.Row, b *models.Brand) error {
+	return row.Scan(
+		&b.ID,
+		&b.Slug,
+		&b.Name,
+		&b.LogoLink,
+		&b.Website,
+		&b.Description,
+		&b.SortOrder,
+		&b.ProductCount,
+		&b.CreatedAt,
+		&b.UpdatedAt,
+	)
+}
+
+// brandWriteError maps a unique violation on the slug to ErrBrandSlugTaken.
+func brandWriteError(action string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23505" { // unique_violation
+		return ErrBrandSlugTaken
+	}
+	return fmt.Errorf("failed to %s brand: %w", action, err)
+}
+
+// Create inserts the brand and sets its ID and timestamps.
+func (r *BrandRepository) Create(ctx context.Context, b *models.Brand) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `
+		INSERT INTO brands (slug, name, logo_link, website, description, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.DB.QueryRow(ctx, stmt, b.Slug, b.Name, b.LogoLink, b.Website, b.Description, b.SortOrder).
+		Scan(&b.ID, &b.CreatedAt, &b.UpdatedAt)
+	if err != nil {
+		return brandWriteError("insert", err)
+	}
+
+	return nil
+}
+
+// Update modifies every field of the brand, including its logo link.
+func (r *BrandRepository) Update(ctx context.Context, b *models.Brand) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `
+		UPDATE brands
+		SET slug = $1, name = $2, logo_link = $3, website = $4, description = $5, sort_order = $6,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7
+		RETURNING updated_at
+	`
+
+	err := r.DB.QueryRow(ctx, stmt, b.Slug, b.Name, b.LogoLink, b.Website, b.Description, b.SortOrder, b.ID).
+		Scan(&b.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("no brand found")
+		}
+		return brandWriteError("update", err)
+	}
+
+	return nil
+}
+
+// Delete removes a brand. Its products are kept without a brand.
+func (r *BrandRepository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	cmdTag, err := r.DB.Exec(ctx, `DELETE FROM brands WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete brand: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.New("no brand found")
+	}
+
+	return nil
+}
+
+// GetByID returns a single brand.
+func (r *BrandRepository) GetByID(ctx context.Context, id int64) (*models.Brand, error) {
+	return r.getOne(ctx, `b.id = $1`, id)
+}
+
+// GetBySlug returns a single brand by its slug.
+func (r *BrandRepository) GetBySlug(ctx context.Context, slug string) (*models.Brand, error) {
+	return r.getOne(ctx, `b.slug = $1`, slug)
+}
+
+func (r *BrandRepository) getOne(ctx context.Context, cond string, arg any) (*models.Brand, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var b models.Brand
+	err := scanBrand(r.DB.QueryRow(ctx, `SELECT `+brandColumns+` FROM brands b WHERE `+cond, arg), &b)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("no brand found")
+		}
+		return nil, fmt.Errorf("failed to get brand: %w", err)
+	}
+
+	return &b, nil
+}
+
+// GetAll returns every brand in display order.
+func (r *BrandRepository) GetAll(ctx context.Context) ([]*models.Brand, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.Query(ctx, `SELECT `+brandColumns+` FROM brands b ORDER BY b.sort_order, b.name, b.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query brands: %w", err)
+	}
+	defer rows.Close()
+
+	brands := []*models.Brand{}
+	for rows.Next() {
+		var b models.Brand
+		if err := scanBrand(rows, &b); err != nil {
+			return nil, fmt.Errorf("failed to scan brand row: %w", err)
+		}
+		brands = append(brands, &b)
+	}
+
+	return brands, rows.Err()
+}
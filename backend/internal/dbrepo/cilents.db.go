@@ -132,12 +132,16 @@ func (c *ClientRepository) GetByID(ctx context.Context, id int64) (*models.Clien
 	defer cancel()
 
 	stmt := `
-		SELECT id, name, area, service_name, service_date, status, note, image_link, created_at, updated_at
-		FROM clients
-		WHERE id = $1
+		SELECT c.id, c.name, c.area, c.service_name, c.service_date, c.status, c.note, c.image_link, c.inquiry_id,
+		       c.created_at, c.updated_at, COALESCE(i.subject, ''), i.inquiry_date
+		FROM clients c
+		LEFT JOIN inquiries i ON i.id = c.inquiry_id
+		WHERE c.id = $1
 	`
 
 	var client models.Client
+	var subject string
+	var inquiryDate *time.Time
 	err := c.DB.QueryRow(ctx, stmt, id).Scan(
 		&client.ID,
 		&client.Name,
@@ -147,8 +151,11 @@ func (c *ClientRepository) GetByID(ctx context.Context, id int64) (*models.Clien
 		&client.Status,
 		&client.Note,
 		&client.ImageLink,
+		&client.InquiryID,
 		&client.CreatedAt,
 		&client.UpdatedAt,
+		&subject,
+		&inquiryDate,
 	)
 
 	if err != nil {
@@ -158,6 +165,16 @@ func (c *ClientRepository) GetByID(ctx context.Context, id int64) (*models.Clien
 		return nil, fmt.Errorf("failed to get client: %w", err)
 	}
 
+	if client.InquiryID != nil && inquiryDate != nil {
+		source := models.Inquiry{ID: *client.InquiryID, InquiryDate: *inquiryDate}
+		client.SourceInquiry = &models.ClientSourceInquiry{
+			ID:          source.ID,
+			ReferenceNo: source.ReferenceNo(),
+			Subject:     subject,
+			InquiryDate: *inquiryDate,
+		}
+	}
+
 	return &client, nil
 }
 
@@ -177,7 +194,7 @@ func (c *ClientRepository) GetAll(ctx context.Context, status string) ([]*models
 	}
 
 	stmt := fmt.Sprintf(`
-		SELECT id, name, area, service_name, service_date, status, note, image_link, created_at, updated_at
+		SELECT id, name, area, service_name, service_date, status, note, image_link, inquiry_id, created_at, updated_at
 		FROM clients
 		%s 
 		ORDER BY created_at DESC;
@@ -201,6 +218,7 @@ func (c *ClientRepository) GetAll(ctx context.Context, status string) ([]*models
 			&client.Status,
 			&client.Note,
 			&client.ImageLink,
+			&client.InquiryID,
 			&client.CreatedAt,
 			&client.UpdatedAt,
 		)
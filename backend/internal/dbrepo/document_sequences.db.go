@@ -0,0 +1,24 @@
+package dbrepo
+
+import (
+	"context"
+	"fmt"
+)
+
+// nextSequenceValue takes the next number of the document sequence name for period (e.g. "quotation", "2026").
+// It must run inside the transaction that stores the document: the upsert locks the counter row until the
+// transaction ends, so concurrent documents get consecutive numbers and a rolled back one gives its number back.
+func nextSequenceValue(ctx context.Context, tx dbtx, name, period string) (int64, error) {
+	var value int64
+	err := tx.QueryRow(ctx, `
+		INSERT INTO document_sequences (name, period, last_value)
+		VALUES ($1, $2, 1)
+		ON CONFLICT (name, period) DO UPDATE
+		SET last_value = document_sequences.last_value + 1, updated_at = CURRENT_TIMESTAMP
+		RETURNING last_value
+	`, name, period).Scan(&value)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get next %s number: %w", name, err)
+	}
+	return value, nil
+}
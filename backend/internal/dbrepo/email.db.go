@@ -0,0 +1,89 @@
+package dbrepo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// EmailMessageRepository stores the log of outgoing emails. It implements mailer.Recorder.
+type EmailMessageRepository struct {
+	DB *pgxpool.Pool
+}
+
+// newEmailMessageRepository creates a new instance of the repository.
+func newEmailMessageRepository(db *pgxpool.Pool) *EmailMessageRepository {
+	return &EmailMessageRepository{DB: db}
+}
+
+// Record stores the outcome of one delivery.
+func (r *EmailMessageRepository) Record(ctx context.Context, e *models.EmailMessage) error {
+	sql := `
+		INSERT INTO email_messages (template, recipients, subject, transport, status, attempts, error, sent_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at
+	`
+
+	err := r.DB.QueryRow(ctx, sql, e.Template, e.Recipients, e.Subject, e.Transport, e.Status, e.Attempts, e.Error, e.SentAt).
+		Scan(&e.ID, &e.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record email: %w", err)
+	}
+
+	return nil
+}
+
+// GetAll returns a page of the email log, newest first, and the total count.
+// status and template are optional filters; search matches recipients and subject.
+func (r *EmailMessageRepository) GetAll(ctx context.Context, page, limit int, status, template, search string) ([]models.EmailMessage, int, error) {
+	where := ` WHERE 1=1`
+	args := []any{}
+	argIdx := 1
+
+	if status != "" {
+		where += fmt.Sprintf(" AND status = $%d", argIdx)
+		args = append(args, status)
+		argIdx++
+	}
+	if template != "" {
+		where += fmt.Sprintf(" AND template = $%d", argIdx)
+		args = append(args, template)
+		argIdx++
+	}
+	if search != "" {
+		where += fmt.Sprintf(" AND (subject ILIKE $%d OR array_to_string(recipients, ',') ILIKE $%d)", argIdx, argIdx)
+		args = append(args, "%"+search+"%")
+		argIdx++
+	}
+
+	var total int
+	if err := r.DB.QueryRow(ctx, `SELECT COUNT(*) FROM email_messages`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count emails: %w", err)
+	}
+
+	sql := `
+		SELECT id, template, recipients, subject, transport, status, attempts, error, sent_at, created_at
+		FROM email_messages` + where +
+		fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	args = append(args, limit, (page-1)*limit)
+
+	rows, err := r.DB.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query emails: %w", err)
+	}
+	defer rows.Close()
+
+	emails := []models.EmailMessage{}
+	for rows.Next() {
+		var e models.EmailMessage
+		if err := rows.Scan(&e.ID, &e.Template, &e.Recipients, &e.Subject, &e.Transport, &e.Status,
+			&e.Attempts, &e.Error, &e.SentAt, &e.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan email row: %w", err)
+		}
+		emails = append(emails, e)
+	}
+
+	return emails, total, rows.Err()
+}
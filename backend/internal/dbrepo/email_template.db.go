@@ -0,0 +1,68 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// EmailTemplateRepository stores admin-edited email templates. It implements mailer.TemplateStore.
+type EmailTemplateRepository struct {
+	DB *pgxpool.Pool
+}
+
+// newEmailTemplateRepository creates a new instance of the repository.
+func newEmailTemplateRepository(db *pgxpool.Pool) *EmailTemplateRepository {
+	return &EmailTemplateRepository{DB: db}
+}
+
+// GetTemplate returns the edited template, or nil if the template was not edited.
+func (r *EmailTemplateRepository) GetTemplate(ctx context.Context, name string) (*models.EmailTemplate, error) {
+	sql := `
+		SELECT name, subject, text_body, html_body, updated_by, updated_at
+		FROM email_templates
+		WHERE name = $1
+	`
+
+	t := models.EmailTemplate{Customized: true}
+	err := r.DB.QueryRow(ctx, sql, name).Scan(&t.Name, &t.Subject, &t.Text, &t.HTML, &t.UpdatedBy, &t.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to get email template: %w", err)
+	}
+
+	return &t, nil
+}
+
+// Save creates or replaces the edited version of a template.
+func (r *EmailTemplateRepository) Save(ctx context.Context, t *models.EmailTemplate) error {
+	sql := `
+		INSERT INTO email_templates (name, subject, text_body, html_body, updated_by, updated_at)
+		VALUES ($1, $2, $3, $4, $5, CURRENT_TIMESTAMP)
+		ON CONFLICT (name) DO UPDATE
+		SET subject = EXCLUDED.subject, text_body = EXCLUDED.text_body, html_body = EXCLUDED.html_body,
+		    updated_by = EXCLUDED.updated_by, updated_at = CURRENT_TIMESTAMP
+		RETURNING updated_at
+	`
+
+	if err := r.DB.QueryRow(ctx, sql, t.Name, t.Subject, t.Text, t.HTML, t.UpdatedBy).Scan(&t.UpdatedAt); err != nil {
+		return fmt.Errorf("failed to save email template: %w", err)
+	}
+	t.Customized = true
+
+	return nil
+}
+
+// Delete removes the edited version, restoring the built-in default. It is not an error if there was none.
+func (r *EmailTemplateRepository) Delete(ctx context.Context, name string) error {
+	if _, err := r.DB.Exec(ctx, `DELETE FROM email_templates WHERE name = $1`, name); err != nil {
+		return fmt.Errorf("failed to delete email template: %w", err)
+	}
+	return nil
+}
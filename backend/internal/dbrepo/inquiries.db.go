@@ -2,6 +2,7 @@ package dbrepo
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/jackc/pgx/v5"
@@ -19,35 +20,59 @@ func newInquiryRepository(db *pgxpool.Pool) *InquiryRepository {
 	return &InquiryRepository{DB: db}
 }
 
-// Create inserts a new inquiry into the database.
+// Create inserts a new inquiry with its status and starts the status history.
 // Note: created_at, updated_at, and inquiry_date are handled by DB defaults unless specified otherwise.
 func (r *InquiryRepository) Create(ctx context.Context, i *models.Inquiry) (int64, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
 	sql := `
-		INSERT INTO inquiries (name, mobile, email, subject, message)
-		VALUES ($1, $2, $3, $4, $5)
+		INSERT INTO inquiries (name, mobile, email, subject, message, status, spam_score, spam_reasons, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 		RETURNING id, inquiry_date, created_at, updated_at
 	`
 
-	err := r.DB.QueryRow(ctx, sql, i.Name, i.Mobile, i.Email, i.Subject, i.Message).
+	err = tx.QueryRow(ctx, sql, i.Name, i.Mobile, i.Email, i.Subject, i.Message, i.Status,
+		i.SpamScore, i.SpamReasons, i.IPAddress).
 		Scan(&i.ID, &i.InquiryDate, &i.CreatedAt, &i.UpdatedAt)
 
 	if err != nil {
 		return 0, fmt.Errorf("failed to create inquiry: %w", err)
 	}
 
+	change := &models.InquiryStatusChange{InquiryID: i.ID, To: i.Status}
+	if i.Status == models.InquiryStatusSpam {
+		change.Comment = "Flagged as spam: " + i.SpamReasons
+	}
+	if err := insertStatusChange(ctx, tx, change); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
 	return i.ID, nil
 }
 
-// GetByID retrieves a single inquiry by its ID.
-func (r *InquiryRepository) GetByID(ctx context.Context, id int64) (*models.Inquiry, error) {
-	sql := `
-		SELECT id, inquiry_date, name, mobile, email, subject, message, status, created_at, updated_at
-		FROM inquiries
-		WHERE id = $1
-	`
+// inquiryColumns and inquiryFrom select the inquiry columns and the assignee's name. They are shared by
+// the read queries and must be scanned with scanInquiry (or inquiryScanTargets).
+const (
+	inquiryColumns = `
+	SELECT i.id, i.inquiry_date, i.name, i.mobile, i.email, i.subject, i.message, i.status,
+	       i.assigned_to, COALESCE(u.name, ''), i.assigned_at, i.spam_score, i.spam_reasons, i.ip_address,
+	       i.client_id, i.created_at, i.updated_at`
+	inquiryFrom = `
+	FROM inquiries i
+	LEFT JOIN users u ON u.id = i.assigned_to`
+	inquirySelect = inquiryColumns + inquiryFrom
+)
 
-	var i models.Inquiry
-	err := r.DB.QueryRow(ctx, sql, id).Scan(
+// inquiryScanTargets returns the scan destinations of the inquiryColumns.
+func inquiryScanTargets(i *models.Inquiry) []any {
+	return []any{
 		&i.ID,
 		&i.InquiryDate,
 		&i.Name,
@@ -56,9 +81,29 @@ func (r *InquiryRepository) GetByID(ctx context.Context, id int64) (*models.Inqu
 		&i.Subject,
 		&i.Message,
 		&i.Status,
+		&i.AssignedTo,
+		&i.AssigneeName,
+		&i.AssignedAt,
+		&i.SpamScore,
+		&i.SpamReasons,
+		&i.IPAddress,
+		&i.ClientID,
 		&i.CreatedAt,
 		&i.UpdatedAt,
-	)
+	}
+}
+
+// scanInquiry scans a row selected with inquirySelect.
+func scanInquiry(row pgx.Row, i *models.Inquiry) error {
+	return row.Scan(inquiryScanTargets(i)...)
+}
+
+// GetByID retrieves a single inquiry by its ID.
+func (r *InquiryRepository) GetByID(ctx context.Context, id int64) (*models.Inquiry, error) {
+	sql := inquirySelect + ` WHERE i.id = $1`
+
+	var i models.Inquiry
+	err := scanInquiry(r.DB.QueryRow(ctx, sql, id), &i)
 
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -70,56 +115,84 @@ func (r *InquiryRepository) GetByID(ctx context.Context, id int64) (*models.Inqu
 	return &i, nil
 }
 
-// GetAll retrieves all inquiries, ordered by inquiry_date descending.
-func (r *InquiryRepository) GetAll(ctx context.Context) ([]models.Inquiry, error) {
-	sql := `
-		SELECT id, inquiry_date, name, mobile, email, subject, message, status, created_at, updated_at
-		FROM inquiries
-		ORDER BY inquiry_date DESC
-	`
+// inquiryFilterClause builds the WHERE clause and arguments for the filter (paging is not included).
+// Columns are qualified with the alias i used by inquirySelect.
+func inquiryFilterClause(f models.InquiryFilter) (string, []any) {
+	where := ` WHERE 1=1`
+	args := []any{}
+
+	if f.Status != "" {
+		args = append(args, f.Status)
+		where += fmt.Sprintf(" AND i.status = $%d", len(args))
+	}
+	if f.From != nil {
+		args = append(args, *f.From)
+		where += fmt.Sprintf(" AND i.inquiry_date >= $%d", len(args))
+	}
+	if f.To != nil {
+		args = append(args, *f.To)
+		where += fmt.Sprintf(" AND i.inquiry_date < $%d", len(args))
+	}
+	if f.AssignedTo != nil {
+		args = append(args, *f.AssignedTo)
+		where += fmt.Sprintf(" AND i.assigned_to = $%d", len(args))
+	}
+	if f.Unassigned {
+		where += " AND i.assigned_to IS NULL"
+	}
+	if f.Search != "" {
+		args = append(args, "%"+f.Search+"%")
+		n := len(args)
+		where += fmt.Sprintf(` AND (i.name ILIKE $%d OR i.email ILIKE $%d OR i.mobile ILIKE $%d
+			OR i.subject ILIKE $%d OR i.message ILIKE $%d)`, n, n, n, n, n)
+	}
+
+	return where, args
+}
+
+// GetAll retrieves one page of inquiries matching the filter, newest first, and the total number of matches.
+func (r *InquiryRepository) GetAll(ctx context.Context, f models.InquiryFilter) ([]models.Inquiry, int, error) {
+	where, args := inquiryFilterClause(f)
+
+	var total int
+	if err := r.DB.QueryRow(ctx, `SELECT COUNT(*) FROM inquiries i`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count inquiries: %w", err)
+	}
+
+	sql := inquirySelect + where + fmt.Sprintf(`
+		ORDER BY i.inquiry_date DESC, i.id DESC
+		LIMIT $%d OFFSET $%d`, len(args)+1, len(args)+2)
+	args = append(args, f.PageLength, (f.PageIndex-1)*f.PageLength)
 
-	rows, err := r.DB.Query(ctx, sql)
+	rows, err := r.DB.Query(ctx, sql, args...)
 	if err != nil {
-		return nil, fmt.Errorf("failed to query inquiries: %w", err)
+		return nil, 0, fmt.Errorf("failed to query inquiries: %w", err)
 	}
 	defer rows.Close()
 
-	var inquiries []models.Inquiry
+	inquiries := []models.Inquiry{}
 
 	for rows.Next() {
 		var i models.Inquiry
-		err := rows.Scan(
-			&i.ID,
-			&i.InquiryDate,
-			&i.Name,
-			&i.Mobile,
-			&i.Email,
-			&i.Subject,
-			&i.Message,
-			&i.Status,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		)
-		if err != nil {
-			return nil, fmt.Errorf("failed to scan inquiry row: %w", err)
+		if err := scanInquiry(rows, &i); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan inquiry row: %w", err)
 		}
 		inquiries = append(inquiries, i)
 	}
 
 	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error iterating inquiry rows: %w", err)
+		return nil, 0, fmt.Errorf("error iterating inquiry rows: %w", err)
 	}
 
-	return inquiries, nil
+	return inquiries, total, nil
 }
 
-// GetStatusCounts retrieves the count of inquiries for specific statuses.
+// GetStatusCounts retrieves the number of inquiries in each status (over all inquiries, not a filtered page).
 func (r *InquiryRepository) GetStatusCounts(ctx context.Context) (map[string]int, error) {
     // 1. Define SQL to count grouped by status
     sql := `
         SELECT status, COUNT(*)
         FROM inquiries
-        WHERE status IN ('NEW', 'RESOLVED')
         GROUP BY status
     `
 
@@ -149,9 +222,40 @@ func (r *InquiryRepository) GetStatusCounts(ctx context.Context) (map[string]int
     return counts, nil
 }
 
+// ErrStatusConflict is returned by Update when the status was changed by someone else in the meantime.
+var ErrStatusConflict = errors.New("the inquiry status was changed by someone else, reload and try again")
+
 // Update modifies an existing inquiry.
-// It automatically updates the 'updated_at' timestamp.
-func (r *InquiryRepository) Update(ctx context.Context, i *models.Inquiry) error {
+// It automatically updates the 'updated_at' timestamp. When change is not nil the status moves from
+// change.From to change.To and the change is added to the status history; otherwise the status is kept.
+func (r *InquiryRepository) Update(ctx context.Context, i *models.Inquiry, change *models.InquiryStatusChange) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var current string
+	err = tx.QueryRow(ctx, `SELECT status FROM inquiries WHERE id = $1 FOR UPDATE`, i.ID).Scan(&current)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return fmt.Errorf("inquiry not found to update with id: %d", i.ID)
+		}
+		return fmt.Errorf("failed to load inquiry: %w", err)
+	}
+
+	i.Status = current
+	if change != nil {
+		if change.From != current {
+			return ErrStatusConflict
+		}
+		change.InquiryID = i.ID
+		if err := insertStatusChange(ctx, tx, change); err != nil {
+			return err
+		}
+		i.Status = change.To
+	}
+
 	sql := `
 		UPDATE inquiries
 		SET name = $1, mobile = $2, email=$3, subject = $4, message = $5, status = $6, updated_at = CURRENT_TIMESTAMP
@@ -159,19 +263,63 @@ func (r *InquiryRepository) Update(ctx context.Context, i *models.Inquiry) error
 		RETURNING updated_at
 	`
 
-	err := r.DB.QueryRow(ctx, sql, i.Name, i.Mobile, i.Email, i.Subject, i.Message, i.Status, i.ID).
+	err = tx.QueryRow(ctx, sql, i.Name, i.Mobile, i.Email, i.Subject, i.Message, i.Status, i.ID).
 		Scan(&i.UpdatedAt)
 
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			return fmt.Errorf("inquiry not found to update with id: %d", i.ID)
-		}
 		return fmt.Errorf("failed to update inquiry: %w", err)
 	}
 
+	return tx.Commit(ctx)
+}
+
+// insertStatusChange adds an entry to the status history inside tx.
+func insertStatusChange(ctx context.Context, tx dbtx, c *models.InquiryStatusChange) error {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO inquiry_status_history (inquiry_id, from_status, to_status, changed_by, comment)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, c.InquiryID, c.From, c.To, c.ChangedBy, c.Comment).Scan(&c.ID, &c.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record status change: %w", err)
+	}
 	return nil
 }
 
+// GetStatusHistory returns the status changes of an inquiry in chronological order.
+func (r *InquiryRepository) GetStatusHistory(ctx context.Context, inquiryID int64) ([]models.InquiryStatusChange, error) {
+	sql := `
+		SELECT h.id, h.inquiry_id, h.from_status, h.to_status, h.changed_by, COALESCE(u.name, ''),
+		       h.comment, h.created_at
+		FROM inquiry_status_history h
+		LEFT JOIN users u ON u.id = h.changed_by
+		WHERE h.inquiry_id = $1
+		ORDER BY h.created_at, h.id
+	`
+
+	rows, err := r.DB.Query(ctx, sql, inquiryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query status history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []models.InquiryStatusChange{}
+	for rows.Next() {
+		var c models.InquiryStatusChange
+		if err := rows.Scan(&c.ID, &c.InquiryID, &c.From, &c.To, &c.ChangedBy, &c.ChangedByName,
+			&c.Comment, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan status history row: %w", err)
+		}
+		history = append(history, c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating status history rows: %w", err)
+	}
+
+	return history, nil
+}
+
 // Delete removes an inquiry from the database.
 func (r *InquiryRepository) Delete(ctx context.Context, id int64) error {
 	sql := `DELETE FROM inquiries WHERE id = $1`
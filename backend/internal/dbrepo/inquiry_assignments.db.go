@@ -0,0 +1,100 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// ErrAssignmentUnchanged is returned by Assign when the inquiry already has the requested assignee.
+var ErrAssignmentUnchanged = errors.New("inquiry is already assigned to this user")
+
+// Assign sets (or with assignee nil, clears) the inquiry's assignee and records the change in its history.
+func (r *InquiryRepository) Assign(ctx context.Context, inquiryID int64, assignee *int64, assignedBy int64, note string) (*models.InquiryAssignment, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var previous *int64
+	err = tx.QueryRow(ctx, `SELECT assigned_to FROM inquiries WHERE id = $1 FOR UPDATE`, inquiryID).Scan(&previous)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("inquiry not found with id: %d", inquiryID)
+		}
+		return nil, fmt.Errorf("failed to load inquiry: %w", err)
+	}
+	if (previous == nil && assignee == nil) || (previous != nil && assignee != nil && *previous == *assignee) {
+		return nil, ErrAssignmentUnchanged
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE inquiries
+		SET assigned_to = $1,
+		    assigned_at = CASE WHEN $1::BIGINT IS NULL THEN NULL ELSE CURRENT_TIMESTAMP END,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, assignee, inquiryID); err != nil {
+		return nil, fmt.Errorf("failed to assign inquiry: %w", err)
+	}
+
+	a := &models.InquiryAssignment{
+		InquiryID:        inquiryID,
+		PreviousAssignee: previous,
+		AssignedTo:       assignee,
+		AssignedBy:       &assignedBy,
+		Note:             note,
+	}
+	err = tx.QueryRow(ctx, `
+		INSERT INTO inquiry_assignments (inquiry_id, previous_assignee, assigned_to, assigned_by, note)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, inquiryID, previous, assignee, assignedBy, note).Scan(&a.ID, &a.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record assignment: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return a, nil
+}
+
+// GetAssignments returns the assignment history of an inquiry in chronological order.
+func (r *InquiryRepository) GetAssignments(ctx context.Context, inquiryID int64) ([]models.InquiryAssignment, error) {
+	sql := `
+		SELECT a.id, a.inquiry_id, a.previous_assignee, a.assigned_to, COALESCE(t.name, ''),
+		       a.assigned_by, COALESCE(b.name, ''), a.note, a.created_at
+		FROM inquiry_assignments a
+		LEFT JOIN users t ON t.id = a.assigned_to
+		LEFT JOIN users b ON b.id = a.assigned_by
+		WHERE a.inquiry_id = $1
+		ORDER BY a.created_at, a.id
+	`
+
+	rows, err := r.DB.Query(ctx, sql, inquiryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query inquiry assignments: %w", err)
+	}
+	defer rows.Close()
+
+	assignments := []models.InquiryAssignment{}
+	for rows.Next() {
+		var a models.InquiryAssignment
+		if err := rows.Scan(&a.ID, &a.InquiryID, &a.PreviousAssignee, &a.AssignedTo, &a.AssigneeName,
+			&a.AssignedBy, &a.AssignedByName, &a.Note, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan inquiry assignment row: %w", err)
+		}
+		assignments = append(assignments, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating inquiry assignment rows: %w", err)
+	}
+
+	return assignments, nil
+}
@@ -0,0 +1,88 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// InquiryAttachmentRepository holds the metadata of files sent with inquiries.
+// The files themselves are stored on disk by the handler.
+type InquiryAttachmentRepository struct {
+	DB *pgxpool.Pool
+}
+
+// newInquiryAttachmentRepository creates a new instance of the repository.
+func newInquiryAttachmentRepository(db *pgxpool.Pool) *InquiryAttachmentRepository {
+	return &InquiryAttachmentRepository{DB: db}
+}
+
+// Create records an attachment whose file has been saved.
+func (r *InquiryAttachmentRepository) Create(ctx context.Context, a *models.InquiryAttachment) error {
+	sql := `
+		INSERT INTO inquiry_attachments (inquiry_id, file_name, stored_name, content_type, size_bytes)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+	err := r.DB.QueryRow(ctx, sql, a.InquiryID, a.FileName, a.StoredName, a.ContentType, a.Size).
+		Scan(&a.ID, &a.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create inquiry attachment: %w", err)
+	}
+	return nil
+}
+
+// GetByID retrieves a single attachment by its ID.
+func (r *InquiryAttachmentRepository) GetByID(ctx context.Context, id int64) (*models.InquiryAttachment, error) {
+	sql := `
+		SELECT id, inquiry_id, file_name, stored_name, content_type, size_bytes, created_at
+		FROM inquiry_attachments
+		WHERE id = $1
+	`
+
+	var a models.InquiryAttachment
+	err := r.DB.QueryRow(ctx, sql, id).Scan(&a.ID, &a.InquiryID, &a.FileName, &a.StoredName, &a.ContentType, &a.Size, &a.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, fmt.Errorf("attachment not found with id: %d", id)
+		}
+		return nil, fmt.Errorf("failed to get inquiry attachment: %w", err)
+	}
+
+	return &a, nil
+}
+
+// GetByInquiry returns the attachments of an inquiry in upload order.
+func (r *InquiryAttachmentRepository) GetByInquiry(ctx context.Context, inquiryID int64) ([]models.InquiryAttachment, error) {
+	sql := `
+		SELECT id, inquiry_id, file_name, stored_name, content_type, size_bytes, created_at
+		FROM inquiry_attachments
+		WHERE inquiry_id = $1
+		ORDER BY id
+	`
+
+	rows, err := r.DB.Query(ctx, sql, inquiryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query inquiry attachments: %w", err)
+	}
+	defer rows.Close()
+
+	attachments := []models.InquiryAttachment{}
+	for rows.Next() {
+		var a models.InquiryAttachment
+		if err := rows.Scan(&a.ID, &a.InquiryID, &a.FileName, &a.StoredName, &a.ContentType, &a.Size, &a.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan inquiry attachment row: %w", err)
+		}
+		attachments = append(attachments, a)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating inquiry attachment rows: %w", err)
+	}
+
+	return attachments, nil
+}
@@ -0,0 +1,67 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// ErrInquiryConverted is returned by ConvertToClient when the inquiry already has a client.
+var ErrInquiryConverted = errors.New("inquiry has already been converted to a client")
+
+// ConvertToClient creates client from the inquiry, links both records and moves the inquiry from
+// change.From to change.To, recording the change in the status history. All of it happens in one
+// transaction, so a failure leaves neither a client nor a changed inquiry behind.
+func (r *InquiryRepository) ConvertToClient(ctx context.Context, inquiryID int64, client *models.Client, change *models.InquiryStatusChange) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	var clientID *int64
+	err = tx.QueryRow(ctx, `
+		SELECT status, client_id FROM inquiries WHERE id = $1 FOR UPDATE
+	`, inquiryID).Scan(&status, &clientID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("inquiry not found with id: %d", inquiryID)
+		}
+		return fmt.Errorf("failed to load inquiry: %w", err)
+	}
+	if clientID != nil {
+		return ErrInquiryConverted
+	}
+	if status != change.From {
+		return ErrStatusConflict
+	}
+
+	client.InquiryID = &inquiryID
+	err = tx.QueryRow(ctx, `
+		INSERT INTO clients (name, area, service_name, service_date, status, note, image_link, inquiry_id)
+		VALUES ($1, $2, $3, $4, $5, $6, '', $7)
+		RETURNING id, created_at, updated_at
+	`, client.Name, client.Area, client.ServiceName, client.ServiceDate, client.Status, client.Note, inquiryID).
+		Scan(&client.ID, &client.CreatedAt, &client.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert client: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE inquiries SET client_id = $1, status = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, client.ID, change.To, inquiryID); err != nil {
+		return fmt.Errorf("failed to update inquiry: %w", err)
+	}
+
+	change.InquiryID = inquiryID
+	if err := insertStatusChange(ctx, tx, change); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,57 @@
+package dbrepo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// Export calls fn for every inquiry matching the filter (paging is ignored), newest first, together with
+// its status history. Rows are streamed, so fn may write them out straight away; an error from fn stops
+// the export and is returned.
+func (r *InquiryRepository) Export(ctx context.Context, f models.InquiryFilter, fn func(*models.InquiryExportRow) error) error {
+	where, args := inquiryFilterClause(f)
+
+	sql := inquiryColumns + `,
+	       COALESCE(h.changes, 0), h.last_at, COALESCE(h.last_by, ''), COALESCE(h.history, '')` + inquiryFrom + `
+	LEFT JOIN LATERAL (
+		SELECT COUNT(*) FILTER (WHERE sh.from_status <> '') AS changes,
+		       MAX(sh.created_at) AS last_at,
+		       (ARRAY_AGG(cu.name ORDER BY sh.created_at DESC, sh.id DESC))[1] AS last_by,
+		       STRING_AGG(
+		           TO_CHAR(sh.created_at, 'YYYY-MM-DD HH24:MI') || ' ' || sh.to_status
+		           || COALESCE(' by ' || cu.name, '')
+		           || CASE WHEN sh.comment <> '' THEN ' (' || sh.comment || ')' ELSE '' END,
+		           '; ' ORDER BY sh.created_at, sh.id
+		       ) AS history
+		FROM inquiry_status_history sh
+		LEFT JOIN users cu ON cu.id = sh.changed_by
+		WHERE sh.inquiry_id = i.id
+	) h ON TRUE` + where + `
+	ORDER BY i.inquiry_date DESC, i.id DESC`
+
+	rows, err := r.DB.Query(ctx, sql, args...)
+	if err != nil {
+		return fmt.Errorf("failed to query inquiries for export: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row models.InquiryExportRow
+		targets := append(inquiryScanTargets(&row.Inquiry),
+			&row.StatusChanges, &row.LastStatusChange, &row.LastChangedBy, &row.StatusHistory)
+		if err := rows.Scan(targets...); err != nil {
+			return fmt.Errorf("failed to scan inquiry export row: %w", err)
+		}
+		if err := fn(&row); err != nil {
+			return err
+		}
+	}
+
+	if err = rows.Err(); err != nil {
+		return fmt.Errorf("error iterating inquiry export rows: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,87 @@
+package dbrepo
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// InquiryMessageRepository holds the conversation threads of inquiries.
+type InquiryMessageRepository struct {
+	DB *pgxpool.Pool
+}
+
+// newInquiryMessageRepository creates a new instance of the repository.
+func newInquiryMessageRepository(db *pgxpool.Pool) *InquiryMessageRepository {
+	return &InquiryMessageRepository{DB: db}
+}
+
+// Create adds a message to an inquiry's thread and touches the inquiry's updated_at.
+func (r *InquiryMessageRepository) Create(ctx context.Context, m *models.InquiryMessage) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	sql := `
+		INSERT INTO inquiry_messages (inquiry_id, kind, body, author_id, recipient, email_status)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+	err = tx.QueryRow(ctx, sql, m.InquiryID, m.Kind, m.Body, m.AuthorID, m.Recipient, m.EmailStatus).
+		Scan(&m.ID, &m.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create inquiry message: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE inquiries SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`, m.InquiryID); err != nil {
+		return fmt.Errorf("failed to touch inquiry: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SetEmailStatus records the delivery outcome of a reply.
+func (r *InquiryMessageRepository) SetEmailStatus(ctx context.Context, id int64, status string) error {
+	if _, err := r.DB.Exec(ctx, `UPDATE inquiry_messages SET email_status = $1 WHERE id = $2`, status, id); err != nil {
+		return fmt.Errorf("failed to update inquiry message: %w", err)
+	}
+	return nil
+}
+
+// GetByInquiry returns the thread of an inquiry in chronological order.
+func (r *InquiryMessageRepository) GetByInquiry(ctx context.Context, inquiryID int64) ([]models.InquiryMessage, error) {
+	sql := `
+		SELECT m.id, m.inquiry_id, m.kind, m.body, m.author_id, COALESCE(u.name, ''),
+		       m.recipient, m.email_status, m.created_at
+		FROM inquiry_messages m
+		LEFT JOIN users u ON u.id = m.author_id
+		WHERE m.inquiry_id = $1
+		ORDER BY m.created_at, m.id
+	`
+
+	rows, err := r.DB.Query(ctx, sql, inquiryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query inquiry messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []models.InquiryMessage{}
+	for rows.Next() {
+		var m models.InquiryMessage
+		if err := rows.Scan(&m.ID, &m.InquiryID, &m.Kind, &m.Body, &m.AuthorID, &m.AuthorName,
+			&m.Recipient, &m.EmailStatus, &m.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan inquiry message row: %w", err)
+		}
+		messages = append(messages, m)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating inquiry message rows: %w", err)
+	}
+
+	return messages, nil
+}
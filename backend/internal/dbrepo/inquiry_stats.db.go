@@ -0,0 +1,144 @@
+package dbrepo
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// GetStats builds the analytics report for inquiries received in [from, to). SPAM inquiries are left out
+// unless includeSpam is set.
+//
+// Time to first response runs from inquiry_date to the first reply emailed to the customer. Time to
+// resolution runs to the first change into a closed status other than SPAM; the initial history entries
+// are not changes and do not count.
+func (r *InquiryRepository) GetStats(ctx context.Context, from, to time.Time, includeSpam bool) (*models.InquiryStats, error) {
+	stats := &models.InquiryStats{
+		From:        from,
+		To:          to,
+		IncludeSpam: includeSpam,
+		BySubject:   []models.InquirySubjectStats{},
+		ByStatus:    map[string]int{},
+	}
+
+	spamClause := ""
+	if !includeSpam {
+		spamClause = " AND i.status <> '" + models.InquiryStatusSpam + "'"
+	}
+
+	var err error
+	if stats.Daily, err = r.volume(ctx, from, to, "day", spamClause); err != nil {
+		return nil, err
+	}
+	if stats.Weekly, err = r.volume(ctx, from, to, "week", spamClause); err != nil {
+		return nil, err
+	}
+	if stats.Monthly, err = r.volume(ctx, from, to, "month", spamClause); err != nil {
+		return nil, err
+	}
+
+	// Per subject with a ROLLUP row (subject NULL) for the overall figures
+	sql := `
+		WITH base AS (
+			SELECT COALESCE(NULLIF(TRIM(i.subject), ''), '(none)') AS subject, i.inquiry_date, i.client_id,
+			       (SELECT MIN(m.created_at) FROM inquiry_messages m
+			        WHERE m.inquiry_id = i.id AND m.kind = 'REPLY') AS first_reply,
+			       (SELECT MIN(sh.created_at) FROM inquiry_status_history sh
+			        JOIN inquiry_statuses s ON s.code = sh.to_status
+			        WHERE sh.inquiry_id = i.id AND sh.from_status <> '' AND s.is_closed
+			          AND sh.to_status <> '` + models.InquiryStatusSpam + `') AS resolved_at
+			FROM inquiries i
+			WHERE i.inquiry_date >= $1 AND i.inquiry_date < $2` + spamClause + `
+		)
+		SELECT subject, COUNT(*),
+		       COUNT(first_reply), AVG(EXTRACT(EPOCH FROM first_reply - inquiry_date)) / 3600,
+		       COUNT(resolved_at), AVG(EXTRACT(EPOCH FROM resolved_at - inquiry_date)) / 3600,
+		       COUNT(client_id)
+		FROM base
+		GROUP BY ROLLUP (subject)
+		ORDER BY COUNT(*) DESC, subject
+	`
+	rows, err := r.DB.Query(ctx, sql, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query inquiry performance: %w", err)
+	}
+	for rows.Next() {
+		var subject *string
+		var p models.InquiryPerformance
+		if err := rows.Scan(&subject, &p.Total, &p.Responded, &p.AvgFirstResponseHours,
+			&p.Resolved, &p.AvgResolutionHours, &p.Converted); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan inquiry performance row: %w", err)
+		}
+		if subject == nil {
+			stats.Overall = p
+			continue
+		}
+		stats.BySubject = append(stats.BySubject, models.InquirySubjectStats{Subject: *subject, InquiryPerformance: p})
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating inquiry performance rows: %w", err)
+	}
+
+	rows, err = r.DB.Query(ctx, `
+		SELECT i.status, COUNT(*) FROM inquiries i
+		WHERE i.inquiry_date >= $1 AND i.inquiry_date < $2`+spamClause+`
+		GROUP BY i.status
+	`, from, to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query inquiry status counts: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan inquiry status count: %w", err)
+		}
+		stats.ByStatus[status] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating inquiry status counts: %w", err)
+	}
+
+	return stats, nil
+}
+
+// volume counts inquiries per period (day, week or month) in [from, to), including empty periods.
+func (r *InquiryRepository) volume(ctx context.Context, from, to time.Time, period, spamClause string) ([]models.InquiryVolume, error) {
+	sql := `
+		SELECT g.period, COUNT(i.id)
+		FROM generate_series(
+			DATE_TRUNC($3, $1::timestamptz),
+			$2::timestamptz - INTERVAL '1 microsecond',
+			('1 ' || $3)::interval
+		) AS g(period)
+		LEFT JOIN inquiries i
+			ON DATE_TRUNC($3, i.inquiry_date) = g.period
+			AND i.inquiry_date >= $1 AND i.inquiry_date < $2` + spamClause + `
+		GROUP BY g.period
+		ORDER BY g.period
+	`
+	rows, err := r.DB.Query(ctx, sql, from, to, period)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s inquiry volume: %w", period, err)
+	}
+	defer rows.Close()
+
+	volume := []models.InquiryVolume{}
+	for rows.Next() {
+		var v models.InquiryVolume
+		if err := rows.Scan(&v.Period, &v.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan inquiry volume row: %w", err)
+		}
+		volume = append(volume, v)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating inquiry volume rows: %w", err)
+	}
+
+	return volume, nil
+}
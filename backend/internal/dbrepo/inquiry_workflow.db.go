@@ -0,0 +1,123 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// ErrStatusInUse is returned by Replace when a status that would be removed is still used by inquiries.
+var ErrStatusInUse = errors.New("status is still used by inquiries")
+
+// InquiryWorkflowRepository holds the configurable inquiry statuses and transitions.
+type InquiryWorkflowRepository struct {
+	DB *pgxpool.Pool
+}
+
+// newInquiryWorkflowRepository creates a new instance of the repository.
+func newInquiryWorkflowRepository(db *pgxpool.Pool) *InquiryWorkflowRepository {
+	return &InquiryWorkflowRepository{DB: db}
+}
+
+// Get returns the statuses (in display order) and the allowed transitions.
+func (r *InquiryWorkflowRepository) Get(ctx context.Context) (*models.InquiryWorkflow, error) {
+	wf := &models.InquiryWorkflow{
+		Statuses:    []models.InquiryStatus{},
+		Transitions: []models.InquiryTransition{},
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT code, label, is_initial, is_closed, sort_order
+		FROM inquiry_statuses
+		ORDER BY sort_order, code
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query inquiry statuses: %w", err)
+	}
+	for rows.Next() {
+		var s models.InquiryStatus
+		if err := rows.Scan(&s.Code, &s.Label, &s.IsInitial, &s.IsClosed, &s.SortOrder); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan inquiry status row: %w", err)
+		}
+		wf.Statuses = append(wf.Statuses, s)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating inquiry status rows: %w", err)
+	}
+
+	rows, err = r.DB.Query(ctx, `
+		SELECT from_status, to_status FROM inquiry_status_transitions ORDER BY from_status, to_status
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query inquiry transitions: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var t models.InquiryTransition
+		if err := rows.Scan(&t.From, &t.To); err != nil {
+			return nil, fmt.Errorf("failed to scan inquiry transition row: %w", err)
+		}
+		wf.Transitions = append(wf.Transitions, t)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating inquiry transition rows: %w", err)
+	}
+
+	return wf, nil
+}
+
+// Replace stores a new workflow in one transaction. Statuses missing from wf are deleted, which fails
+// with ErrStatusInUse while inquiries still have them. The caller validates wf beforehand.
+func (r *InquiryWorkflowRepository) Replace(ctx context.Context, wf *models.InquiryWorkflow) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	codes := make([]string, len(wf.Statuses))
+	for i, s := range wf.Statuses {
+		codes[i] = s.Code
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO inquiry_statuses (code, label, is_initial, is_closed, sort_order)
+			VALUES ($1, $2, $3, $4, $5)
+			ON CONFLICT (code) DO UPDATE
+			SET label = EXCLUDED.label, is_initial = EXCLUDED.is_initial,
+			    is_closed = EXCLUDED.is_closed, sort_order = EXCLUDED.sort_order
+		`, s.Code, s.Label, s.IsInitial, s.IsClosed, s.SortOrder); err != nil {
+			return fmt.Errorf("failed to save inquiry status: %w", err)
+		}
+	}
+
+	var inUse string
+	err = tx.QueryRow(ctx, `
+		SELECT COALESCE(MIN(status), '') FROM inquiries WHERE NOT (status = ANY($1))
+	`, codes).Scan(&inUse)
+	if err != nil {
+		return fmt.Errorf("failed to check inquiry statuses: %w", err)
+	}
+	if inUse != "" {
+		return fmt.Errorf("%w: %s", ErrStatusInUse, inUse)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM inquiry_status_transitions`); err != nil {
+		return fmt.Errorf("failed to delete inquiry transitions: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM inquiry_statuses WHERE NOT (code = ANY($1))`, codes); err != nil {
+		return fmt.Errorf("failed to delete inquiry statuses: %w", err)
+	}
+	for _, t := range wf.Transitions {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO inquiry_status_transitions (from_status, to_status) VALUES ($1, $2)
+		`, t.From, t.To); err != nil {
+			return fmt.Errorf("failed to save inquiry transition: %w", err)
+		}
+	}
+
+	return tx.Commit(ctx)
+}
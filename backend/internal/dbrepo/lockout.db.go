@@ -0,0 +1,163 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// ============================== Login Attempts ==============================
+
+// LoginAttemptRepository records sign-in attempts for throttling and auditing.
+type LoginAttemptRepository struct {
+	DB *pgxpool.Pool
+}
+
+// newLoginAttemptRepository creates a new instance of the repository.
+func newLoginAttemptRepository(db *pgxpool.Pool) *LoginAttemptRepository {
+	return &LoginAttemptRepository{DB: db}
+}
+
+// Record stores a sign-in attempt. userID may be 0 when the username did not match an account.
+// Attempts older than 90 days are purged on the way.
+func (r *LoginAttemptRepository) Record(ctx context.Context, username string, userID int64, ip string, success bool) error {
+	var uid any
+	if userID > 0 {
+		uid = userID
+	}
+
+	sql := `
+		INSERT INTO login_attempts (username, user_id, ip_address, success)
+		VALUES ($1, $2, $3, $4)
+	`
+	if _, err := r.DB.Exec(ctx, sql, username, uid, ip, success); err != nil {
+		return fmt.Errorf("failed to record login attempt: %w", err)
+	}
+
+	if _, err := r.DB.Exec(ctx, `DELETE FROM login_attempts WHERE created_at < CURRENT_TIMESTAMP - INTERVAL '90 days'`); err != nil {
+		return fmt.Errorf("failed to purge login attempts: %w", err)
+	}
+
+	return nil
+}
+
+// CountFailuresByIP returns the number of failed attempts from ip since the given time.
+func (r *LoginAttemptRepository) CountFailuresByIP(ctx context.Context, ip string, since time.Time) (int, error) {
+	sql := `
+		SELECT COUNT(*) FROM login_attempts
+		WHERE ip_address = $1 AND success = FALSE AND created_at >= $2
+	`
+
+	var count int
+	if err := r.DB.QueryRow(ctx, sql, ip, since).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count login attempts: %w", err)
+	}
+
+	return count, nil
+}
+
+// ============================== Account Lockout ==============================
+
+// RegisterFailedLogin increments the user's failed sign-in counter. When it reaches maxAttempts the
+// account is locked; the lock lasts lockDuration doubled for every previous lock, capped at maxLock.
+// It returns the end of the lock, or nil if the account is not locked.
+func (r *UserRepo) RegisterFailedLogin(ctx context.Context, id int64, maxAttempts int, lockDuration, maxLock time.Duration) (*time.Time, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	var failed, locks int
+	var lockedUntil *time.Time
+	err = tx.QueryRow(ctx, `
+		SELECT status, failed_login_count, lock_count, locked_until
+		FROM users WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&status, &failed, &locks, &lockedUntil)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("no user found")
+		}
+		return nil, fmt.Errorf("failed to load login counters: %w", err)
+	}
+
+	// A lock that has run out starts a fresh count
+	if status == "Locked" && lockedUntil != nil && lockedUntil.Before(time.Now()) {
+		status = "Active"
+		failed = 0
+	}
+
+	failed++
+	if failed < maxAttempts || status != "Active" {
+		if _, err := tx.Exec(ctx, `
+			UPDATE users SET failed_login_count = $1 WHERE id = $2
+		`, failed, id); err != nil {
+			return nil, fmt.Errorf("failed to update login counters: %w", err)
+		}
+		return nil, tx.Commit(ctx)
+	}
+
+	// Lock the account: duration doubles with each lock
+	locks++
+	duration := lockDuration
+	for i := 1; i < locks && duration < maxLock; i++ {
+		duration *= 2
+	}
+	if duration > maxLock {
+		duration = maxLock
+	}
+	until := time.Now().Add(duration)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE users
+		SET status = 'Locked', failed_login_count = 0, lock_count = $1, locked_until = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, locks, until, id); err != nil {
+		return nil, fmt.Errorf("failed to lock account: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return &until, nil
+}
+
+// ResetFailedLogins clears the lockout state after a successful sign-in.
+// An expired lock is lifted; an Inactive account stays Inactive.
+func (r *UserRepo) ResetFailedLogins(ctx context.Context, id int64) error {
+	query := `
+		UPDATE users
+		SET failed_login_count = 0, lock_count = 0, locked_until = NULL,
+		    status = CASE WHEN status = 'Locked' THEN 'Active' ELSE status END
+		WHERE id = $1
+	`
+	if _, err := r.db.Exec(ctx, query, id); err != nil {
+		return fmt.Errorf("failed to reset login counters: %w", err)
+	}
+	return nil
+}
+
+// UnlockUser lifts a lock immediately (admin action).
+func (r *UserRepo) UnlockUser(ctx context.Context, id int64) error {
+	query := `
+		UPDATE users
+		SET failed_login_count = 0, lock_count = 0, locked_until = NULL,
+		    status = CASE WHEN status = 'Locked' THEN 'Active' ELSE status END,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`
+	cmdTag, err := r.db.Exec(ctx, query, id)
+	if err != nil {
+		return fmt.Errorf("failed to unlock user: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no user found with id: %d", id)
+	}
+	return nil
+}
@@ -0,0 +1,122 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// ErrResetTokenInvalid is returned when a reset token does not exist, has expired or was already used.
+var ErrResetTokenInvalid = errors.New("reset token is invalid or has expired")
+
+// PasswordResetRepository stores emailed password reset tokens.
+type PasswordResetRepository struct {
+	DB *pgxpool.Pool
+}
+
+// newPasswordResetRepository creates a new instance of the repository.
+func newPasswordResetRepository(db *pgxpool.Pool) *PasswordResetRepository {
+	return &PasswordResetRepository{DB: db}
+}
+
+// Create stores a new reset token and invalidates the user's earlier unused tokens.
+// Earlier rows are kept so CountRecent sees every request; only tokens expired for more than a day are purged.
+func (r *PasswordResetRepository) Create(ctx context.Context, t *models.PasswordResetToken) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE password_reset_tokens SET used_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND used_at IS NULL
+	`, t.UserID); err != nil {
+		return fmt.Errorf("failed to invalidate reset tokens: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		DELETE FROM password_reset_tokens WHERE expires_at < CURRENT_TIMESTAMP - INTERVAL '1 day'
+	`); err != nil {
+		return fmt.Errorf("failed to purge reset tokens: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO password_reset_tokens (user_id, token_hash, expires_at, ip_address)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id, created_at
+	`, t.UserID, t.TokenHash, t.ExpiresAt, t.IPAddress).Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create reset token: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// CountRecent returns how many reset tokens were issued for the user since the given time.
+func (r *PasswordResetRepository) CountRecent(ctx context.Context, userID int64, since time.Time) (int, error) {
+	var count int
+	err := r.DB.QueryRow(ctx, `
+		SELECT COUNT(*) FROM password_reset_tokens WHERE user_id = $1 AND created_at >= $2
+	`, userID, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count reset tokens: %w", err)
+	}
+	return count, nil
+}
+
+// Consume redeems a reset token in one transaction: it marks the token used, sets the new password hash,
+// lifts a lock and signs the user out everywhere. It returns the user ID, or ErrResetTokenInvalid.
+func (r *PasswordResetRepository) Consume(ctx context.Context, tokenHash, hashedPassword string) (int64, error) {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var userID int64
+	err = tx.QueryRow(ctx, `
+		UPDATE password_reset_tokens SET used_at = CURRENT_TIMESTAMP
+		WHERE token_hash = $1 AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		RETURNING user_id
+	`, tokenHash).Scan(&userID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, ErrResetTokenInvalid
+		}
+		return 0, fmt.Errorf("failed to use reset token: %w", err)
+	}
+
+	// An Inactive account stays Inactive; a Locked one is unlocked
+	cmdTag, err := tx.Exec(ctx, `
+		UPDATE users
+		SET password = $1, token_version = token_version + 1,
+		    failed_login_count = 0, lock_count = 0, locked_until = NULL,
+		    status = CASE WHEN status = 'Locked' THEN 'Active' ELSE status END,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, hashedPassword, userID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to update password: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return 0, ErrResetTokenInvalid
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID); err != nil {
+		return 0, fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return 0, err
+	}
+	return userID, nil
+}
@@ -0,0 +1,230 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+var (
+	// ErrCategorySlugTaken is returned when another category already uses the slug.
+	ErrCategorySlugTaken = errors.New("another category already uses this slug")
+	// ErrCategoryParentMissing is returned when the parent category does not exist.
+	ErrCategoryParentMissing = errors.New("the parent category does not exist")
+	// ErrCategoryHasChildren is returned when a category with subcategories is deleted.
+	ErrCategoryHasChildren = errors.New("the category has subcategories, move or delete them first")
+)
+
+// ProductCategoryRepository holds the database pool connection for the category tree.
+type ProductCategoryRepository struct {
+	DB *pgxpool.Pool
+}
+
+// newProductCategoryRepository creates a new instance of the repository.
+func newProductCategoryRepository(db *pgxpool.Pool) *ProductCategoryRepository {
+	return &ProductCategoryRepository{DB: db}
+}
+
+// categoryColumns are the columns read by scanCategory, in order.
+const categoryColumns = `
+	c.id, c.parent_id, c.slug, c.name, c.description, c.image_link, c.sort_order,
+	(SELECT COUNT(*) FROM products p WHERE p.category_id = c.id AND p.is_active),
+	c.created_at, c.updated_at`
+
+func scanCategory(row pgx.Row, c *models.ProductCategory) error {
+	return row.Scan(
+		&c.ID,
+		&c.ParentID,
+		&c.Slug,
+		&c.Name,
+		&c.Description,
+		&c.ImageLink,
+		&c.SortOrder,
+		&c.ProductCount,
+		&c.CreatedAt,
+		&c.UpdatedAt,
+	)
+}
+
+// categoryWriteError maps constraint violations to the errors shown to the user.
+func categoryWriteError(action string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			return ErrCategorySlugTaken
+		case "23503": // foreign_key_violation
+			return ErrCategoryParentMissing
+		}
+	}
+	return fmt.Errorf("failed to %s category: %w", action, err)
+}
+
+// Create inserts the category and sets its ID and timestamps.
+func (r *ProductCategoryRepository) Create(ctx context.Context, c *models.ProductCategory) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `
+		INSERT INTO product_categories (parent_id, slug, name, description, image_link, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.DB.QueryRow(ctx, stmt, c.ParentID, c.Slug, c.Name, c.Description, c.ImageLink, c.SortOrder).
+		Scan(&c.ID, &c.CreatedAt, &c.UpdatedAt)
+	if err != nil {
+		return categoryWriteError("insert", err)
+	}
+
+	return nil
+}
+
+// Update modifies every field of the category, including its image link.
+func (r *ProductCategoryRepository) Update(ctx context.Context, c *models.ProductCategory) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `
+		UPDATE product_categories
+		SET parent_id = $1, slug = $2, name = $3, description = $4, image_link = $5, sort_order = $6,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $7
+		RETURNING updated_at
+	`
+
+	err := r.DB.QueryRow(ctx, stmt, c.ParentID, c.Slug, c.Name, c.Description, c.ImageLink, c.SortOrder, c.ID).
+		Scan(&c.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("no category found")
+		}
+		return categoryWriteError("update", err)
+	}
+
+	return nil
+}
+
+// Delete removes a category. Its products are kept without a category.
+func (r *ProductCategoryRepository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	cmdTag, err := r.DB.Exec(ctx, `DELETE FROM product_categories WHERE id = $1`, id)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" { // a subcategory still points at it
+			return ErrCategoryHasChildren
+		}
+		return fmt.Errorf("failed to delete category: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.New("no category found")
+	}
+
+	return nil
+}
+
+// GetByID returns a single category.
+func (r *ProductCategoryRepository) GetByID(ctx context.Context, id int64) (*models.ProductCategory, error) {
+	return r.getOne(ctx, `c.id = $1`, id)
+}
+
+// GetBySlug returns a single category by its slug.
+func (r *ProductCategoryRepository) GetBySlug(ctx context.Context, slug string) (*models.ProductCategory, error) {
+	return r.getOne(ctx, `c.slug = $1`, slug)
+}
+
+func (r *ProductCategoryRepository) getOne(ctx context.Context, cond string, arg any) (*models.ProductCategory, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var c models.ProductCategory
+	err := scanCategory(r.DB.QueryRow(ctx, `SELECT `+categoryColumns+` FROM product_categories c WHERE `+cond, arg), &c)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("no category found")
+		}
+		return nil, fmt.Errorf("failed to get category: %w", err)
+	}
+
+	return &c, nil
+}
+
+// GetAll returns every category as a flat list, in display order.
+func (r *ProductCategoryRepository) GetAll(ctx context.Context) ([]*models.ProductCategory, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.Query(ctx, `SELECT `+categoryColumns+` FROM product_categories c ORDER BY c.sort_order, c.name, c.id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query categories: %w", err)
+	}
+	defer rows.Close()
+
+	categories := []*models.ProductCategory{}
+	for rows.Next() {
+		var c models.ProductCategory
+		if err := scanCategory(rows, &c); err != nil {
+			return nil, fmt.Errorf("failed to scan category row: %w", err)
+		}
+		categories = append(categories, &c)
+	}
+
+	return categories, rows.Err()
+}
+
+// GetTree returns the top-level categories with their subcategories nested in Children.
+func (r *ProductCategoryRepository) GetTree(ctx context.Context) ([]*models.ProductCategory, error) {
+	categories, err := r.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int64]*models.ProductCategory, len(categories))
+	for _, c := range categories {
+		byID[c.ID] = c
+	}
+
+	roots := []*models.ProductCategory{}
+	for _, c := range categories {
+		if c.ParentID != nil {
+			if parent, ok := byID[*c.ParentID]; ok {
+				parent.Children = append(parent.Children, c)
+				continue
+			}
+		}
+		roots = append(roots, c)
+	}
+
+	return roots, nil
+}
+
+// WouldCycle reports whether making parentID the parent of category id would create a loop,
+// i.e. whether parentID is id itself or one of its subcategories.
+func (r *ProductCategoryRepository) WouldCycle(ctx context.Context, id, parentID int64) (bool, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `
+		WITH RECURSIVE ancestors AS (
+			SELECT id, parent_id FROM product_categories WHERE id = $2
+			UNION ALL
+			SELECT pc.id, pc.parent_id FROM product_categories pc JOIN ancestors a ON pc.id = a.parent_id
+		)
+		SELECT EXISTS (SELECT 1 FROM ancestors WHERE id = $1)
+	`
+
+	var found bool
+	if err := r.DB.QueryRow(ctx, stmt, id, parentID).Scan(&found); err != nil {
+		return false, fmt.Errorf("failed to check category tree: %w", err)
+	}
+
+	return found, nil
+}
@@ -0,0 +1,202 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// ProductDatasheetRepository stores the versions of the products' spec sheets.
+type ProductDatasheetRepository struct {
+	DB *pgxpool.Pool
+}
+
+// newProductDatasheetRepository creates a new instance of the repository.
+func newProductDatasheetRepository(db *pgxpool.Pool) *ProductDatasheetRepository {
+	return &ProductDatasheetRepository{DB: db}
+}
+
+// datasheetColumns are the columns read by scanDatasheet, in order.
+const datasheetColumns = `
+	d.id, d.product_id, d.version, d.file_name, d.stored_name, d.size, d.is_current,
+	d.uploaded_by, COALESCE(u.name, ''), d.created_at`
+
+func scanDatasheet(row pgx.Row, d *models.ProductDatasheet) error {
+	return row.Scan(
+		&d.ID,
+		&d.ProductID,
+		&d.Version,
+		&d.FileName,
+		&d.StoredName,
+		&d.Size,
+		&d.IsCurrent,
+		&d.UploadedBy,
+		&d.UploadedByName,
+		&d.CreatedAt,
+	)
+}
+
+// Add records a new version of the product's spec sheet, numbered after the existing ones,
+// and makes it the current one.
+func (r *ProductDatasheetRepository) Add(ctx context.Context, d *models.ProductDatasheet) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	// Lock the product so concurrent uploads are numbered one after the other
+	var productID int64
+	err = tx.QueryRow(ctx, `SELECT id FROM products WHERE id = $1 FOR UPDATE`, d.ProductID).Scan(&productID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("no product found")
+		}
+		return fmt.Errorf("failed to lock product: %w", err)
+	}
+
+	var version int
+	err = tx.QueryRow(ctx, `SELECT COALESCE(MAX(version), 0) + 1 FROM product_datasheets WHERE product_id = $1`, d.ProductID).
+		Scan(&version)
+	if err != nil {
+		return fmt.Errorf("failed to get next datasheet version: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE product_datasheets SET is_current = FALSE WHERE product_id = $1 AND is_current`, d.ProductID); err != nil {
+		return fmt.Errorf("failed to replace current datasheet: %w", err)
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO product_datasheets (product_id, version, file_name, stored_name, size, is_current, uploaded_by)
+		VALUES ($1, $2, $3, $4, $5, TRUE, $6)
+		RETURNING id, created_at
+	`, d.ProductID, version, d.FileName, d.StoredName, d.Size, d.UploadedBy).Scan(&d.ID, &d.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert datasheet: %w", err)
+	}
+	d.Version = version
+	d.IsCurrent = true
+
+	if _, err := tx.Exec(ctx, `UPDATE products SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`, d.ProductID); err != nil {
+		return fmt.Errorf("failed to update product: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetByID returns a single datasheet version.
+func (r *ProductDatasheetRepository) GetByID(ctx context.Context, id int64) (*models.ProductDatasheet, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var d models.ProductDatasheet
+	err := scanDatasheet(r.DB.QueryRow(ctx, `
+		SELECT `+datasheetColumns+`
+		FROM product_datasheets d
+		LEFT JOIN users u ON u.id = d.uploaded_by
+		WHERE d.id = $1
+	`, id), &d)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("no datasheet found")
+		}
+		return nil, fmt.Errorf("failed to get datasheet: %w", err)
+	}
+
+	return &d, nil
+}
+
+// GetByProduct returns every version of the product's spec sheet, newest first.
+func (r *ProductDatasheetRepository) GetByProduct(ctx context.Context, productID int64) ([]models.ProductDatasheet, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT `+datasheetColumns+`
+		FROM product_datasheets d
+		LEFT JOIN users u ON u.id = d.uploaded_by
+		WHERE d.product_id = $1
+		ORDER BY d.version DESC
+	`, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query datasheets: %w", err)
+	}
+	defer rows.Close()
+
+	sheets := []models.ProductDatasheet{}
+	for rows.Next() {
+		var d models.ProductDatasheet
+		if err := scanDatasheet(rows, &d); err != nil {
+			return nil, fmt.Errorf("failed to scan datasheet row: %w", err)
+		}
+		sheets = append(sheets, d)
+	}
+
+	return sheets, rows.Err()
+}
+
+// SetCurrent makes a kept version the product's current spec sheet again.
+func (r *ProductDatasheetRepository) SetCurrent(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var productID int64
+	err = tx.QueryRow(ctx, `SELECT product_id FROM product_datasheets WHERE id = $1`, id).Scan(&productID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("no datasheet found")
+		}
+		return fmt.Errorf("failed to get datasheet: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `SELECT id FROM products WHERE id = $1 FOR UPDATE`, productID); err != nil {
+		return fmt.Errorf("failed to lock product: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE product_datasheets SET is_current = FALSE WHERE product_id = $1 AND is_current`, productID); err != nil {
+		return fmt.Errorf("failed to replace current datasheet: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE product_datasheets SET is_current = TRUE WHERE id = $1`, id); err != nil {
+		return fmt.Errorf("failed to set current datasheet: %w", err)
+	}
+	if _, err := tx.Exec(ctx, `UPDATE products SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`, productID); err != nil {
+		return fmt.Errorf("failed to update product: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Delete removes a datasheet version. When it was the current one the product has no spec sheet
+// until another version is made current or a new one is uploaded.
+func (r *ProductDatasheetRepository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var productID int64
+	err := r.DB.QueryRow(ctx, `DELETE FROM product_datasheets WHERE id = $1 RETURNING product_id`, id).Scan(&productID)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("no datasheet found")
+		}
+		return fmt.Errorf("failed to delete datasheet: %w", err)
+	}
+
+	if _, err := r.DB.Exec(ctx, `UPDATE products SET updated_at = CURRENT_TIMESTAMP WHERE id = $1`, productID); err != nil {
+		return fmt.Errorf("failed to update product: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,379 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+var (
+	// ErrProductCodeTaken is returned when another product already uses the code (case-insensitive).
+	ErrProductCodeTaken = errors.New("another product already uses this code")
+	// ErrProductLinkMissing is returned when the category or brand of a product does not exist.
+	ErrProductLinkMissing = errors.New("the selected category or brand does not exist")
+)
+
+// ProductRepository holds the database pool connection for the product catalogue.
+type ProductRepository struct {
+	DB *pgxpool.Pool
+}
+
+// newProductRepository creates a new instance of the repository.
+func newProductRepository(db *pgxpool.Pool) *ProductRepository {
+	return &ProductRepository{DB: db}
+}
+
+// productColumns are the columns read by scanProduct, in order; productFrom joins what they need.
+const (
+	productColumns = `
+	p.id, p.code, p.name, p.category_id, c.slug, c.name, p.brand_id, b.slug, b.name, p.description, p.specifications,
+	d.id, d.version, d.file_name, d.stored_name, d.size, d.created_at, p.is_active, p.sort_order, p.created_at, p.updated_at`
+	productFrom = `
+	FROM products p
+	LEFT JOIN product_categories c ON c.id = p.category_id
+	LEFT JOIN brands b ON b.id = p.brand_id
+	LEFT JOIN product_datasheets d ON d.product_id = p.id AND d.is_current`
+)
+
+func scanProduct(row pgx.Row, p *models.Product) error {
+	var categorySlug, categoryName, brandSlug, brandName *string
+	var sheetID, sheetSize *int64
+	var sheetVersion *int
+	var sheetFileName, sheetStoredName *string
+	var sheetCreatedAt *time.Time
+	err := row.Scan(
+		&p.ID,
+		&p.Code,
+		&p.Name,
+		&p.CategoryID,
+		&categorySlug,
+		&categoryName,
+		&p.BrandID,
+		&brandSlug,
+		&brandName,
+		&p.Description,
+		&p.Specifications,
+		&sheetID,
+		&sheetVersion,
+		&sheetFileName,
+		&sheetStoredName,
+		&sheetSize,
+		&sheetCreatedAt,
+		&p.IsActive,
+		&p.SortOrder,
+		&p.CreatedAt,
+		&p.UpdatedAt,
+	)
+	if err != nil {
+		return err
+	}
+
+	if p.CategoryID != nil && categorySlug != nil {
+		p.Category = &models.CatalogueRef{ID: *p.CategoryID, Slug: *categorySlug, Name: *categoryName}
+	}
+	if p.BrandID != nil && brandSlug != nil {
+		p.Brand = &models.CatalogueRef{ID: *p.BrandID, Slug: *brandSlug, Name: *brandName}
+	}
+	if sheetID != nil {
+		p.Datasheet = &models.ProductDatasheet{
+			ID:         *sheetID,
+			ProductID:  p.ID,
+			Version:    *sheetVersion,
+			FileName:   *sheetFileName,
+			StoredName: *sheetStoredName,
+			Size:       *sheetSize,
+			IsCurrent:  true,
+			CreatedAt:  *sheetCreatedAt,
+		}
+	}
+	return nil
+}
+
+// productWriteError maps a unique violation on the code to ErrProductCodeTaken and
+// an unknown category or brand to ErrProductLinkMissing.
+func productWriteError(action string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		switch pgErr.Code {
+		case "23505": // unique_violation
+			return ErrProductCodeTaken
+		case "23503": // foreign_key_violation
+			return ErrProductLinkMissing
+		}
+	}
+	return fmt.Errorf("failed to %s product: %w", action, err)
+}
+
+// specsOrEmpty keeps a product without specifications stored as [] rather than null.
+func specsOrEmpty(specs []models.ProductSpec) []models.ProductSpec {
+	if specs == nil {
+		return []models.ProductSpec{}
+	}
+	return specs
+}
+
+// Create inserts the product and sets its ID and timestamps. Images and spec sheets are added afterwards.
+func (r *ProductRepository) Create(ctx context.Context, p *models.Product) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `
+		INSERT INTO products (code, name, category_id, brand_id, description, specifications, is_active, sort_order)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING id, created_at, updated_at
+	`
+
+	err := r.DB.QueryRow(ctx, stmt,
+		p.Code,
+		p.Name,
+		p.CategoryID,
+		p.BrandID,
+		p.Description,
+		specsOrEmpty(p.Specifications),
+		p.IsActive,
+		p.SortOrder,
+	).Scan(&p.ID, &p.CreatedAt, &p.UpdatedAt)
+	if err != nil {
+		return productWriteError("insert", err)
+	}
+
+	return nil
+}
+
+// Update modifies the descriptive fields of a product. Spec sheets are managed by ProductDatasheetRepository.
+func (r *ProductRepository) Update(ctx context.Context, p *models.Product) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `
+		UPDATE products
+		SET code = $1, name = $2, category_id = $3, brand_id = $4, description = $5, specifications = $6,
+		    is_active = $7, sort_order = $8, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $9
+		RETURNING updated_at
+	`
+
+	err := r.DB.QueryRow(ctx, stmt,
+		p.Code,
+		p.Name,
+		p.CategoryID,
+		p.BrandID,
+		p.Description,
+		specsOrEmpty(p.Specifications),
+		p.IsActive,
+		p.SortOrder,
+		p.ID,
+	).Scan(&p.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("no product found")
+		}
+		return productWriteError("update", err)
+	}
+
+	return nil
+}
+
+// Delete removes a product; its image and datasheet rows go with it.
+func (r *ProductRepository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	cmdTag, err := r.DB.Exec(ctx, `DELETE FROM products WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete product: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.New("no product found")
+	}
+
+	return nil
+}
+
+// GetByID returns the product with its images.
+func (r *ProductRepository) GetByID(ctx context.Context, id int64) (*models.Product, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var p models.Product
+	err := scanProduct(r.DB.QueryRow(ctx, `SELECT `+productColumns+productFrom+` WHERE p.id = $1`, id), &p)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("no product found")
+		}
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+
+	if err := r.loadImages(ctx, []*models.Product{&p}); err != nil {
+		return nil, err
+	}
+
+	return &p, nil
+}
+
+// GetAll returns a page of products matching the filter, in catalogue order, and the total count.
+func (r *ProductRepository) GetAll(ctx context.Context, f models.ProductFilter) ([]*models.Product, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	where := ` WHERE 1=1`
+	args := []any{}
+	argIdx := 1
+
+	if !f.IncludeInactive {
+		where += ` AND p.is_active`
+	}
+	if f.Category != "" {
+		where += fmt.Sprintf(` AND p.category_id IN (
+			WITH RECURSIVE tree AS (
+				SELECT id FROM product_categories WHERE slug = $%d
+				UNION ALL
+				SELECT pc.id FROM product_categories pc JOIN tree t ON pc.parent_id = t.id
+			)
+			SELECT id FROM tree)`, argIdx)
+		args = append(args, f.Category)
+		argIdx++
+	}
+	if f.Brand != "" {
+		where += fmt.Sprintf(" AND b.slug = $%d", argIdx)
+		args = append(args, f.Brand)
+		argIdx++
+	}
+	if f.Search != "" {
+		where += fmt.Sprintf(" AND (p.code ILIKE $%d OR p.name ILIKE $%d OR p.description ILIKE $%d)", argIdx, argIdx, argIdx)
+		args = append(args, "%"+f.Search+"%")
+		argIdx++
+	}
+
+	var total int
+	if err := r.DB.QueryRow(ctx, `SELECT COUNT(*)`+productFrom+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count products: %w", err)
+	}
+
+	stmt := `SELECT ` + productColumns + productFrom + where +
+		fmt.Sprintf(" ORDER BY p.sort_order, p.name, p.id LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	args = append(args, f.Limit, (f.Page-1)*f.Limit)
+
+	rows, err := r.DB.Query(ctx, stmt, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query products: %w", err)
+	}
+	defer rows.Close()
+
+	products := []*models.Product{}
+	for rows.Next() {
+		var p models.Product
+		if err := scanProduct(rows, &p); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan product row: %w", err)
+		}
+		products = append(products, &p)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating product rows: %w", err)
+	}
+
+	if err := r.loadImages(ctx, products); err != nil {
+		return nil, 0, err
+	}
+
+	return products, total, nil
+}
+
+// loadImages fills the Images of the given products with a single query.
+func (r *ProductRepository) loadImages(ctx context.Context, products []*models.Product) error {
+	byID := make(map[int64]*models.Product, len(products))
+	ids := make([]int64, 0, len(products))
+	for _, p := range products {
+		p.Images = []models.ProductImage{}
+		byID[p.ID] = p
+		ids = append(ids, p.ID)
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT id, product_id, image_link, alt_text, sort_order, created_at
+		FROM product_images
+		WHERE product_id = ANY($1)
+		ORDER BY sort_order, id
+	`, ids)
+	if err != nil {
+		return fmt.Errorf("failed to query product images: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var img models.ProductImage
+		if err := rows.Scan(&img.ID, &img.ProductID, &img.ImageLink, &img.AltText, &img.SortOrder, &img.CreatedAt); err != nil {
+			return fmt.Errorf("failed to scan product image row: %w", err)
+		}
+		p := byID[img.ProductID]
+		p.Images = append(p.Images, img)
+	}
+
+	return rows.Err()
+}
+
+// AddImage appends an image to the product, after the existing ones.
+func (r *ProductRepository) AddImage(ctx context.Context, img *models.ProductImage) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	stmt := `
+		INSERT INTO product_images (product_id, image_link, alt_text, sort_order)
+		VALUES ($1, $2, $3, (SELECT COALESCE(MAX(sort_order) + 1, 0) FROM product_images WHERE product_id = $1))
+		RETURNING id, sort_order, created_at
+	`
+
+	err := r.DB.QueryRow(ctx, stmt, img.ProductID, img.ImageLink, img.AltText).
+		Scan(&img.ID, &img.SortOrder, &img.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to insert product image: %w", err)
+	}
+
+	return nil
+}
+
+// GetImage returns a single product image.
+func (r *ProductRepository) GetImage(ctx context.Context, id int64) (*models.ProductImage, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var img models.ProductImage
+	err := r.DB.QueryRow(ctx, `
+		SELECT id, product_id, image_link, alt_text, sort_order, created_at
+		FROM product_images
+		WHERE id = $1
+	`, id).Scan(&img.ID, &img.ProductID, &img.ImageLink, &img.AltText, &img.SortOrder, &img.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("no product image found")
+		}
+		return nil, fmt.Errorf("failed to get product image: %w", err)
+	}
+
+	return &img, nil
+}
+
+// DeleteImage removes a product image record.
+func (r *ProductRepository) DeleteImage(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	cmdTag, err := r.DB.Exec(ctx, `DELETE FROM product_images WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete product image: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.New("no product image found")
+	}
+
+	return nil
+}
@@ -0,0 +1,356 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+	"github.com/projuktisheba/ajfses/backend/internal/utils"
+)
+
+var (
+	// ErrQuotationNotDraft is returned when a quotation that was already sent is changed or deleted.
+	ErrQuotationNotDraft = errors.New("the quotation was already sent and can no longer be changed")
+	// ErrQuotationLinkMissing is returned when the RFQ, inquiry or a product of a quotation does not exist.
+	ErrQuotationLinkMissing = errors.New("the linked request, inquiry or product does not exist")
+)
+
+// QuotationRepository stores the quotations with their line items.
+type QuotationRepository struct {
+	DB *pgxpool.Pool
+}
+
+// newQuotationRepository creates a new instance of the repository.
+func newQuotationRepository(db *pgxpool.Pool) *QuotationRepository {
+	return &QuotationRepository{DB: db}
+}
+
+// quotationWriteError maps an unknown RFQ, inquiry or product to ErrQuotationLinkMissing.
+func quotationWriteError(action string, err error) error {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) && pgErr.Code == "23503" { // foreign_key_violation
+		return ErrQuotationLinkMissing
+	}
+	return fmt.Errorf("failed to %s quotation: %w", action, err)
+}
+
+// quotationColumns are the columns read by scanQuotation, in order; the table alias is q.
+const quotationColumns = `
+	q.id, q.quotation_no, q.rfq_id, q.inquiry_id, q.customer_name, q.customer_company, q.customer_email,
+	q.customer_mobile, q.customer_address, q.subject, q.reference, q.issue_date, q.valid_until, q.currency,
+	q.subtotal, q.discount_percent, q.discount, q.vat_rate, q.vat, q.total, q.notes, q.terms, q.status,
+	q.pdf_file, q.sent_at, q.sent_to, q.created_by, COALESCE(u.name, ''), q.created_at, q.updated_at`
+
+// quotationFrom joins the author's name to the quotations.
+const quotationFrom = `
+	FROM quotations q
+	LEFT JOIN users u ON u.id = q.created_by`
+
+func scanQuotation(row pgx.Row, q *models.Quotation) error {
+	return row.Scan(
+		&q.ID,
+		&q.QuotationNo,
+		&q.RfqID,
+		&q.InquiryID,
+		&q.CustomerName,
+		&q.CustomerCompany,
+		&q.CustomerEmail,
+		&q.CustomerMobile,
+		&q.CustomerAddress,
+		&q.Subject,
+		&q.Reference,
+		&q.IssueDate,
+		&q.ValidUntil,
+		&q.Currency,
+		&q.Subtotal,
+		&q.DiscountPercent,
+		&q.Discount,
+		&q.VatRate,
+		&q.Vat,
+		&q.Total,
+		&q.Notes,
+		&q.Terms,
+		&q.Status,
+		&q.PdfFile,
+		&q.SentAt,
+		&q.SentTo,
+		&q.CreatedBy,
+		&q.CreatedByName,
+		&q.CreatedAt,
+		&q.UpdatedAt,
+	)
+}
+
+// Create stores a new draft quotation with its items. Its number is the next one of the year of the issue
+// date, formatted with prefix (see utils.GenerateMemoNo); the totals must have been calculated.
+func (r *QuotationRepository) Create(ctx context.Context, q *models.Quotation, prefix string) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	seq, err := nextSequenceValue(ctx, tx, "quotation", q.IssueDate.Format("2006"))
+	if err != nil {
+		return err
+	}
+	q.QuotationNo = utils.GenerateMemoNo(prefix, q.IssueDate, seq)
+	q.Status = models.QuotationStatusDraft
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO quotations (quotation_no, rfq_id, inquiry_id, customer_name, customer_company, customer_email,
+			customer_mobile, customer_address, subject, reference, issue_date, valid_until, currency, subtotal,
+			discount_percent, discount, vat_rate, vat, total, notes, terms, status, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17, $18, $19, $20, $21, $22, $23)
+		RETURNING id, created_at, updated_at
+	`, q.QuotationNo, q.RfqID, q.InquiryID, q.CustomerName, q.CustomerCompany, q.CustomerEmail,
+		q.CustomerMobile, q.CustomerAddress, q.Subject, q.Reference, q.IssueDate, q.ValidUntil, q.Currency, q.Subtotal,
+		q.DiscountPercent, q.Discount, q.VatRate, q.Vat, q.Total, q.Notes, q.Terms, q.Status, q.CreatedBy).
+		Scan(&q.ID, &q.CreatedAt, &q.UpdatedAt)
+	if err != nil {
+		return quotationWriteError("insert", err)
+	}
+
+	if err := insertQuotationItems(ctx, tx, q); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// insertQuotationItems stores the items of q in their order inside tx.
+func insertQuotationItems(ctx context.Context, tx dbtx, q *models.Quotation) error {
+	for i := range q.Items {
+		item := &q.Items[i]
+		item.QuotationID = q.ID
+		err := tx.QueryRow(ctx, `
+			INSERT INTO quotation_items (quotation_id, product_id, product_code, description, quantity, unit,
+				unit_price, amount, sort_order)
+			VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+			RETURNING id
+		`, q.ID, item.ProductID, item.ProductCode, item.Description, item.Quantity, item.Unit,
+			item.UnitPrice, item.Amount, i).Scan(&item.ID)
+		if err != nil {
+			return quotationWriteError("insert item of", err)
+		}
+	}
+	return nil
+}
+
+// Update saves a draft quotation and replaces its items; the number is kept.
+// It returns ErrQuotationNotDraft when the quotation was sent in the meantime.
+func (r *QuotationRepository) Update(ctx context.Context, q *models.Quotation) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var status string
+	err = tx.QueryRow(ctx, `SELECT status FROM quotations WHERE id = $1 FOR UPDATE`, q.ID).Scan(&status)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("no quotation found")
+		}
+		return fmt.Errorf("failed to load quotation: %w", err)
+	}
+	if status != models.QuotationStatusDraft {
+		return ErrQuotationNotDraft
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE quotations
+		SET rfq_id = $1, inquiry_id = $2, customer_name = $3, customer_company = $4, customer_email = $5,
+		    customer_mobile = $6, customer_address = $7, subject = $8, reference = $9, issue_date = $10,
+		    valid_until = $11, currency = $12, subtotal = $13, discount_percent = $14, discount = $15,
+		    vat_rate = $16, vat = $17, total = $18, notes = $19, terms = $20, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $21
+		RETURNING updated_at
+	`, q.RfqID, q.InquiryID, q.CustomerName, q.CustomerCompany, q.CustomerEmail,
+		q.CustomerMobile, q.CustomerAddress, q.Subject, q.Reference, q.IssueDate,
+		q.ValidUntil, q.Currency, q.Subtotal, q.DiscountPercent, q.Discount,
+		q.VatRate, q.Vat, q.Total, q.Notes, q.Terms, q.ID).Scan(&q.UpdatedAt)
+	if err != nil {
+		return quotationWriteError("update", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM quotation_items WHERE quotation_id = $1`, q.ID); err != nil {
+		return fmt.Errorf("failed to replace quotation items: %w", err)
+	}
+	if err := insertQuotationItems(ctx, tx, q); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SetPdfFile records the name of the rendered PDF under data/quotations.
+func (r *QuotationRepository) SetPdfFile(ctx context.Context, id int64, pdfFile string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	if _, err := r.DB.Exec(ctx, `UPDATE quotations SET pdf_file = $1 WHERE id = $2`, pdfFile, id); err != nil {
+		return fmt.Errorf("failed to set quotation pdf: %w", err)
+	}
+	return nil
+}
+
+// MarkSent records that the quotation was emailed to the address to; it can no longer be edited.
+func (r *QuotationRepository) MarkSent(ctx context.Context, q *models.Quotation, to string) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	err := r.DB.QueryRow(ctx, `
+		UPDATE quotations
+		SET status = $1, sent_at = CURRENT_TIMESTAMP, sent_to = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+		RETURNING status, sent_at, sent_to, updated_at
+	`, models.QuotationStatusSent, to, q.ID).Scan(&q.Status, &q.SentAt, &q.SentTo, &q.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("no quotation found")
+		}
+		return fmt.Errorf("failed to mark quotation sent: %w", err)
+	}
+	return nil
+}
+
+// GetByID returns a single quotation with its items.
+func (r *QuotationRepository) GetByID(ctx context.Context, id int64) (*models.Quotation, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var q models.Quotation
+	err := scanQuotation(r.DB.QueryRow(ctx, `SELECT `+quotationColumns+quotationFrom+` WHERE q.id = $1`, id), &q)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("no quotation found")
+		}
+		return nil, fmt.Errorf("failed to get quotation: %w", err)
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT id, quotation_id, product_id, product_code, description, quantity, unit, unit_price, amount
+		FROM quotation_items
+		WHERE quotation_id = $1
+		ORDER BY sort_order, id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query quotation items: %w", err)
+	}
+	defer rows.Close()
+
+	q.Items = []models.QuotationItem{}
+	for rows.Next() {
+		var item models.QuotationItem
+		if err := rows.Scan(&item.ID, &item.QuotationID, &item.ProductID, &item.ProductCode, &item.Description,
+			&item.Quantity, &item.Unit, &item.UnitPrice, &item.Amount); err != nil {
+			return nil, fmt.Errorf("failed to scan quotation item row: %w", err)
+		}
+		q.Items = append(q.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating quotation item rows: %w", err)
+	}
+
+	return &q, nil
+}
+
+// GetAll retrieves one page of quotations matching the filter, newest first, and the total number of matches.
+// Items are not loaded.
+func (r *QuotationRepository) GetAll(ctx context.Context, f models.QuotationFilter) ([]models.Quotation, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	where := " WHERE 1=1"
+	args := []any{}
+	argIdx := 1
+
+	if f.Status != "" {
+		where += fmt.Sprintf(" AND q.status = $%d", argIdx)
+		args = append(args, f.Status)
+		argIdx++
+	}
+	if f.RfqID != nil {
+		where += fmt.Sprintf(" AND q.rfq_id = $%d", argIdx)
+		args = append(args, *f.RfqID)
+		argIdx++
+	}
+	if f.InquiryID != nil {
+		where += fmt.Sprintf(" AND q.inquiry_id = $%d", argIdx)
+		args = append(args, *f.InquiryID)
+		argIdx++
+	}
+	if f.Search != "" {
+		where += fmt.Sprintf(` AND (q.quotation_no ILIKE $%[1]d OR q.customer_name ILIKE $%[1]d
+			OR q.customer_company ILIKE $%[1]d OR q.customer_email ILIKE $%[1]d OR q.subject ILIKE $%[1]d
+			OR q.reference ILIKE $%[1]d)`, argIdx)
+		args = append(args, "%"+f.Search+"%")
+		argIdx++
+	}
+
+	var total int
+	if err := r.DB.QueryRow(ctx, `SELECT COUNT(*) FROM quotations q`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count quotations: %w", err)
+	}
+
+	query := `SELECT ` + quotationColumns + quotationFrom + where +
+		fmt.Sprintf(" ORDER BY q.created_at DESC, q.id DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	args = append(args, f.PageLength, (f.PageIndex-1)*f.PageLength)
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query quotations: %w", err)
+	}
+	defer rows.Close()
+
+	quotations := []models.Quotation{}
+	for rows.Next() {
+		var q models.Quotation
+		if err := scanQuotation(rows, &q); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan quotation row: %w", err)
+		}
+		quotations = append(quotations, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating quotation rows: %w", err)
+	}
+
+	return quotations, total, nil
+}
+
+// Delete removes a draft quotation and returns the name of its PDF. Its number is not reused.
+func (r *QuotationRepository) Delete(ctx context.Context, id int64) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var pdfFile string
+	err := r.DB.QueryRow(ctx, `DELETE FROM quotations WHERE id = $1 AND status = $2 RETURNING pdf_file`,
+		id, models.QuotationStatusDraft).Scan(&pdfFile)
+	if err == nil {
+		return pdfFile, nil
+	}
+	if !errors.Is(err, pgx.ErrNoRows) {
+		return "", fmt.Errorf("failed to delete quotation: %w", err)
+	}
+
+	var exists bool
+	if err := r.DB.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM quotations WHERE id = $1)`, id).Scan(&exists); err != nil {
+		return "", fmt.Errorf("failed to check quotation: %w", err)
+	}
+	if exists {
+		return "", ErrQuotationNotDraft
+	}
+	return "", errors.New("no quotation found")
+}
@@ -0,0 +1,123 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// ErrRefreshTokenReused is returned by Rotate when the token was already rotated or revoked.
+var ErrRefreshTokenReused = errors.New("refresh token has already been used")
+
+// RefreshTokenRepository holds the database connection pool.
+type RefreshTokenRepository struct {
+	DB *pgxpool.Pool
+}
+
+// newRefreshTokenRepository creates a new instance of the repository.
+func newRefreshTokenRepository(db *pgxpool.Pool) *RefreshTokenRepository {
+	return &RefreshTokenRepository{DB: db}
+}
+
+// Create stores a new refresh token.
+func (r *RefreshTokenRepository) Create(ctx context.Context, t *models.RefreshToken) error {
+	sql := `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, user_agent, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`
+
+	err := r.DB.QueryRow(ctx, sql, t.UserID, t.FamilyID, t.TokenHash, t.ExpiresAt, t.UserAgent, t.IPAddress).
+		Scan(&t.ID, &t.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	return nil
+}
+
+// GetByHash retrieves a refresh token by the hash of its plain value.
+func (r *RefreshTokenRepository) GetByHash(ctx context.Context, tokenHash string) (*models.RefreshToken, error) {
+	sql := `
+		SELECT id, user_id, family_id, token_hash, expires_at, revoked_at, replaced_by, user_agent, ip_address, created_at
+		FROM refresh_tokens
+		WHERE token_hash = $1
+	`
+
+	var t models.RefreshToken
+	err := r.DB.QueryRow(ctx, sql, tokenHash).Scan(
+		&t.ID,
+		&t.UserID,
+		&t.FamilyID,
+		&t.TokenHash,
+		&t.ExpiresAt,
+		&t.RevokedAt,
+		&t.ReplacedBy,
+		&t.UserAgent,
+		&t.IPAddress,
+		&t.CreatedAt,
+	)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("refresh token not found")
+		}
+		return nil, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	return &t, nil
+}
+
+// Rotate revokes the token identified by oldID and stores next as its replacement in one transaction.
+// It returns ErrRefreshTokenReused if oldID was already revoked (e.g. a concurrent refresh won the race).
+func (r *RefreshTokenRepository) Rotate(ctx context.Context, oldID int64, next *models.RefreshToken) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	cmdTag, err := tx.Exec(ctx, `
+		UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND revoked_at IS NULL
+	`, oldID)
+	if err != nil {
+		return fmt.Errorf("failed to revoke refresh token: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return ErrRefreshTokenReused
+	}
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO refresh_tokens (user_id, family_id, token_hash, expires_at, user_agent, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at
+	`, next.UserID, next.FamilyID, next.TokenHash, next.ExpiresAt, next.UserAgent, next.IPAddress).
+		Scan(&next.ID, &next.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create refresh token: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `UPDATE refresh_tokens SET replaced_by = $1 WHERE id = $2`, next.ID, oldID); err != nil {
+		return fmt.Errorf("failed to link refresh token: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RevokeFamily revokes every active token that descends from the same sign-in.
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	sql := `
+		UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE family_id = $1 AND revoked_at IS NULL
+	`
+
+	if _, err := r.DB.Exec(ctx, sql, familyID); err != nil {
+		return fmt.Errorf("failed to revoke refresh token family: %w", err)
+	}
+
+	return nil
+}
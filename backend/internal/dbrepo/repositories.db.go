@@ -1,27 +1,70 @@
 package dbrepo
 
 import (
+	"context"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
 
+// dbtx is satisfied by both *pgxpool.Pool and pgx.Tx, so helpers can run inside or outside a transaction
+type dbtx interface {
+	Exec(ctx context.Context, sql string, args ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, args ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, args ...any) pgx.Row
+}
+
 // DBRepository contains all individual repositories
 type DBRepository struct {
-	UserRepo    *UserRepo
-	InquiryRepo *InquiryRepository
-	MemberRepo  *MemberRepository
-	TeamRepo  *TeamRepository
-	GalleryRepo  *GalleryRepository
-	ClientRepo  *ClientRepository
+	UserRepo              *UserRepo
+	InquiryRepo           *InquiryRepository
+	MemberRepo            *MemberRepository
+	TeamRepo              *TeamRepository
+	GalleryRepo           *GalleryRepository
+	ClientRepo            *ClientRepository
+	RefreshTokenRepo      *RefreshTokenRepository
+	SessionRepo           *SessionRepository
+	LoginAttemptRepo      *LoginAttemptRepository
+	PasswordResetRepo     *PasswordResetRepository
+	EmailMessageRepo      *EmailMessageRepository
+	EmailTemplateRepo     *EmailTemplateRepository
+	InquiryMessageRepo    *InquiryMessageRepository
+	InquiryWorkflowRepo   *InquiryWorkflowRepository
+	InquiryAttachmentRepo *InquiryAttachmentRepository
+	WebhookRepo           *WebhookRepository
+	ProductRepo           *ProductRepository
+	ProductCategoryRepo   *ProductCategoryRepository
+	BrandRepo             *BrandRepository
+	ProductDatasheetRepo  *ProductDatasheetRepository
+	RfqRepo               *RfqRepository
+	QuotationRepo         *QuotationRepository
 }
 
 // NewDBRepository initializes all repositories with a shared connection pool
 func NewDBRepository(db *pgxpool.Pool) *DBRepository {
 	return &DBRepository{
-		UserRepo:    newUserRepo(db),
-		InquiryRepo: newInquiryRepository(db),
-		MemberRepo:  newMemberRepository(db),
-		TeamRepo:  newTeamRepository(db),
-		GalleryRepo:  newGalleryRepository(db),
-		ClientRepo:  newClientRepository(db),
+		UserRepo:              newUserRepo(db),
+		InquiryRepo:           newInquiryRepository(db),
+		MemberRepo:            newMemberRepository(db),
+		TeamRepo:              newTeamRepository(db),
+		GalleryRepo:           newGalleryRepository(db),
+		ClientRepo:            newClientRepository(db),
+		RefreshTokenRepo:      newRefreshTokenRepository(db),
+		SessionRepo:           newSessionRepository(db),
+		LoginAttemptRepo:      newLoginAttemptRepository(db),
+		PasswordResetRepo:     newPasswordResetRepository(db),
+		EmailMessageRepo:      newEmailMessageRepository(db),
+		EmailTemplateRepo:     newEmailTemplateRepository(db),
+		InquiryMessageRepo:    newInquiryMessageRepository(db),
+		InquiryWorkflowRepo:   newInquiryWorkflowRepository(db),
+		InquiryAttachmentRepo: newInquiryAttachmentRepository(db),
+		WebhookRepo:           newWebhookRepository(db),
+		ProductRepo:           newProductRepository(db),
+		ProductCategoryRepo:   newProductCategoryRepository(db),
+		BrandRepo:             newBrandRepository(db),
+		ProductDatasheetRepo:  newProductDatasheetRepository(db),
+		RfqRepo:               newRfqRepository(db),
+		QuotationRepo:         newQuotationRepository(db),
 	}
 }
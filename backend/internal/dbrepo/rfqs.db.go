@@ -0,0 +1,330 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+var (
+	// ErrRfqProductUnavailable is returned by Create when an item refers to a product that does not exist
+	// or is not active.
+	ErrRfqProductUnavailable = errors.New("one of the selected products is no longer available")
+	// ErrRfqStatusConflict is returned by UpdateStatus when the status was changed by someone else in the meantime.
+	ErrRfqStatusConflict = errors.New("the request status was changed by someone else, reload and try again")
+)
+
+// RfqRepository stores the requests for quotation with their line items and status history.
+type RfqRepository struct {
+	DB *pgxpool.Pool
+}
+
+// newRfqRepository creates a new instance of the repository.
+func newRfqRepository(db *pgxpool.Pool) *RfqRepository {
+	return &RfqRepository{DB: db}
+}
+
+// Create inserts a new request with its items and starts the status history. The code and name of each
+// item's product are copied from the catalogue; only active products can be requested.
+func (r *RfqRepository) Create(ctx context.Context, q *models.Rfq) error {
+	ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, `
+		INSERT INTO rfqs (name, company, email, mobile, message, status, is_spam, spam_score, spam_reasons, ip_address)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING id, created_at, updated_at
+	`, q.Name, q.Company, q.Email, q.Mobile, q.Message, q.Status, q.IsSpam, q.SpamScore, q.SpamReasons, q.IPAddress).
+		Scan(&q.ID, &q.CreatedAt, &q.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create rfq: %w", err)
+	}
+
+	for i := range q.Items {
+		item := &q.Items[i]
+		item.RfqID = q.ID
+		err := tx.QueryRow(ctx, `
+			INSERT INTO rfq_items (rfq_id, product_id, product_code, product_name, quantity, note, sort_order)
+			SELECT $1, p.id, p.code, p.name, $3, $4, $5
+			FROM products p
+			WHERE p.id = $2 AND p.is_active
+			RETURNING id, product_code, product_name
+		`, q.ID, item.ProductID, item.Quantity, item.Note, i).Scan(&item.ID, &item.ProductCode, &item.ProductName)
+		if err != nil {
+			if errors.Is(err, pgx.ErrNoRows) {
+				return ErrRfqProductUnavailable
+			}
+			return fmt.Errorf("failed to insert rfq item: %w", err)
+		}
+	}
+	q.ItemCount = len(q.Items)
+
+	change := &models.RfqStatusChange{RfqID: q.ID, To: q.Status}
+	if q.IsSpam {
+		change.Comment = "Flagged as spam: " + q.SpamReasons
+	}
+	if err := insertRfqStatusChange(ctx, tx, change); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// rfqColumns are the columns read by scanRfq, in order; the table alias is q.
+const rfqColumns = `
+	q.id, q.name, q.company, q.email, q.mobile, q.message, q.status, q.is_spam, q.spam_score, q.spam_reasons,
+	q.ip_address, (SELECT COUNT(*) FROM rfq_items it WHERE it.rfq_id = q.id), q.created_at, q.updated_at`
+
+func scanRfq(row pgx.Row, q *models.Rfq) error {
+	return row.Scan(
+		&q.ID,
+		&q.Name,
+		&q.Company,
+		&q.Email,
+		&q.Mobile,
+		&q.Message,
+		&q.Status,
+		&q.IsSpam,
+		&q.SpamScore,
+		&q.SpamReasons,
+		&q.IPAddress,
+		&q.ItemCount,
+		&q.CreatedAt,
+		&q.UpdatedAt,
+	)
+}
+
+// GetByID returns a single request with its items.
+func (r *RfqRepository) GetByID(ctx context.Context, id int64) (*models.Rfq, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	var q models.Rfq
+	err := scanRfq(r.DB.QueryRow(ctx, `SELECT `+rfqColumns+` FROM rfqs q WHERE q.id = $1`, id), &q)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("no rfq found")
+		}
+		return nil, fmt.Errorf("failed to get rfq: %w", err)
+	}
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT id, rfq_id, product_id, product_code, product_name, quantity, note
+		FROM rfq_items
+		WHERE rfq_id = $1
+		ORDER BY sort_order, id
+	`, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rfq items: %w", err)
+	}
+	defer rows.Close()
+
+	q.Items = []models.RfqItem{}
+	for rows.Next() {
+		var item models.RfqItem
+		if err := rows.Scan(&item.ID, &item.RfqID, &item.ProductID, &item.ProductCode, &item.ProductName,
+			&item.Quantity, &item.Note); err != nil {
+			return nil, fmt.Errorf("failed to scan rfq item row: %w", err)
+		}
+		q.Items = append(q.Items, item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating rfq item rows: %w", err)
+	}
+
+	return &q, nil
+}
+
+// GetAll retrieves one page of requests matching the filter, newest first, and the total number of matches.
+// Items are not loaded; ItemCount is set.
+func (r *RfqRepository) GetAll(ctx context.Context, f models.RfqFilter) ([]models.Rfq, int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	where := " WHERE q.is_spam = $1"
+	args := []any{f.Spam}
+	argIdx := 2
+
+	if f.Status != "" {
+		where += fmt.Sprintf(" AND q.status = $%d", argIdx)
+		args = append(args, f.Status)
+		argIdx++
+	}
+	if f.Search != "" {
+		where += fmt.Sprintf(` AND (q.name ILIKE $%[1]d OR q.company ILIKE $%[1]d OR q.email ILIKE $%[1]d
+			OR q.mobile ILIKE $%[1]d OR q.message ILIKE $%[1]d
+			OR EXISTS (SELECT 1 FROM rfq_items it WHERE it.rfq_id = q.id
+			           AND (it.product_code ILIKE $%[1]d OR it.product_name ILIKE $%[1]d)))`, argIdx)
+		args = append(args, "%"+f.Search+"%")
+		argIdx++
+	}
+
+	var total int
+	if err := r.DB.QueryRow(ctx, `SELECT COUNT(*) FROM rfqs q`+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count rfqs: %w", err)
+	}
+
+	query := `SELECT ` + rfqColumns + ` FROM rfqs q` + where +
+		fmt.Sprintf(" ORDER BY q.created_at DESC, q.id DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	args = append(args, f.PageLength, (f.PageIndex-1)*f.PageLength)
+
+	rows, err := r.DB.Query(ctx, query, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query rfqs: %w", err)
+	}
+	defer rows.Close()
+
+	rfqs := []models.Rfq{}
+	for rows.Next() {
+		var q models.Rfq
+		if err := scanRfq(rows, &q); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan rfq row: %w", err)
+		}
+		rfqs = append(rfqs, q)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error iterating rfq rows: %w", err)
+	}
+
+	return rfqs, total, nil
+}
+
+// GetStatusCounts returns the number of requests in each status, not counting spam.
+func (r *RfqRepository) GetStatusCounts(ctx context.Context) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.Query(ctx, `SELECT status, COUNT(*) FROM rfqs WHERE NOT is_spam GROUP BY status`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rfq status counts: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[string]int)
+	for _, s := range models.RfqStatuses {
+		counts[s] = 0
+	}
+	for rows.Next() {
+		var status string
+		var count int
+		if err := rows.Scan(&status, &count); err != nil {
+			return nil, fmt.Errorf("failed to scan count row: %w", err)
+		}
+		counts[status] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// UpdateStatus moves the request from change.From to change.To and adds the change to the history.
+// Reopening a request clears its spam flag. q is reloaded with the new status.
+func (r *RfqRepository) UpdateStatus(ctx context.Context, q *models.Rfq, change *models.RfqStatusChange) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	var current string
+	err = tx.QueryRow(ctx, `SELECT status FROM rfqs WHERE id = $1 FOR UPDATE`, q.ID).Scan(&current)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return errors.New("no rfq found")
+		}
+		return fmt.Errorf("failed to load rfq: %w", err)
+	}
+	if change.From != current {
+		return ErrRfqStatusConflict
+	}
+
+	change.RfqID = q.ID
+	if err := insertRfqStatusChange(ctx, tx, change); err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE rfqs
+		SET status = $1, is_spam = is_spam AND $1 <> 'NEW', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+		RETURNING status, is_spam, updated_at
+	`, change.To, q.ID).Scan(&q.Status, &q.IsSpam, &q.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to update rfq status: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// insertRfqStatusChange adds an entry to the RFQ status history inside tx.
+func insertRfqStatusChange(ctx context.Context, tx dbtx, c *models.RfqStatusChange) error {
+	err := tx.QueryRow(ctx, `
+		INSERT INTO rfq_status_history (rfq_id, from_status, to_status, changed_by, comment)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`, c.RfqID, c.From, c.To, c.ChangedBy, c.Comment).Scan(&c.ID, &c.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to record rfq status change: %w", err)
+	}
+	return nil
+}
+
+// GetStatusHistory returns the status changes of a request in chronological order.
+func (r *RfqRepository) GetStatusHistory(ctx context.Context, rfqID int64) ([]models.RfqStatusChange, error) {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	rows, err := r.DB.Query(ctx, `
+		SELECT h.id, h.rfq_id, h.from_status, h.to_status, h.changed_by, COALESCE(u.name, ''),
+		       h.comment, h.created_at
+		FROM rfq_status_history h
+		LEFT JOIN users u ON u.id = h.changed_by
+		WHERE h.rfq_id = $1
+		ORDER BY h.created_at, h.id
+	`, rfqID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rfq status history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []models.RfqStatusChange{}
+	for rows.Next() {
+		var c models.RfqStatusChange
+		if err := rows.Scan(&c.ID, &c.RfqID, &c.From, &c.To, &c.ChangedBy, &c.ChangedByName,
+			&c.Comment, &c.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan rfq status history row: %w", err)
+		}
+		history = append(history, c)
+	}
+
+	return history, rows.Err()
+}
+
+// Delete removes a request with its items and history.
+func (r *RfqRepository) Delete(ctx context.Context, id int64) error {
+	ctx, cancel := context.WithTimeout(ctx, 3*time.Second)
+	defer cancel()
+
+	cmdTag, err := r.DB.Exec(ctx, `DELETE FROM rfqs WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete rfq: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return errors.New("no rfq found")
+	}
+
+	return nil
+}
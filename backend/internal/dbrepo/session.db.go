@@ -0,0 +1,93 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// SessionRepository handles access token revocation (logout and "revoke all sessions").
+type SessionRepository struct {
+	DB *pgxpool.Pool
+}
+
+// newSessionRepository creates a new instance of the repository.
+func newSessionRepository(db *pgxpool.Pool) *SessionRepository {
+	return &SessionRepository{DB: db}
+}
+
+// GetState returns the user's status, current token version and whether the given JWT ID was revoked.
+func (r *SessionRepository) GetState(ctx context.Context, userID int64, jti string) (*models.SessionState, error) {
+	sql := `
+		SELECT u.status, u.token_version,
+		       EXISTS (SELECT 1 FROM revoked_tokens rt WHERE rt.jti = $2)
+		FROM users u
+		WHERE u.id = $1
+	`
+
+	var s models.SessionState
+	err := r.DB.QueryRow(ctx, sql, userID, jti).Scan(&s.Status, &s.TokenVersion, &s.TokenRevoked)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("no user found")
+		}
+		return nil, fmt.Errorf("failed to get session state: %w", err)
+	}
+
+	return &s, nil
+}
+
+// RevokeAccessToken blacklists a single access token until it expires.
+// Rows of tokens that have already expired are purged on the way.
+func (r *SessionRepository) RevokeAccessToken(ctx context.Context, jti string, userID int64, expiresAt time.Time) error {
+	sql := `
+		INSERT INTO revoked_tokens (jti, user_id, expires_at)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (jti) DO NOTHING
+	`
+
+	if _, err := r.DB.Exec(ctx, sql, jti, userID, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+
+	if _, err := r.DB.Exec(ctx, `DELETE FROM revoked_tokens WHERE expires_at < CURRENT_TIMESTAMP`); err != nil {
+		return fmt.Errorf("failed to purge revoked tokens: %w", err)
+	}
+
+	return nil
+}
+
+// RevokeAllForUser invalidates every access and refresh token of the user by bumping the token version
+// and revoking all active refresh tokens.
+func (r *SessionRepository) RevokeAllForUser(ctx context.Context, userID int64) error {
+	tx, err := r.DB.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	cmdTag, err := tx.Exec(ctx, `
+		UPDATE users SET token_version = token_version + 1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to bump token version: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no user found with id: %d", userID)
+	}
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE refresh_tokens SET revoked_at = CURRENT_TIMESTAMP
+		WHERE user_id = $1 AND revoked_at IS NULL
+	`, userID); err != nil {
+		return fmt.Errorf("failed to revoke refresh tokens: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,141 @@
+package dbrepo
+
+import (
+	"context"
+	"fmt"
+)
+
+// ============================== Two-Factor (TOTP) ==============================
+
+// SetTOTPSecret stores a new, not yet enabled, TOTP secret for the user (enrollment step).
+func (r *UserRepo) SetTOTPSecret(ctx context.Context, id int64, secret string) error {
+	query := `
+		UPDATE users
+		SET totp_secret = $1, totp_enabled = FALSE, totp_last_step = 0, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`
+	_, err := r.db.Exec(ctx, query, secret, id)
+	if err != nil {
+		return fmt.Errorf("failed to set totp secret: %w", err)
+	}
+	return nil
+}
+
+// EnableTOTP switches two-factor on and replaces the user's recovery codes with the given hashes.
+func (r *UserRepo) EnableTOTP(ctx context.Context, id int64, step int64, codeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE users
+		SET totp_enabled = TRUE, totp_last_step = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, step, id); err != nil {
+		return fmt.Errorf("failed to enable totp: %w", err)
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, id, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// DisableTOTP switches two-factor off and removes the secret and recovery codes.
+func (r *UserRepo) DisableTOTP(ctx context.Context, id int64) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if _, err := tx.Exec(ctx, `
+		UPDATE users
+		SET totp_enabled = FALSE, totp_secret = '', totp_last_step = 0, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1
+	`, id); err != nil {
+		return fmt.Errorf("failed to disable totp: %w", err)
+	}
+
+	if err := replaceRecoveryCodes(ctx, tx, id, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// ConsumeTOTPStep records step as the last accepted time step.
+// It returns false if the step (or a later one) was already used, which rejects a replayed code.
+func (r *UserRepo) ConsumeTOTPStep(ctx context.Context, id int64, step int64) (bool, error) {
+	query := `
+		UPDATE users SET totp_last_step = $1
+		WHERE id = $2 AND totp_last_step < $1
+	`
+	cmdTag, err := r.db.Exec(ctx, query, step, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to record totp step: %w", err)
+	}
+	return cmdTag.RowsAffected() == 1, nil
+}
+
+// ReplaceRecoveryCodes discards the user's recovery codes and stores the given hashes instead.
+func (r *UserRepo) ReplaceRecoveryCodes(ctx context.Context, id int64, codeHashes []string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	if err := replaceRecoveryCodes(ctx, tx, id, codeHashes); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// UseRecoveryCode marks an unused recovery code as used. It returns false if no such code exists.
+func (r *UserRepo) UseRecoveryCode(ctx context.Context, id int64, codeHash string) (bool, error) {
+	query := `
+		UPDATE user_recovery_codes SET used_at = CURRENT_TIMESTAMP
+		WHERE id = (
+			SELECT id FROM user_recovery_codes
+			WHERE user_id = $1 AND code_hash = $2 AND used_at IS NULL
+			LIMIT 1
+		)
+	`
+	cmdTag, err := r.db.Exec(ctx, query, id, codeHash)
+	if err != nil {
+		return false, fmt.Errorf("failed to use recovery code: %w", err)
+	}
+	return cmdTag.RowsAffected() == 1, nil
+}
+
+// CountRecoveryCodes returns how many unused recovery codes the user has left.
+func (r *UserRepo) CountRecoveryCodes(ctx context.Context, id int64) (int, error) {
+	var count int
+	err := r.db.QueryRow(ctx, `
+		SELECT COUNT(*) FROM user_recovery_codes WHERE user_id = $1 AND used_at IS NULL
+	`, id).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count recovery codes: %w", err)
+	}
+	return count, nil
+}
+
+// replaceRecoveryCodes deletes the user's codes and inserts the given hashes inside tx.
+func replaceRecoveryCodes(ctx context.Context, tx dbtx, id int64, codeHashes []string) error {
+	if _, err := tx.Exec(ctx, `DELETE FROM user_recovery_codes WHERE user_id = $1`, id); err != nil {
+		return fmt.Errorf("failed to delete recovery codes: %w", err)
+	}
+	for _, h := range codeHashes {
+		if _, err := tx.Exec(ctx, `
+			INSERT INTO user_recovery_codes (user_id, code_hash) VALUES ($1, $2)
+		`, id, h); err != nil {
+			return fmt.Errorf("failed to insert recovery code: %w", err)
+		}
+	}
+	return nil
+}
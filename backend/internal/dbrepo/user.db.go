@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
@@ -57,14 +58,17 @@ func (r *UserRepo) CreateUser(ctx context.Context, e *models.User) error {
 // GetUserByID fetches a user by ID
 func (r *UserRepo) GetUserByID(ctx context.Context, id int64) (*models.User, error) {
 	query := `
-		SELECT id, name, role, status, mobile, email, password, address, avatar_link, joining_date, created_at, updated_at
+		SELECT id, name, role, status, mobile, email, password, address, avatar_link, joining_date, token_version,
+		       totp_enabled, totp_secret, totp_last_step, failed_login_count, locked_until,
+		       created_at, updated_at
 		FROM users WHERE id = $1
 	`
 	e := &models.User{}
 	err := r.db.QueryRow(ctx, query, id).Scan(
 		&e.ID, &e.Name, &e.Role, &e.Status, &e.Mobile, &e.Email,
 		&e.Password, &e.Address, &e.AvatarLink, &e.JoiningDate,
-		&e.CreatedAt, &e.UpdatedAt,
+		&e.TokenVersion, &e.TOTPEnabled, &e.TOTPSecret, &e.TOTPLastStep,
+		&e.FailedLogins, &e.LockedUntil, &e.CreatedAt, &e.UpdatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -78,7 +82,9 @@ func (r *UserRepo) GetUserByID(ctx context.Context, id int64) (*models.User, err
 // GetUserByUsername fetches a user by mobile or email
 func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (*models.User, error) {
 	query := `
-		SELECT id, name, role, status, mobile, email, password, address, avatar_link, joining_date, created_at, updated_at
+		SELECT id, name, role, status, mobile, email, password, address, avatar_link, joining_date, token_version,
+		       totp_enabled, totp_secret, totp_last_step, failed_login_count, locked_until,
+		       created_at, updated_at
 		FROM users
 		WHERE mobile = $1 OR email = $1
 		LIMIT 1
@@ -87,7 +93,8 @@ func (r *UserRepo) GetUserByUsername(ctx context.Context, username string) (*mod
 	err := r.db.QueryRow(ctx, query, username).Scan(
 		&e.ID, &e.Name, &e.Role, &e.Status, &e.Mobile, &e.Email,
 		&e.Password, &e.Address, &e.AvatarLink, &e.JoiningDate,
-		&e.CreatedAt, &e.UpdatedAt,
+		&e.TokenVersion, &e.TOTPEnabled, &e.TOTPSecret, &e.TOTPLastStep,
+		&e.FailedLogins, &e.LockedUntil, &e.CreatedAt, &e.UpdatedAt,
 	)
 	if err != nil {
 		if err == pgx.ErrNoRows {
@@ -144,6 +151,7 @@ func (r *UserRepo) UpdateUser(ctx context.Context, e *models.User) error {
 
 	return nil
 }
+
 // UpdatePassword updates only the password hash for a specific user ID.
 func (r *UserRepo) UpdatePassword(ctx context.Context, userID int64, hashedPassword string) error {
 	const sqlUpdatePassword = `
@@ -152,15 +160,16 @@ func (r *UserRepo) UpdatePassword(ctx context.Context, userID int64, hashedPassw
             updated_at = CURRENT_TIMESTAMP
         WHERE id = $1
     `
-	
-	_, err :=  r.db.Exec(ctx, sqlUpdatePassword, userID, hashedPassword)
-	
+
+	_, err := r.db.Exec(ctx, sqlUpdatePassword, userID, hashedPassword)
+
 	if err != nil {
 		return fmt.Errorf("failed to update password: %w", err)
 	}
 
 	return nil
 }
+
 // UpdateUserAvatarLink updates only the user's avatar link
 func (r *UserRepo) UpdateUserAvatarLink(ctx context.Context, id int64, avatarLink string) error {
 	query := `
@@ -183,10 +192,25 @@ func (r *UserRepo) UpdateUserStatus(ctx context.Context, id int64, role, status
 	return err
 }
 
-// PaginatedUserList returns paginated list of users with optional filters
-func (r *UserRepo) PaginatedUserList(ctx context.Context, page, limit int, role, status, sortBy, sortOrder string) ([]*models.User, int, error) {
+// userSortColumns whitelists the columns PaginatedUserList can sort by
+var userSortColumns = map[string]string{
+	"id":           "id",
+	"name":         "name",
+	"role":         "role",
+	"status":       "status",
+	"email":        "email",
+	"mobile":       "mobile",
+	"joining_date": "joining_date",
+	"created_at":   "created_at",
+	"updated_at":   "updated_at",
+}
+
+// PaginatedUserList returns paginated list of users with optional filters.
+// search matches name, email or mobile (case-insensitive).
+func (r *UserRepo) PaginatedUserList(ctx context.Context, page, limit int, role, status, search, sortBy, sortOrder string) ([]*models.User, int, error) {
 	query := `
-		SELECT id, name, role, status, mobile, email, address, avatar_link, joining_date, created_at, updated_at
+		SELECT id, name, role, status, mobile, email, address, avatar_link, joining_date, totp_enabled,
+		       failed_login_count, locked_until, created_at, updated_at
 		FROM users
 		WHERE 1=1
 	`
@@ -211,14 +235,25 @@ func (r *UserRepo) PaginatedUserList(ctx context.Context, page, limit int, role,
 		argIdx++
 	}
 
-	// Sorting
-	if sortBy == "" {
-		sortBy = "created_at"
+	if search != "" {
+		clause := fmt.Sprintf(" AND (name ILIKE $%d OR email ILIKE $%d OR mobile ILIKE $%d)", argIdx, argIdx, argIdx)
+		query += clause
+		countQuery += clause
+		args = append(args, "%"+search+"%")
+		countArgs = append(countArgs, "%"+search+"%")
+		argIdx++
+	}
+
+	// Sorting (column names cannot be bound as parameters, so only whitelisted columns are allowed)
+	sortColumn, ok := userSortColumns[sortBy]
+	if !ok {
+		sortColumn = "created_at"
 	}
+	sortOrder = strings.ToUpper(sortOrder)
 	if sortOrder != "ASC" && sortOrder != "DESC" {
 		sortOrder = "DESC"
 	}
-	query += fmt.Sprintf(" ORDER BY %s %s", sortBy, sortOrder)
+	query += fmt.Sprintf(" ORDER BY %s %s, id %s", sortColumn, sortOrder, sortOrder)
 
 	// Pagination
 	if page > 0 && limit > 0 {
@@ -244,7 +279,8 @@ func (r *UserRepo) PaginatedUserList(ctx context.Context, page, limit int, role,
 		var u models.User
 		if err := rows.Scan(
 			&u.ID, &u.Name, &u.Role, &u.Status, &u.Mobile, &u.Email,
-			&u.Address, &u.AvatarLink, &u.JoiningDate, &u.CreatedAt, &u.UpdatedAt,
+			&u.Address, &u.AvatarLink, &u.JoiningDate, &u.TOTPEnabled,
+			&u.FailedLogins, &u.LockedUntil, &u.CreatedAt, &u.UpdatedAt,
 		); err != nil {
 			return nil, 0, err
 		}
@@ -0,0 +1,334 @@
+package dbrepo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// webhookDeliveryRetention is how long finished deliveries are kept in the log
+const webhookDeliveryRetention = 30 * 24 * time.Hour
+
+// WebhookRepository stores webhook endpoints and the delivery log. It implements webhook.Store.
+type WebhookRepository struct {
+	DB *pgxpool.Pool
+}
+
+// newWebhookRepository creates a new instance of the repository.
+func newWebhookRepository(db *pgxpool.Pool) *WebhookRepository {
+	return &WebhookRepository{DB: db}
+}
+
+// ============================== Endpoints ==============================
+
+const webhookEndpointSelect = `
+	SELECT id, url, description, events, secret, active, created_by, created_at, updated_at
+	FROM webhook_endpoints
+`
+
+func scanWebhookEndpoint(row pgx.Row, e *models.WebhookEndpoint) error {
+	return row.Scan(&e.ID, &e.URL, &e.Description, &e.Events, &e.Secret, &e.Active, &e.CreatedBy, &e.CreatedAt, &e.UpdatedAt)
+}
+
+// CreateEndpoint inserts a new endpoint and sets its ID and timestamps.
+func (r *WebhookRepository) CreateEndpoint(ctx context.Context, e *models.WebhookEndpoint) error {
+	sql := `
+		INSERT INTO webhook_endpoints (url, description, events, secret, active, created_by)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id, created_at, updated_at
+	`
+	err := r.DB.QueryRow(ctx, sql, e.URL, e.Description, e.Events, e.Secret, e.Active, e.CreatedBy).
+		Scan(&e.ID, &e.CreatedAt, &e.UpdatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+// GetEndpoints returns every endpoint, oldest first.
+func (r *WebhookRepository) GetEndpoints(ctx context.Context) ([]models.WebhookEndpoint, error) {
+	rows, err := r.DB.Query(ctx, webhookEndpointSelect+` ORDER BY id`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query webhook endpoints: %w", err)
+	}
+	defer rows.Close()
+
+	endpoints := []models.WebhookEndpoint{}
+	for rows.Next() {
+		var e models.WebhookEndpoint
+		if err := scanWebhookEndpoint(rows, &e); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook endpoint: %w", err)
+		}
+		endpoints = append(endpoints, e)
+	}
+	return endpoints, rows.Err()
+}
+
+// GetEndpoint returns a single endpoint.
+func (r *WebhookRepository) GetEndpoint(ctx context.Context, id int64) (*models.WebhookEndpoint, error) {
+	var e models.WebhookEndpoint
+	if err := scanWebhookEndpoint(r.DB.QueryRow(ctx, webhookEndpointSelect+` WHERE id = $1`, id), &e); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("no webhook endpoint found")
+		}
+		return nil, fmt.Errorf("failed to get webhook endpoint: %w", err)
+	}
+	return &e, nil
+}
+
+// UpdateEndpoint saves the URL, description, events and active flag of an endpoint.
+func (r *WebhookRepository) UpdateEndpoint(ctx context.Context, e *models.WebhookEndpoint) error {
+	sql := `
+		UPDATE webhook_endpoints
+		SET url = $1, description = $2, events = $3, active = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5
+		RETURNING updated_at
+	`
+	if err := r.DB.QueryRow(ctx, sql, e.URL, e.Description, e.Events, e.Active, e.ID).Scan(&e.UpdatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("no webhook endpoint found with id: %d", e.ID)
+		}
+		return fmt.Errorf("failed to update webhook endpoint: %w", err)
+	}
+	return nil
+}
+
+// RotateSecret replaces the signing secret of an endpoint.
+func (r *WebhookRepository) RotateSecret(ctx context.Context, id int64, secret string) error {
+	cmdTag, err := r.DB.Exec(ctx, `
+		UPDATE webhook_endpoints SET secret = $1, updated_at = CURRENT_TIMESTAMP WHERE id = $2
+	`, secret, id)
+	if err != nil {
+		return fmt.Errorf("failed to rotate webhook secret: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no webhook endpoint found with id: %d", id)
+	}
+	return nil
+}
+
+// DeleteEndpoint removes an endpoint together with its delivery log.
+func (r *WebhookRepository) DeleteEndpoint(ctx context.Context, id int64) error {
+	cmdTag, err := r.DB.Exec(ctx, `DELETE FROM webhook_endpoints WHERE id = $1`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook endpoint: %w", err)
+	}
+	if cmdTag.RowsAffected() == 0 {
+		return fmt.Errorf("no webhook endpoint found with id: %d", id)
+	}
+	return nil
+}
+
+// ============================== Deliveries ==============================
+
+// Enqueue queues the event for every active endpoint subscribed to it and returns the number of deliveries.
+// Finished deliveries older than the retention period are purged on the way.
+func (r *WebhookRepository) Enqueue(ctx context.Context, event, eventID string, payload []byte) (int, error) {
+	sql := `
+		INSERT INTO webhook_deliveries (endpoint_id, event, event_id, payload)
+		SELECT id, $1, $2, $3
+		FROM webhook_endpoints
+		WHERE active = TRUE AND ($1 = ANY(events) OR '*' = ANY(events))
+	`
+	cmdTag, err := r.DB.Exec(ctx, sql, event, eventID, payload)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue webhook deliveries: %w", err)
+	}
+
+	if _, err := r.DB.Exec(ctx, `
+		DELETE FROM webhook_deliveries
+		WHERE status <> 'PENDING' AND created_at < $1
+	`, time.Now().Add(-webhookDeliveryRetention)); err != nil {
+		return 0, fmt.Errorf("failed to purge webhook deliveries: %w", err)
+	}
+
+	return int(cmdTag.RowsAffected()), nil
+}
+
+// EnqueueTo queues an event for a single endpoint, whatever it subscribes to, and returns the delivery ID.
+func (r *WebhookRepository) EnqueueTo(ctx context.Context, endpointID int64, event, eventID string, payload []byte) (int64, error) {
+	sql := `
+		INSERT INTO webhook_deliveries (endpoint_id, event, event_id, payload)
+		VALUES ($1, $2, $3, $4)
+		RETURNING id
+	`
+	var id int64
+	if err := r.DB.QueryRow(ctx, sql, endpointID, event, eventID, payload).Scan(&id); err != nil {
+		return 0, fmt.Errorf("failed to enqueue webhook delivery: %w", err)
+	}
+	return id, nil
+}
+
+// Replay queues a copy of a delivery (same event ID and payload) and returns the new delivery ID.
+func (r *WebhookRepository) Replay(ctx context.Context, id int64) (int64, error) {
+	sql := `
+		INSERT INTO webhook_deliveries (endpoint_id, event, event_id, payload, replay_of)
+		SELECT endpoint_id, event, event_id, payload, id
+		FROM webhook_deliveries
+		WHERE id = $1
+		RETURNING id
+	`
+	var newID int64
+	if err := r.DB.QueryRow(ctx, sql, id).Scan(&newID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return 0, errors.New("no webhook delivery found")
+		}
+		return 0, fmt.Errorf("failed to replay webhook delivery: %w", err)
+	}
+	return newID, nil
+}
+
+// ClaimDue returns up to limit pending deliveries of active endpoints whose next attempt is due.
+// Their next attempt is pushed back by lease so that another worker does not pick them up while
+// they are being sent; RecordAttempt sets the real schedule.
+func (r *WebhookRepository) ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookJob, error) {
+	sql := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		FROM webhook_endpoints e
+		WHERE e.id = d.endpoint_id
+		  AND d.id IN (
+			SELECT wd.id
+			FROM webhook_deliveries wd
+			JOIN webhook_endpoints we ON we.id = wd.endpoint_id
+			WHERE wd.status = 'PENDING' AND wd.next_attempt_at <= CURRENT_TIMESTAMP AND we.active = TRUE
+			ORDER BY wd.next_attempt_at, wd.id
+			LIMIT $1
+			FOR UPDATE OF wd SKIP LOCKED
+		  )
+		RETURNING d.id, d.endpoint_id, d.event, d.event_id, d.payload, d.status, d.attempts, d.replay_of, d.created_at,
+		          e.url, e.secret
+	`
+	rows, err := r.DB.Query(ctx, sql, limit, lease.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	jobs := []models.WebhookJob{}
+	for rows.Next() {
+		var j models.WebhookJob
+		d := &j.Delivery
+		if err := rows.Scan(&d.ID, &d.EndpointID, &d.Event, &d.EventID, &d.Payload, &d.Status, &d.Attempts,
+			&d.ReplayOf, &d.CreatedAt, &j.URL, &j.Secret); err != nil {
+			return nil, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		d.EndpointURL = j.URL
+		jobs = append(jobs, j)
+	}
+	return jobs, rows.Err()
+}
+
+// RecordAttempt stores the outcome of a delivery attempt.
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, a *models.WebhookAttempt) error {
+	sql := `
+		UPDATE webhook_deliveries
+		SET status = $2,
+		    attempts = attempts + 1,
+		    last_attempt_at = CURRENT_TIMESTAMP,
+		    response_status = $3,
+		    response_body = $4,
+		    error = $5,
+		    duration_ms = $6,
+		    next_attempt_at = COALESCE($7, next_attempt_at),
+		    delivered_at = CASE WHEN $2 = 'SUCCEEDED' THEN CURRENT_TIMESTAMP ELSE delivered_at END
+		WHERE id = $1
+	`
+	_, err := r.DB.Exec(ctx, sql, a.DeliveryID, a.Status, a.ResponseStatus, a.ResponseBody, a.Error, a.DurationMS, a.NextAttemptAt)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+	return nil
+}
+
+// GetDeliveries returns a page of the delivery log, newest first, and the total count.
+// Payloads and response bodies are left out; GetDelivery returns them.
+func (r *WebhookRepository) GetDeliveries(ctx context.Context, f models.WebhookDeliveryFilter) ([]models.WebhookDelivery, int, error) {
+	where := ` WHERE 1=1`
+	args := []any{}
+	argIdx := 1
+
+	if f.EndpointID > 0 {
+		where += fmt.Sprintf(" AND d.endpoint_id = $%d", argIdx)
+		args = append(args, f.EndpointID)
+		argIdx++
+	}
+	if f.Status != "" {
+		where += fmt.Sprintf(" AND d.status = $%d", argIdx)
+		args = append(args, f.Status)
+		argIdx++
+	}
+	if f.Event != "" {
+		where += fmt.Sprintf(" AND d.event = $%d", argIdx)
+		args = append(args, f.Event)
+		argIdx++
+	}
+
+	from := `
+		FROM webhook_deliveries d
+		JOIN webhook_endpoints e ON e.id = d.endpoint_id`
+
+	var total int
+	if err := r.DB.QueryRow(ctx, `SELECT COUNT(*)`+from+where, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count webhook deliveries: %w", err)
+	}
+
+	sql := `
+		SELECT d.id, d.endpoint_id, e.url, d.event, d.event_id, d.status, d.attempts, d.next_attempt_at,
+		       d.last_attempt_at, d.response_status, d.error, d.duration_ms, d.replay_of, d.delivered_at, d.created_at` +
+		from + where +
+		fmt.Sprintf(" ORDER BY d.created_at DESC, d.id DESC LIMIT $%d OFFSET $%d", argIdx, argIdx+1)
+	args = append(args, f.Limit, (f.Page-1)*f.Limit)
+
+	rows, err := r.DB.Query(ctx, sql, args...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		if err := rows.Scan(&d.ID, &d.EndpointID, &d.EndpointURL, &d.Event, &d.EventID, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastAttemptAt, &d.ResponseStatus, &d.Error, &d.DurationMS, &d.ReplayOf,
+			&d.DeliveredAt, &d.CreatedAt); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan webhook delivery: %w", err)
+		}
+		if d.Status != models.WebhookStatusPending {
+			d.NextAttemptAt = nil
+		}
+		deliveries = append(deliveries, d)
+	}
+	return deliveries, total, rows.Err()
+}
+
+// GetDelivery returns a single delivery with its payload and the last response.
+func (r *WebhookRepository) GetDelivery(ctx context.Context, id int64) (*models.WebhookDelivery, error) {
+	sql := `
+		SELECT d.id, d.endpoint_id, e.url, d.event, d.event_id, d.payload, d.status, d.attempts, d.next_attempt_at,
+		       d.last_attempt_at, d.response_status, d.response_body, d.error, d.duration_ms, d.replay_of,
+		       d.delivered_at, d.created_at
+		FROM webhook_deliveries d
+		JOIN webhook_endpoints e ON e.id = d.endpoint_id
+		WHERE d.id = $1
+	`
+	var d models.WebhookDelivery
+	err := r.DB.QueryRow(ctx, sql, id).Scan(&d.ID, &d.EndpointID, &d.EndpointURL, &d.Event, &d.EventID, &d.Payload,
+		&d.Status, &d.Attempts, &d.NextAttemptAt, &d.LastAttemptAt, &d.ResponseStatus, &d.ResponseBody, &d.Error,
+		&d.DurationMS, &d.ReplayOf, &d.DeliveredAt, &d.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, errors.New("no webhook delivery found")
+		}
+		return nil, fmt.Errorf("failed to get webhook delivery: %w", err)
+	}
+	if d.Status != models.WebhookStatusPending {
+		d.NextAttemptAt = nil
+	}
+	return &d, nil
+}
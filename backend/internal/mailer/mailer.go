@@ -0,0 +1,293 @@
+package mailer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// Message is an outgoing email. At least one of Text or HTML should be set.
+type Message struct {
+	// Template is the name of the template the message was rendered from (recorded in the email log)
+	Template string
+	To       []string
+	ReplyTo  string
+	Subject  string
+	Text     string
+	HTML     string
+	// Attachments are sent after the body, e.g. a generated PDF
+	Attachments []Attachment
+}
+
+// Attachment is a file sent with a message.
+type Attachment struct {
+	FileName    string
+	ContentType string // defaults to application/octet-stream
+	Data        []byte
+}
+
+// Transport delivers a message on the wire (or somewhere else in development).
+type Transport interface {
+	Send(ctx context.Context, from string, msg Message) error
+}
+
+// Recorder stores the outcome of every delivery. It is implemented by the database layer.
+type Recorder interface {
+	Record(ctx context.Context, e *models.EmailMessage) error
+}
+
+// TemplateStore holds admin-edited versions of the templates. It is implemented by the database layer.
+type TemplateStore interface {
+	// GetTemplate returns the edited template, or nil if the built-in default is used.
+	GetTemplate(ctx context.Context, name string) (*models.EmailTemplate, error)
+}
+
+// Mailer renders templates and sends messages through the configured transport,
+// retrying failed deliveries and recording the outcome.
+type Mailer struct {
+	from          string
+	transportName string
+	transport     Transport
+	renderer      *Renderer
+	recorder      Recorder
+	templates     TemplateStore
+	maxAttempts   int
+	retryDelay    time.Duration
+	globals       map[string]any
+	infoLog       *log.Logger
+	errorLog      *log.Logger
+}
+
+// New creates a Mailer for the configured transport ("smtp", "file" or "log").
+// company is available to every template as .Company. recorder and templates may be nil,
+// in which case deliveries are only logged and the built-in templates are always used.
+func New(cfg models.MailConfig, company models.CompanyConfig, recorder Recorder, templates TemplateStore, infoLog, errorLog *log.Logger) (*Mailer, error) {
+	var transport Transport
+	switch cfg.Transport {
+	case "smtp":
+		if cfg.Host == "" {
+			return nil, errors.New("mailer: SMTP_HOST is required for the smtp transport")
+		}
+		transport = NewSMTPTransport(cfg.Host, cfg.Port, cfg.Username, cfg.Password)
+	case "file":
+		t, err := NewFileTransport(cfg.OutboxDir)
+		if err != nil {
+			return nil, err
+		}
+		transport = t
+	case "", "log":
+		cfg.Transport = "log"
+		transport = NewLogTransport(infoLog)
+	default:
+		return nil, fmt.Errorf("mailer: unknown transport %q", cfg.Transport)
+	}
+
+	renderer, err := NewRenderer()
+	if err != nil {
+		return nil, err
+	}
+
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	if cfg.FromName == "" {
+		cfg.FromName = company.Name
+	}
+	from := (&mail.Address{Name: cfg.FromName, Address: cfg.From}).String()
+	return &Mailer{
+		from:          from,
+		transportName: cfg.Transport,
+		transport:     transport,
+		renderer:      renderer,
+		recorder:      recorder,
+		templates:     templates,
+		maxAttempts:   maxAttempts,
+		retryDelay:    2 * time.Second,
+		globals: map[string]any{
+			"Company": company,
+		},
+		infoLog:  infoLog,
+		errorLog: errorLog,
+	}, nil
+}
+
+// asyncTimeout bounds a background delivery, including retries
+const asyncTimeout = 2 * time.Minute
+
+// Send validates and delivers a message.
+func (m *Mailer) Send(ctx context.Context, msg Message) error {
+	return m.deliver(ctx, msg)
+}
+
+// SendAsync delivers a message in the background. Failures are logged (and recorded), never returned,
+// so a mail problem cannot break the request that triggered it.
+func (m *Mailer) SendAsync(msg Message) {
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), asyncTimeout)
+		defer cancel()
+		if err := m.deliver(ctx, msg); err != nil {
+			m.errorLog.Println("mailer: background send failed:", err)
+		}
+	}()
+}
+
+// SendTemplate renders the named template with data and delivers it to the recipients.
+func (m *Mailer) SendTemplate(ctx context.Context, name string, to []string, data map[string]any) error {
+	msg, err := m.Render(ctx, name, data)
+	if err != nil {
+		return err
+	}
+	msg.To = to
+	return m.deliver(ctx, msg)
+}
+
+// SendTemplateAsync renders the named template and delivers it in the background (see SendAsync).
+// Only a rendering error is returned.
+func (m *Mailer) SendTemplateAsync(ctx context.Context, name string, to []string, data map[string]any) error {
+	msg, err := m.Render(ctx, name, data)
+	if err != nil {
+		return err
+	}
+	msg.To = to
+	m.SendAsync(msg)
+	return nil
+}
+
+// Render renders the named template, using the admin-edited version when there is one.
+// Global values (e.g. Company) are available to every template unless data overrides them.
+// If the edited version fails to render, the built-in default is used and the error logged.
+func (m *Mailer) Render(ctx context.Context, name string, data map[string]any) (Message, error) {
+	merged := make(map[string]any, len(m.globals)+len(data))
+	for k, v := range m.globals {
+		merged[k] = v
+	}
+	for k, v := range data {
+		merged[k] = v
+	}
+
+	if m.templates != nil {
+		override, err := m.templates.GetTemplate(ctx, name)
+		if err != nil {
+			m.errorLog.Printf("mailer: failed to load template %s: %v", name, err)
+		} else if override != nil {
+			msg, err := m.renderer.RenderOverride(override, merged)
+			if err == nil {
+				msg.Template = name
+				return msg, nil
+			}
+			m.errorLog.Printf("mailer: edited template %s failed, using the default: %v", name, err)
+		}
+	}
+
+	msg, err := m.renderer.Render(name, merged)
+	msg.Template = name
+	return msg, err
+}
+
+// Templates lists the built-in templates.
+func (m *Mailer) Templates() []string {
+	return m.renderer.Names()
+}
+
+// DefaultTemplate returns the source of a built-in template.
+func (m *Mailer) DefaultTemplate(name string) (*models.EmailTemplate, error) {
+	return m.renderer.Default(name)
+}
+
+// ValidateTemplate checks that an edited template parses.
+func (m *Mailer) ValidateTemplate(t *models.EmailTemplate) error {
+	return m.renderer.Validate(t)
+}
+
+// deliver sends msg, retrying with a doubling delay up to maxAttempts, and records the result.
+func (m *Mailer) deliver(ctx context.Context, msg Message) error {
+	if err := validate(msg); err != nil {
+		return err
+	}
+
+	record := &models.EmailMessage{
+		Template:   msg.Template,
+		Recipients: msg.To,
+		Subject:    msg.Subject,
+		Transport:  m.transportName,
+		Status:     models.EmailStatusFailed,
+	}
+
+	var err error
+	delay := m.retryDelay
+	for attempt := 1; attempt <= m.maxAttempts; attempt++ {
+		record.Attempts = attempt
+		if err = m.transport.Send(ctx, m.from, msg); err == nil {
+			break
+		}
+		m.errorLog.Printf("mailer: attempt %d/%d for %q failed: %v", attempt, m.maxAttempts, msg.Subject, err)
+		if attempt == m.maxAttempts {
+			break
+		}
+		select {
+		case <-ctx.Done():
+			err = ctx.Err()
+			attempt = m.maxAttempts
+		case <-time.After(delay):
+			delay *= 2
+		}
+	}
+
+	if err == nil {
+		now := time.Now()
+		record.Status = models.EmailStatusSent
+		record.SentAt = &now
+		m.infoLog.Printf("Email %q sent to %s", msg.Subject, strings.Join(msg.To, ", "))
+	} else {
+		record.Error = err.Error()
+	}
+	m.record(ctx, record)
+
+	if err != nil {
+		return fmt.Errorf("mailer: send to %s: %w", strings.Join(msg.To, ", "), err)
+	}
+	return nil
+}
+
+// record stores the delivery outcome. It must not fail the send, so errors are only logged.
+func (m *Mailer) record(ctx context.Context, e *models.EmailMessage) {
+	if m.recorder == nil {
+		return
+	}
+	if err := m.recorder.Record(context.WithoutCancel(ctx), e); err != nil {
+		m.errorLog.Println("mailer: failed to record email:", err)
+	}
+}
+
+func validate(msg Message) error {
+	if len(msg.To) == 0 {
+		return errors.New("mailer: message has no recipients")
+	}
+	for _, to := range msg.To {
+		if _, err := mail.ParseAddress(to); err != nil {
+			return fmt.Errorf("mailer: invalid recipient %q: %w", to, err)
+		}
+	}
+	if msg.ReplyTo != "" {
+		if _, err := mail.ParseAddress(msg.ReplyTo); err != nil {
+			return fmt.Errorf("mailer: invalid reply-to %q: %w", msg.ReplyTo, err)
+		}
+	}
+	if strings.TrimSpace(msg.Subject) == "" {
+		return errors.New("mailer: message has no subject")
+	}
+	for _, a := range msg.Attachments {
+		if strings.TrimSpace(a.FileName) == "" || len(a.Data) == 0 {
+			return errors.New("mailer: attachment without file name or content")
+		}
+	}
+	return nil
+}
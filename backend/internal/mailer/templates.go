@@ -0,0 +1,183 @@
+package mailer
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	"strings"
+	texttemplate "text/template"
+
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+//go:embed templates/*.tmpl
+var templateFS embed.FS
+
+// Renderer renders the embedded email templates. A template <name> consists of
+//   - templates/<name>.txt.tmpl defining "<name>.subject" and "<name>.text" (text/template)
+//   - templates/<name>.html.tmpl defining "<name>.html" (html/template, optional)
+//
+// HTML templates may use the "layout.header" and "layout.footer" blocks from layout.html.tmpl.
+// A template can be overridden at render time by an admin-edited version (see RenderOverride).
+type Renderer struct {
+	text *texttemplate.Template
+	html *htmltemplate.Template
+	// layout holds only the layout blocks. It is never executed, so it can be cloned for overrides.
+	layout *htmltemplate.Template
+	// htmlSource is the HTML templates parsed as plain text, never executed; executing an html/template
+	// rewrites its tree with escapers, so the editable source is taken from here.
+	htmlSource *texttemplate.Template
+}
+
+// NewRenderer parses the embedded templates.
+func NewRenderer() (*Renderer, error) {
+	text, err := texttemplate.New("").ParseFS(templateFS, "templates/*.txt.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("mailer: parse text templates: %w", err)
+	}
+	html, err := htmltemplate.New("").ParseFS(templateFS, "templates/*.html.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("mailer: parse html templates: %w", err)
+	}
+	layout, err := htmltemplate.New("").ParseFS(templateFS, "templates/layout.html.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("mailer: parse layout: %w", err)
+	}
+	htmlSource, err := texttemplate.New("").ParseFS(templateFS, "templates/*.html.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("mailer: parse html templates: %w", err)
+	}
+	return &Renderer{text: text, html: html, layout: layout, htmlSource: htmlSource}, nil
+}
+
+// Names lists the embedded templates.
+func (r *Renderer) Names() []string {
+	var names []string
+	for _, t := range r.text.Templates() {
+		if name, ok := strings.CutSuffix(t.Name(), ".subject"); ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Default returns the source of the embedded template, in the form an admin edits it.
+func (r *Renderer) Default(name string) (*models.EmailTemplate, error) {
+	subject := r.text.Lookup(name + ".subject")
+	if subject == nil {
+		return nil, fmt.Errorf("mailer: unknown template %q", name)
+	}
+
+	t := &models.EmailTemplate{Name: name, Subject: strings.TrimSpace(subject.Tree.Root.String())}
+	if text := r.text.Lookup(name + ".text"); text != nil {
+		t.Text = strings.TrimSpace(text.Tree.Root.String())
+	}
+	if html := r.htmlSource.Lookup(name + ".html"); html != nil {
+		t.HTML = strings.TrimSpace(html.Tree.Root.String())
+	}
+	return t, nil
+}
+
+// Validate parses an edited template without executing it.
+func (r *Renderer) Validate(t *models.EmailTemplate) error {
+	_, _, _, err := r.parseOverride(t)
+	return err
+}
+
+// Render executes the named embedded template. The returned message has no recipients.
+func (r *Renderer) Render(name string, data any) (Message, error) {
+	var msg Message
+
+	if r.text.Lookup(name+".subject") == nil {
+		return msg, fmt.Errorf("mailer: unknown template %q", name)
+	}
+
+	var err error
+	if msg.Subject, err = execText(r.text, name+".subject", data); err != nil {
+		return msg, err
+	}
+	if msg.Text, err = execText(r.text, name+".text", data); err != nil {
+		return msg, err
+	}
+	msg.Subject = strings.TrimSpace(msg.Subject)
+	msg.Text = strings.TrimSpace(msg.Text) + "\n"
+
+	if r.html.Lookup(name+".html") != nil {
+		var buf bytes.Buffer
+		if err := r.html.ExecuteTemplate(&buf, name+".html", data); err != nil {
+			return msg, fmt.Errorf("mailer: render %s html: %w", name, err)
+		}
+		msg.HTML = buf.String()
+	}
+
+	return msg, nil
+}
+
+// RenderOverride executes an admin-edited template. An empty HTML body sends a text-only message.
+func (r *Renderer) RenderOverride(t *models.EmailTemplate, data any) (Message, error) {
+	var msg Message
+
+	subject, text, html, err := r.parseOverride(t)
+	if err != nil {
+		return msg, err
+	}
+
+	if msg.Subject, err = execText(subject, subject.Name(), data); err != nil {
+		return msg, err
+	}
+	if msg.Text, err = execText(text, text.Name(), data); err != nil {
+		return msg, err
+	}
+	msg.Subject = strings.TrimSpace(msg.Subject)
+	msg.Text = strings.TrimSpace(msg.Text) + "\n"
+
+	if html != nil {
+		var buf bytes.Buffer
+		if err := html.ExecuteTemplate(&buf, t.Name+".html", data); err != nil {
+			return msg, fmt.Errorf("mailer: render %s html: %w", t.Name, err)
+		}
+		msg.HTML = buf.String()
+	}
+
+	return msg, nil
+}
+
+// parseOverride parses the parts of an edited template. The HTML part can use the layout blocks.
+func (r *Renderer) parseOverride(t *models.EmailTemplate) (*texttemplate.Template, *texttemplate.Template, *htmltemplate.Template, error) {
+	if strings.TrimSpace(t.Subject) == "" || strings.TrimSpace(t.Text) == "" {
+		return nil, nil, nil, fmt.Errorf("mailer: template %q needs a subject and a text body", t.Name)
+	}
+
+	subject, err := texttemplate.New(t.Name + ".subject").Parse(t.Subject)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("mailer: parse %s subject: %w", t.Name, err)
+	}
+	text, err := texttemplate.New(t.Name + ".text").Parse(t.Text)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("mailer: parse %s text: %w", t.Name, err)
+	}
+
+	if strings.TrimSpace(t.HTML) == "" {
+		return subject, text, nil, nil
+	}
+	set, err := r.layout.Clone()
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("mailer: clone layout: %w", err)
+	}
+	html, err := set.New(t.Name + ".html").Parse(t.HTML)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("mailer: parse %s html: %w", t.Name, err)
+	}
+	return subject, text, html, nil
+}
+
+func execText(t *texttemplate.Template, name string, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := t.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("mailer: render %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
@@ -0,0 +1,264 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"mime"
+	"mime/quotedprintable"
+	"net"
+	"net/smtp"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ============================== SMTP ==============================
+
+// SMTPTransport delivers messages to an SMTP server. STARTTLS is used when the server offers it,
+// and authentication only when a username is configured, so a local catcher (MailHog, Mailpit) works too.
+type SMTPTransport struct {
+	host     string
+	port     int
+	username string
+	password string
+}
+
+// NewSMTPTransport creates an SMTP transport. Port defaults to 587.
+func NewSMTPTransport(host string, port int, username, password string) *SMTPTransport {
+	if port == 0 {
+		port = 587
+	}
+	return &SMTPTransport{host: host, port: port, username: username, password: password}
+}
+
+// Send implements Transport.
+func (t *SMTPTransport) Send(ctx context.Context, from string, msg Message) error {
+	addr := net.JoinHostPort(t.host, strconv.Itoa(t.port))
+
+	dialer := net.Dialer{Timeout: 15 * time.Second}
+	conn, err := dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	var c *smtp.Client
+	if t.port == 465 {
+		// Implicit TLS
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: t.host})
+		if c, err = smtp.NewClient(tlsConn, t.host); err != nil {
+			conn.Close()
+			return err
+		}
+	} else {
+		if c, err = smtp.NewClient(conn, t.host); err != nil {
+			conn.Close()
+			return err
+		}
+		if ok, _ := c.Extension("STARTTLS"); ok {
+			if err := c.StartTLS(&tls.Config{ServerName: t.host}); err != nil {
+				c.Close()
+				return err
+			}
+		}
+	}
+	defer c.Close()
+
+	if t.username != "" {
+		if err := c.Auth(smtp.PlainAuth("", t.username, t.password, t.host)); err != nil {
+			return err
+		}
+	}
+
+	envelopeFrom := from
+	if i := strings.LastIndex(from, "<"); i >= 0 {
+		envelopeFrom = strings.Trim(from[i:], "<>")
+	}
+	if err := c.Mail(envelopeFrom); err != nil {
+		return err
+	}
+	for _, to := range msg.To {
+		if err := c.Rcpt(to); err != nil {
+			return err
+		}
+	}
+
+	wc, err := c.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := wc.Write(buildMIME(from, msg)); err != nil {
+		wc.Close()
+		return err
+	}
+	if err := wc.Close(); err != nil {
+		return err
+	}
+
+	return c.Quit()
+}
+
+// ============================== File ==============================
+
+// FileTransport writes every message as an .eml file into a directory, so it can be opened in a mail client.
+type FileTransport struct {
+	dir string
+}
+
+// NewFileTransport creates the outbox directory (default data/outbox) and returns a file transport.
+func NewFileTransport(dir string) (*FileTransport, error) {
+	if dir == "" {
+		dir = filepath.Join("data", "outbox")
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("mailer: create outbox directory: %w", err)
+	}
+	return &FileTransport{dir: dir}, nil
+}
+
+// Send implements Transport.
+func (t *FileTransport) Send(ctx context.Context, from string, msg Message) error {
+	name := fmt.Sprintf("%s_%s.eml", time.Now().Format("20060102-150405"), randomID())
+	return os.WriteFile(filepath.Join(t.dir, name), buildMIME(from, msg), 0644)
+}
+
+// ============================== Log ==============================
+
+// LogTransport only logs messages. It is the default in development.
+type LogTransport struct {
+	infoLog *log.Logger
+}
+
+// NewLogTransport creates a transport that writes messages to the info log.
+func NewLogTransport(infoLog *log.Logger) *LogTransport {
+	return &LogTransport{infoLog: infoLog}
+}
+
+// Send implements Transport.
+func (t *LogTransport) Send(ctx context.Context, from string, msg Message) error {
+	body := msg.Text
+	if body == "" {
+		body = msg.HTML
+	}
+	for _, a := range msg.Attachments {
+		body += fmt.Sprintf("\n[attachment] %s (%d bytes)", a.FileName, len(a.Data))
+	}
+	t.infoLog.Printf("[mail] from=%s to=%s subject=%q\n%s", from, strings.Join(msg.To, ", "), msg.Subject, body)
+	return nil
+}
+
+// ============================== MIME ==============================
+
+// buildMIME renders the message as RFC 5322 bytes with text and/or HTML parts.
+func buildMIME(from string, msg Message) []byte {
+	var b bytes.Buffer
+
+	// Header values never contain line breaks, so user input cannot inject headers
+	writeHeader := func(k, v string) {
+		v = strings.NewReplacer("\r", "", "\n", "").Replace(v)
+		fmt.Fprintf(&b, "%s: %s\r\n", k, v)
+	}
+	writeHeader("From", from)
+	writeHeader("To", strings.Join(msg.To, ", "))
+	if msg.ReplyTo != "" {
+		writeHeader("Reply-To", msg.ReplyTo)
+	}
+	writeHeader("Subject", mime.QEncoding.Encode("utf-8", msg.Subject))
+	writeHeader("Date", time.Now().Format(time.RFC1123Z))
+	writeHeader("Message-ID", fmt.Sprintf("<%s@%s>", randomID(), messageIDHost(from)))
+	writeHeader("MIME-Version", "1.0")
+
+	if len(msg.Attachments) == 0 {
+		writeContent(&b, msg)
+		return b.Bytes()
+	}
+
+	// multipart/mixed: the body first, then one part per attachment
+	boundary := "mixed-" + randomID()
+	writeHeader("Content-Type", fmt.Sprintf(`multipart/mixed; boundary="%s"`, boundary))
+	b.WriteString("\r\n")
+	fmt.Fprintf(&b, "--%s\r\n", boundary)
+	writeContent(&b, msg)
+	for _, a := range msg.Attachments {
+		fmt.Fprintf(&b, "\r\n--%s\r\n", boundary)
+		writeAttachment(&b, a)
+	}
+	fmt.Fprintf(&b, "\r\n--%s--\r\n", boundary)
+
+	return b.Bytes()
+}
+
+// writeContent writes the Content-Type header and the text and/or HTML body of msg.
+func writeContent(b *bytes.Buffer, msg Message) {
+	switch {
+	case msg.Text != "" && msg.HTML != "":
+		boundary := "alt-" + randomID()
+		fmt.Fprintf(b, "Content-Type: multipart/alternative; boundary=\"%s\"\r\n\r\n", boundary)
+		writePart(b, boundary, "text/plain", msg.Text)
+		writePart(b, boundary, "text/html", msg.HTML)
+		fmt.Fprintf(b, "--%s--\r\n", boundary)
+	case msg.HTML != "":
+		writeBody(b, "text/html", msg.HTML)
+	default:
+		writeBody(b, "text/plain", msg.Text)
+	}
+}
+
+// writeAttachment writes a as a base64 encoded part with lines of 76 characters.
+func writeAttachment(b *bytes.Buffer, a Attachment) {
+	contentType := a.ContentType
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	name := strings.NewReplacer("\r", "", "\n", "", `"`, "").Replace(a.FileName)
+	fmt.Fprintf(b, "Content-Type: %s; name=\"%s\"\r\n", contentType, mime.QEncoding.Encode("utf-8", name))
+	fmt.Fprintf(b, "Content-Disposition: attachment; filename=\"%s\"\r\n", mime.QEncoding.Encode("utf-8", name))
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	encoded := base64.StdEncoding.EncodeToString(a.Data)
+	for len(encoded) > 76 {
+		b.WriteString(encoded[:76])
+		b.WriteString("\r\n")
+		encoded = encoded[76:]
+	}
+	b.WriteString(encoded)
+	b.WriteString("\r\n")
+}
+
+func writePart(b *bytes.Buffer, boundary, contentType, body string) {
+	fmt.Fprintf(b, "--%s\r\n", boundary)
+	writeBody(b, contentType, body)
+	b.WriteString("\r\n")
+}
+
+func writeBody(b *bytes.Buffer, contentType, body string) {
+	fmt.Fprintf(b, "Content-Type: %s; charset=utf-8\r\n", contentType)
+	b.WriteString("Content-Transfer-Encoding: quoted-printable\r\n\r\n")
+	qp := quotedprintable.NewWriter(b)
+	qp.Write([]byte(body))
+	qp.Close()
+	b.WriteString("\r\n")
+}
+
+func randomID() string {
+	buf := make([]byte, 12)
+	rand.Read(buf)
+	return hex.EncodeToString(buf)
+}
+
+func messageIDHost(from string) string {
+	if i := strings.LastIndex(from, "@"); i >= 0 {
+		return strings.Trim(from[i+1:], ">")
+	}
+	return "localhost"
+}
@@ -0,0 +1,36 @@
+package models
+
+import "time"
+
+// RefreshToken represents a row of the refresh_tokens table.
+// Only the SHA-256 hash of the token is stored; the plain token is returned to the client once.
+type RefreshToken struct {
+	ID         int64      `json:"id"`
+	UserID     int64      `json:"user_id"`
+	FamilyID   string     `json:"family_id"`
+	TokenHash  string     `json:"-"`
+	ExpiresAt  time.Time  `json:"expires_at"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	ReplacedBy *int64     `json:"replaced_by,omitempty"`
+	UserAgent  string     `json:"user_agent"`
+	IPAddress  string     `json:"ip_address"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// SessionState is what AuthJWT needs to know about a user to accept one of their access tokens.
+type SessionState struct {
+	Status       string
+	TokenVersion int64
+	TokenRevoked bool
+}
+
+// PasswordResetToken is a single-use token emailed by the "forgot password" flow
+type PasswordResetToken struct {
+	ID        int64      `json:"id"`
+	UserID    int64      `json:"userId"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expiresAt"`
+	UsedAt    *time.Time `json:"usedAt,omitempty"`
+	IPAddress string     `json:"ipAddress"`
+	CreatedAt time.Time  `json:"createdAt"`
+}
@@ -4,16 +4,27 @@ import "time"
 
 // Client struct corresponds to the 'clients' database table.
 type Client struct {
+	ID            int64                `json:"id"`
+	Name          string               `json:"name"`
+	Area          string               `json:"area"`
+	ServiceName   string               `json:"service_name"`
+	ServiceDate   string               `json:"service_date"`
+	Status        string               `json:"status"` // Can be used for filtering (e.g., "Running", "Completed")
+	Note          string               `json:"note"`
+	ImageLink     string               `json:"image_link"` // The filename/path on the server
+	InquiryID     *int64               `json:"inquiry_id"` // The inquiry the client was converted from, if any
+	CreatedAt     time.Time            `json:"created_at"`
+	UpdatedAt     time.Time            `json:"updated_at"`
+	SourceInquiry *ClientSourceInquiry `json:"source_inquiry,omitempty"` // Summary of the original inquiry, filled by GetByID
+}
+
+// ClientSourceInquiry is the part of the original inquiry shown to staff on the client profile.
+// It is left out of the public profile.
+type ClientSourceInquiry struct {
 	ID          int64     `json:"id"`
-	Name        string    `json:"name"`
-	Area        string    `json:"area"`
-	ServiceName string    `json:"service_name"`
-	ServiceDate string    `json:"service_date"`
-	Status      string    `json:"status"` // Can be used for filtering (e.g., "Running", "Completed")
-	Note        string    `json:"note"`
-	ImageLink   string    `json:"image_link"` // The filename/path on the server
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ReferenceNo string    `json:"reference_no"`
+	Subject     string    `json:"subject"`
+	InquiryDate time.Time `json:"inquiry_date"`
 }
 
 // ClientMetrics holds the statistical counts for the client data.
@@ -1,6 +1,7 @@
 package models
 
 import (
+	"net"
 	"time"
 )
 
@@ -18,16 +19,18 @@ const AuthClaimsContextKey ContextKey = "authClaims"
 
 // JWT holds token data
 type JWT struct {
-	ID        int64     `json:"id"`
-	Name      string    `json:"name"`
-	Username  string    `json:"username"`
-	Role      string    `json:"role"`
-	Issuer    string    `json:"iss"`
-	Audience  string    `json:"aud"`
-	ExpiresAt int64     `json:"exp"`
-	IssuedAt  int64     `json:"iat"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
+	ID           int64     `json:"id"`
+	TokenID      string    `json:"jti"`
+	TokenVersion int64     `json:"ver"`
+	Name         string    `json:"name"`
+	Username     string    `json:"username"`
+	Role         string    `json:"role"`
+	Issuer       string    `json:"iss"`
+	Audience     string    `json:"aud"`
+	ExpiresAt    int64     `json:"exp"`
+	IssuedAt     int64     `json:"iat"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
 }
 
 type JWTConfig struct {
@@ -39,6 +42,71 @@ type JWTConfig struct {
 	Refresh   time.Duration
 }
 
+// LoginConfig controls brute-force protection of the sign-in endpoint
+type LoginConfig struct {
+	MaxAttempts     int           // failed attempts before an account is locked
+	IPMaxAttempts   int           // failed attempts per IP within Window before the IP is throttled
+	Window          time.Duration // look-back window for the per-IP counter
+	LockDuration    time.Duration // duration of the first lock; doubles with every further lock
+	MaxLockDuration time.Duration // upper bound of the lock duration
+	ResetTokenTTL   time.Duration // lifetime of an emailed password reset link
+	ResetRateLimit  int           // reset requests allowed per IP and ResetRateWindow (0 disables the limit)
+	ResetRateWindow time.Duration // window of ResetRateLimit
+}
+
+// MailConfig selects and configures the outgoing mail transport
+type MailConfig struct {
+	Transport   string // "smtp", "file" or "log"
+	Host        string
+	Port        int
+	Username    string
+	Password    string
+	From        string
+	FromName    string
+	OutboxDir   string // directory of the file transport
+	MaxAttempts int    // delivery attempts before a message is given up
+}
+
+// CompanyConfig holds the company details shown in emails and documents
+type CompanyConfig struct {
+	Name    string `json:"name"`
+	Email   string `json:"email"`
+	Phone   string `json:"phone"`
+	Address string `json:"address"`
+	Website string `json:"website"`
+}
+
+// InquiryConfig controls what happens around contact-form inquiries
+type InquiryConfig struct {
+	NotifyRecipients []string // staff addresses emailed on every new inquiry
+	AdminURL         string   // admin panel page used for deep links, e.g. https://example.com/admin_panel.html
+	ReplyTo          string   // Reply-To of staff replies to customers
+
+	// Spam protection of the public form
+	RateLimit       int           // submissions allowed per IP and RateWindow (0 disables the limit)
+	RateWindow      time.Duration // window of RateLimit
+	FormTokenSecret string        // HMAC key of the form tokens
+	FormTokenMaxAge time.Duration // form tokens older than this are rejected
+	MinSubmitTime   time.Duration // faster submissions are treated as bots
+	SpamThreshold   int           // submissions scoring at least this are stored as SPAM
+}
+
+// WebhookConfig controls delivery of outgoing webhooks
+type WebhookConfig struct {
+	MaxAttempts int           // delivery attempts before a delivery is marked FAILED
+	Timeout     time.Duration // timeout of a single request to an endpoint
+	RetryDelay  time.Duration // delay before the first retry; multiplied by 4 for every further retry
+}
+
+// QuotationConfig holds the defaults of new quotations
+type QuotationConfig struct {
+	Prefix       string  // number prefix, e.g. QT gives QT-2026-0001
+	Currency     string  // ISO 4217 code printed on quotations
+	VatRate      float64 // VAT percentage suggested for new quotations
+	ValidityDays int     // new quotations are valid for this many days
+	Terms        string  // terms and conditions printed on new quotations
+}
+
 type DBConfig struct {
 	DSN    string
 	DEVDSN string
@@ -49,6 +117,16 @@ type Config struct {
 	Port  int64
 	Env   string
 	Owner string
-	JWT   JWTConfig
-	DB    DBConfig
+	// PasswordResetURL is the frontend page that receives the reset token as ?token=
+	PasswordResetURL string
+	// TrustedProxies are the reverse proxies allowed to set X-Forwarded-For and X-Real-IP
+	TrustedProxies []*net.IPNet
+	JWT            JWTConfig
+	Login          LoginConfig
+	Company        CompanyConfig
+	Mail           MailConfig
+	Inquiry        InquiryConfig
+	Webhook        WebhookConfig
+	Quotation      QuotationConfig
+	DB             DBConfig
 }
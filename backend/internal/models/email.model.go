@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// Delivery outcomes of an email_messages row
+const (
+	EmailStatusSent   = "SENT"
+	EmailStatusFailed = "FAILED"
+)
+
+// EmailMessage is the record of one outgoing email (table email_messages).
+// The body is not stored; Template names the template it was rendered from.
+type EmailMessage struct {
+	ID         int64      `json:"id"`
+	Template   string     `json:"template"`
+	Recipients []string   `json:"recipients"`
+	Subject    string     `json:"subject"`
+	Transport  string     `json:"transport"`
+	Status     string     `json:"status"`
+	Attempts   int        `json:"attempts"`
+	Error      string     `json:"error,omitempty"`
+	SentAt     *time.Time `json:"sentAt,omitempty"`
+	CreatedAt  time.Time  `json:"createdAt"`
+}
@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// EmailTemplate is the editable source of an email template (table email_templates).
+// Subject and Text use Go text/template syntax, HTML uses html/template.
+type EmailTemplate struct {
+	Name       string     `json:"name"`
+	Subject    string     `json:"subject"`
+	Text       string     `json:"text"`
+	HTML       string     `json:"html"`
+	Customized bool       `json:"customized"` // false when the built-in default is used
+	UpdatedBy  *int64     `json:"updatedBy,omitempty"`
+	UpdatedAt  *time.Time `json:"updatedAt,omitempty"`
+}
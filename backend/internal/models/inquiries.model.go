@@ -1,6 +1,9 @@
 package models
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
 
 // Inquiry represents the data structure for the inquiries table.
 type Inquiry struct {
@@ -12,6 +15,201 @@ type Inquiry struct {
 	Subject     string    `json:"subject"`
 	Message     string    `json:"message"`
 	Status      string    `json:"status"`
+	// AssignedTo is the staff member following up the inquiry; AssigneeName is filled on reads
+	AssignedTo   *int64     `json:"assigned_to"`
+	AssigneeName string     `json:"assignee_name"`
+	AssignedAt   *time.Time `json:"assigned_at"`
+	// SpamScore and SpamReasons are set by the spam scorer when the inquiry is submitted
+	SpamScore   int       `json:"spam_score"`
+	SpamReasons string    `json:"spam_reasons"`
+	IPAddress   string    `json:"ip_address"`
+	ClientID    *int64    `json:"client_id"` // the client created from the inquiry
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 }
+
+// Statuses the application sets itself; both must exist in the workflow to be used.
+const (
+	InquiryStatusSpam      = "SPAM"      // submissions flagged by the spam scorer
+	InquiryStatusConverted = "CONVERTED" // inquiries turned into a client
+)
+
+// InquiryFilter holds the paging, filter and search options of the inquiry list.
+type InquiryFilter struct {
+	PageIndex  int        // 1-based page number
+	PageLength int        // rows per page
+	Status     string     // exact status, optional
+	From       *time.Time // inquiry_date on or after, optional
+	To         *time.Time // inquiry_date before, optional
+	Search     string     // matches name, email, mobile, subject and message, optional
+	AssignedTo *int64     // assignee user ID, optional
+	Unassigned bool       // only inquiries without an assignee
+}
+
+// ReferenceNo is the customer-facing reference of the inquiry, e.g. INQ-20250131-000123.
+func (i *Inquiry) ReferenceNo() string {
+	return fmt.Sprintf("INQ-%s-%06d", i.InquiryDate.Format("20060102"), i.ID)
+}
+
+// Kinds of inquiry_messages rows
+const (
+	InquiryMessageNote  = "NOTE"  // internal, never sent to the customer
+	InquiryMessageReply = "REPLY" // emailed to the customer
+)
+
+// InquiryMessage is one entry of an inquiry's conversation thread.
+type InquiryMessage struct {
+	ID          int64     `json:"id"`
+	InquiryID   int64     `json:"inquiry_id"`
+	Kind        string    `json:"kind"`
+	Body        string    `json:"body"`
+	AuthorID    *int64    `json:"author_id"`
+	AuthorName  string    `json:"author_name"`
+	Recipient   string    `json:"recipient,omitempty"`
+	EmailStatus string    `json:"email_status,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// InquiryAssignment is one entry of an inquiry's assignment history. AssignedTo is nil for an unassignment.
+type InquiryAssignment struct {
+	ID               int64     `json:"id"`
+	InquiryID        int64     `json:"inquiry_id"`
+	PreviousAssignee *int64    `json:"previous_assignee"`
+	AssignedTo       *int64    `json:"assigned_to"`
+	AssigneeName     string    `json:"assignee_name"`
+	AssignedBy       *int64    `json:"assigned_by"`
+	AssignedByName   string    `json:"assigned_by_name"`
+	Note             string    `json:"note"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+// InquiryStatus is a configurable status of the inquiry workflow (table inquiry_statuses).
+type InquiryStatus struct {
+	Code      string `json:"code"`
+	Label     string `json:"label"`
+	IsInitial bool   `json:"is_initial"`
+	IsClosed  bool   `json:"is_closed"`
+	SortOrder int    `json:"sort_order"`
+}
+
+// InquiryTransition allows an inquiry to move From one status To another.
+type InquiryTransition struct {
+	From string `json:"from"`
+	To   string `json:"to"`
+}
+
+// InquiryWorkflow is the full set of statuses and allowed transitions.
+type InquiryWorkflow struct {
+	Statuses    []InquiryStatus     `json:"statuses"`
+	Transitions []InquiryTransition `json:"transitions"`
+}
+
+// Allows reports whether the workflow permits changing status from -> to.
+func (wf *InquiryWorkflow) Allows(from, to string) bool {
+	for _, t := range wf.Transitions {
+		if t.From == from && t.To == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Status returns the status with the given code, or nil if the workflow does not define it.
+func (wf *InquiryWorkflow) Status(code string) *InquiryStatus {
+	for i := range wf.Statuses {
+		if wf.Statuses[i].Code == code {
+			return &wf.Statuses[i]
+		}
+	}
+	return nil
+}
+
+// Next returns the statuses an inquiry in status from may move to.
+func (wf *InquiryWorkflow) Next(from string) []string {
+	next := []string{}
+	for _, t := range wf.Transitions {
+		if t.From == from {
+			next = append(next, t.To)
+		}
+	}
+	return next
+}
+
+// InitialStatus returns the status of a newly submitted inquiry.
+func (wf *InquiryWorkflow) InitialStatus() string {
+	for _, s := range wf.Statuses {
+		if s.IsInitial {
+			return s.Code
+		}
+	}
+	return "NEW"
+}
+
+// InquiryStatusChange is one entry of an inquiry's status history. From is empty for the initial status.
+type InquiryStatusChange struct {
+	ID            int64     `json:"id"`
+	InquiryID     int64     `json:"inquiry_id"`
+	From          string    `json:"from_status"`
+	To            string    `json:"to_status"`
+	ChangedBy     *int64    `json:"changed_by"`
+	ChangedByName string    `json:"changed_by_name"`
+	Comment       string    `json:"comment"`
+	CreatedAt     time.Time `json:"created_at"`
+}
+
+// InquiryAttachment is a file sent with an inquiry. StoredName is the file name on disk and never
+// leaves the server.
+type InquiryAttachment struct {
+	ID          int64     `json:"id"`
+	InquiryID   int64     `json:"inquiry_id"`
+	FileName    string    `json:"file_name"`
+	StoredName  string    `json:"-"`
+	ContentType string    `json:"content_type"`
+	Size        int64     `json:"size"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// InquiryExportRow is an inquiry with a summary of its status history, as written by the export.
+type InquiryExportRow struct {
+	Inquiry
+	StatusChanges    int        // number of changes after the initial status
+	LastStatusChange *time.Time // when the current status was set
+	LastChangedBy    string     // who set the current status, empty for the system
+	StatusHistory    string     // every status with its time, author and comment, oldest first
+}
+
+// InquiryVolume is the number of inquiries received in the period starting at Period.
+type InquiryVolume struct {
+	Period time.Time `json:"period"`
+	Count  int       `json:"count"`
+}
+
+// InquiryPerformance holds the response and resolution figures of a group of inquiries.
+// Averages are in hours and nil when no inquiry of the group got that far.
+type InquiryPerformance struct {
+	Total                 int      `json:"total"`
+	Responded             int      `json:"responded"`
+	AvgFirstResponseHours *float64 `json:"avg_first_response_hours"`
+	Resolved              int      `json:"resolved"`
+	AvgResolutionHours    *float64 `json:"avg_resolution_hours"`
+	Converted             int      `json:"converted"`
+}
+
+// InquirySubjectStats is the performance of the inquiries with one subject (the service asked about).
+type InquirySubjectStats struct {
+	Subject string `json:"subject"`
+	InquiryPerformance
+}
+
+// InquiryStats is the analytics report of the inquiries received in [From, To).
+type InquiryStats struct {
+	From        time.Time             `json:"from"`
+	To          time.Time             `json:"to"`
+	IncludeSpam bool                  `json:"include_spam"`
+	Daily       []InquiryVolume       `json:"daily"`
+	Weekly      []InquiryVolume       `json:"weekly"` // weeks start on Monday
+	Monthly     []InquiryVolume       `json:"monthly"`
+	Overall     InquiryPerformance    `json:"overall"`
+	BySubject   []InquirySubjectStats `json:"by_subject"`
+	ByStatus    map[string]int        `json:"by_status"`
+}
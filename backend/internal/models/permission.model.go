@@ -0,0 +1,78 @@
+package models
+
+// Permission is a single action a role may perform, e.g. "inquiry:read".
+type Permission string
+
+const (
+	PermInquiryRead     Permission = "inquiry:read"
+	PermInquiryWrite    Permission = "inquiry:write"
+	PermInquiryWorkflow Permission = "inquiry:workflow"
+	PermClientWrite     Permission = "client:write"
+	PermMemberWrite     Permission = "member:write"
+	PermTeamWrite       Permission = "team:write"
+	PermGalleryWrite    Permission = "gallery:write"
+	PermUserManage      Permission = "user:manage"
+	PermEmailRead       Permission = "email:read"
+	PermEmailWrite      Permission = "email:write"
+	PermWebhookManage   Permission = "webhook:manage"
+	PermProductWrite    Permission = "product:write"
+	PermQuotationRead   Permission = "quotation:read"
+	PermQuotationWrite  Permission = "quotation:write"
+)
+
+// AllPermissions lists every permission known to the application.
+var AllPermissions = []Permission{
+	PermInquiryRead,
+	PermInquiryWrite,
+	PermInquiryWorkflow,
+	PermClientWrite,
+	PermMemberWrite,
+	PermTeamWrite,
+	PermGalleryWrite,
+	PermUserManage,
+	PermEmailRead,
+	PermEmailWrite,
+	PermWebhookManage,
+	PermProductWrite,
+	PermQuotationRead,
+	PermQuotationWrite,
+}
+
+// RolePermissions maps each role (users.role) to the permissions it grants.
+// A role that is not listed here has no permissions and cannot sign in.
+var RolePermissions = map[string][]Permission{
+	"Admin": AllPermissions,
+	"Editor": {
+		PermInquiryRead,
+		PermClientWrite,
+		PermMemberWrite,
+		PermTeamWrite,
+		PermGalleryWrite,
+		PermProductWrite,
+	},
+	"Sales": {
+		PermInquiryRead,
+		PermInquiryWrite,
+		PermClientWrite,
+		PermQuotationRead,
+		PermQuotationWrite,
+	},
+	"Operator": {
+		PermInquiryRead,
+	},
+}
+
+// PermissionsFor returns the permissions granted to role.
+func PermissionsFor(role string) []Permission {
+	return RolePermissions[role]
+}
+
+// HasPermission reports whether role grants the permission p.
+func HasPermission(role string, p Permission) bool {
+	for _, granted := range RolePermissions[role] {
+		if granted == p {
+			return true
+		}
+	}
+	return false
+}
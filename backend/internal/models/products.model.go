@@ -0,0 +1,104 @@
+package models
+
+import "time"
+
+// Product is an entry of the product catalogue (table products).
+// Datasheet is the current version of its spec sheet, if any.
+type Product struct {
+	ID             int64             `json:"id"`
+	Code           string            `json:"code"` // SKU / catalogue number
+	Name           string            `json:"name"`
+	CategoryID     *int64            `json:"category_id"`
+	Category       *CatalogueRef     `json:"category"`
+	BrandID        *int64            `json:"brand_id"`
+	Brand          *CatalogueRef     `json:"brand"`
+	Description    string            `json:"description"`
+	Specifications []ProductSpec     `json:"specifications"`
+	Images         []ProductImage    `json:"images"`
+	Datasheet      *ProductDatasheet `json:"datasheet"`
+	IsActive       bool              `json:"is_active"`
+	SortOrder      int               `json:"sort_order"`
+	CreatedAt      time.Time         `json:"created_at"`
+	UpdatedAt      time.Time         `json:"updated_at"`
+}
+
+// ProductSpec is one line of a product's specification table, e.g. { "Certificate", "UL Listed" }.
+type ProductSpec struct {
+	Label string `json:"label"`
+	Value string `json:"value"`
+}
+
+// ProductImage is an image of a product (table product_images). ImageLink is the file name
+// under /api/v1/images/products/.
+type ProductImage struct {
+	ID        int64     `json:"id"`
+	ProductID int64     `json:"product_id"`
+	ImageLink string    `json:"image_link"`
+	AltText   string    `json:"alt_text"`
+	SortOrder int       `json:"sort_order"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ProductDatasheet is a version of a product's spec sheet (table product_datasheets).
+// StoredName is the file name on disk and never leaves the server; URL is the download path.
+type ProductDatasheet struct {
+	ID             int64     `json:"id"`
+	ProductID      int64     `json:"product_id"`
+	Version        int       `json:"version"`
+	FileName       string    `json:"file_name"`
+	StoredName     string    `json:"-"`
+	Size           int64     `json:"size"`
+	IsCurrent      bool      `json:"is_current"`
+	UploadedBy     *int64    `json:"uploaded_by,omitempty"`
+	UploadedByName string    `json:"uploaded_by_name,omitempty"`
+	URL            string    `json:"url"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+// ProductCategory is a node of the category tree (table product_categories). ImageLink is the file name
+// under /api/v1/images/categories/. ProductCount counts the active products directly in the category.
+type ProductCategory struct {
+	ID           int64              `json:"id"`
+	ParentID     *int64             `json:"parent_id"`
+	Slug         string             `json:"slug"`
+	Name         string             `json:"name"`
+	Description  string             `json:"description"`
+	ImageLink    string             `json:"image_link"`
+	SortOrder    int                `json:"sort_order"`
+	ProductCount int                `json:"product_count"`
+	Children     []*ProductCategory `json:"children,omitempty"`
+	CreatedAt    time.Time          `json:"created_at"`
+	UpdatedAt    time.Time          `json:"updated_at"`
+}
+
+// Brand is a manufacturer whose products are sold (table brands). LogoLink is the file name
+// under /api/v1/images/brands/. ProductCount counts its active products.
+type Brand struct {
+	ID           int64     `json:"id"`
+	Slug         string    `json:"slug"`
+	Name         string    `json:"name"`
+	LogoLink     string    `json:"logo_link"`
+	Website      string    `json:"website"`
+	Description  string    `json:"description"`
+	SortOrder    int       `json:"sort_order"`
+	ProductCount int       `json:"product_count"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+// CatalogueRef is the category or brand embedded in a product.
+type CatalogueRef struct {
+	ID   int64  `json:"id"`
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+}
+
+// ProductFilter narrows the product list. Zero values mean "any".
+type ProductFilter struct {
+	Category        string // slug; products of its subcategories are included
+	Brand           string // slug
+	Search          string // matches code, name and description
+	IncludeInactive bool   // staff only
+	Page            int
+	Limit           int
+}
@@ -0,0 +1,165 @@
+package models
+
+import (
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Statuses of a quotation
+const (
+	QuotationStatusDraft = "DRAFT" // editable, not yet sent to the customer
+	QuotationStatusSent  = "SENT"  // emailed to the customer, no longer editable
+)
+
+// Money is an amount in hundredths of the currency unit (e.g. poisha), so sums are exact.
+// In JSON it is a number with at most two decimals.
+type Money int64
+
+// String formats the amount with thousands separators and two decimals, e.g. 12,345.60.
+func (m Money) String() string {
+	sign := ""
+	v := int64(m)
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	units := strconv.FormatInt(v/100, 10)
+	var b strings.Builder
+	for i, c := range units {
+		if i > 0 && (len(units)-i)%3 == 0 {
+			b.WriteByte(',')
+		}
+		b.WriteRune(c)
+	}
+	return fmt.Sprintf("%s%s.%02d", sign, b.String(), v%100)
+}
+
+// MarshalJSON writes the amount as a number with two decimals.
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(strings.ReplaceAll(m.String(), ",", "")), nil
+}
+
+// UnmarshalJSON reads a number (or a numeric string) with at most two decimals.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(strings.TrimSpace(string(data)), `"`)
+	if s == "" || s == "null" {
+		*m = 0
+		return nil
+	}
+
+	negative := strings.HasPrefix(s, "-")
+	s = strings.TrimPrefix(s, "-")
+	units, cents, hasCents := strings.Cut(s, ".")
+	digits := func(v string) bool { return strings.Trim(v, "0123456789") == "" }
+	if units == "" || len(cents) > 2 || (hasCents && cents == "") || !digits(units) || !digits(cents) {
+		return errors.New("amounts must be numbers with at most two decimals")
+	}
+	cents += strings.Repeat("0", 2-len(cents))
+
+	u, err := strconv.ParseInt(units, 10, 64)
+	if err != nil || u > math.MaxInt64/100-1 {
+		return errors.New("amounts must be numbers with at most two decimals")
+	}
+	c, err := strconv.ParseInt(cents, 10, 64)
+	if err != nil {
+		return errors.New("amounts must be numbers with at most two decimals")
+	}
+
+	*m = Money(u*100 + c)
+	if negative {
+		*m = -*m
+	}
+	return nil
+}
+
+// Value stores the amount as an integer; without it the database driver would use String.
+func (m Money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
+
+// PercentOf returns p percent of m, rounded to the nearest hundredth.
+func (m Money) PercentOf(p float64) Money {
+	return Money(math.Round(float64(m) * p / 100))
+}
+
+// Quotation is a priced offer sent to a customer, optionally for an RFQ or an inquiry.
+type Quotation struct {
+	ID          int64  `json:"id"`
+	QuotationNo string `json:"quotation_no"`
+	RfqID       *int64 `json:"rfq_id"`
+	InquiryID   *int64 `json:"inquiry_id"`
+
+	CustomerName    string `json:"customer_name"`
+	CustomerCompany string `json:"customer_company"`
+	CustomerEmail   string `json:"customer_email"`
+	CustomerMobile  string `json:"customer_mobile"`
+	CustomerAddress string `json:"customer_address"`
+	Subject         string `json:"subject"`
+	Reference       string `json:"reference"` // the customer's reference, e.g. the RFQ or inquiry number
+
+	IssueDate  time.Time       `json:"issue_date"`
+	ValidUntil *time.Time      `json:"valid_until"`
+	Currency   string          `json:"currency"`
+	Items      []QuotationItem `json:"items"`
+
+	// Subtotal, Discount (when DiscountPercent is set), Vat and Total are computed by Calculate
+	Subtotal        Money   `json:"subtotal"`
+	DiscountPercent float64 `json:"discount_percent"`
+	Discount        Money   `json:"discount"`
+	VatRate         float64 `json:"vat_rate"`
+	Vat             Money   `json:"vat"`
+	Total           Money   `json:"total"`
+
+	Notes         string     `json:"notes"`
+	Terms         string     `json:"terms"`
+	Status        string     `json:"status"`
+	PdfFile       string     `json:"-"`
+	SentAt        *time.Time `json:"sent_at"`
+	SentTo        string     `json:"sent_to"`
+	CreatedBy     *int64     `json:"created_by"`
+	CreatedByName string     `json:"created_by_name"`
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// QuotationItem is one priced line of a quotation. Amount is Quantity x UnitPrice.
+type QuotationItem struct {
+	ID          int64  `json:"id"`
+	QuotationID int64  `json:"quotation_id"`
+	ProductID   *int64 `json:"product_id"`
+	ProductCode string `json:"product_code"`
+	Description string `json:"description"`
+	Quantity    int    `json:"quantity"`
+	Unit        string `json:"unit"`
+	UnitPrice   Money  `json:"unit_price"`
+	Amount      Money  `json:"amount"`
+}
+
+// Calculate computes the item amounts and the totals. A discount percentage takes precedence over a fixed
+// Discount; VAT is charged on the discounted subtotal.
+func (q *Quotation) Calculate() {
+	q.Subtotal = 0
+	for i := range q.Items {
+		q.Items[i].Amount = Money(q.Items[i].Quantity) * q.Items[i].UnitPrice
+		q.Subtotal += q.Items[i].Amount
+	}
+	if q.DiscountPercent > 0 {
+		q.Discount = q.Subtotal.PercentOf(q.DiscountPercent)
+	}
+	q.Vat = (q.Subtotal - q.Discount).PercentOf(q.VatRate)
+	q.Total = q.Subtotal - q.Discount + q.Vat
+}
+
+// QuotationFilter holds the paging, filter and search options of the quotation list.
+type QuotationFilter struct {
+	PageIndex  int    // 1-based page number
+	PageLength int    // rows per page
+	Status     string // exact status, optional
+	RfqID      *int64 // quotations for this RFQ, optional
+	InquiryID  *int64 // quotations for this inquiry, optional
+	Search     string // matches the number, customer and subject, optional
+}
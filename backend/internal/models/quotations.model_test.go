@@ -0,0 +1,104 @@
+package models
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Money
+		wantErr bool
+	}{
+		{`0`, 0, false},
+		{`12`, 1200, false},
+		{`12.5`, 1250, false},
+		{`12.05`, 1205, false},
+		{`"12.50"`, 1250, false},
+		{`-0.5`, -50, false},
+		{`null`, 0, false},
+		{`""`, 0, false},
+		{`92233720368547757.99`, 9223372036854775799, false},
+		{`1.`, 0, true},
+		{`.5`, 0, true},
+		{`1.234`, 0, true},
+		{`-`, 0, true},
+		{`+1`, 0, true},
+		{`1e3`, 0, true},
+		{`1,000`, 0, true},
+		{`92233720368547758`, 0, true},
+		{`99999999999999999999`, 0, true},
+	}
+	for _, tt := range tests {
+		var m Money
+		err := json.Unmarshal([]byte(tt.in), &m)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Unmarshal(%s) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && m != tt.want {
+			t.Errorf("Unmarshal(%s) = %d, want %d", tt.in, m, tt.want)
+		}
+	}
+}
+
+func TestMoneyString(t *testing.T) {
+	tests := []struct {
+		m    Money
+		want string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{-50, "-0.50"},
+		{123456, "1,234.56"},
+		{100000000, "1,000,000.00"},
+	}
+	for _, tt := range tests {
+		if got := tt.m.String(); got != tt.want {
+			t.Errorf("Money(%d).String() = %q, want %q", tt.m, got, tt.want)
+		}
+		if got, _ := tt.m.MarshalJSON(); string(got) != strings.ReplaceAll(tt.want, ",", "") {
+			t.Errorf("Money(%d).MarshalJSON() = %s", tt.m, got)
+		}
+	}
+}
+
+func TestQuotationCalculate(t *testing.T) {
+	tests := []struct {
+		name                     string
+		discountPercent, vatRate float64
+		discount                 Money
+		subtotal, wantDiscount   Money
+		wantVat, wantTotal       Money
+	}{
+		// 2 x 1,000.00 + 3 x 33.33 = 2,099.99
+		{"no discount or VAT", 0, 0, 0, 209999, 0, 0, 209999},
+		{"VAT only", 0, 15, 0, 209999, 0, 31500, 241499},
+		{"fixed discount", 0, 15, 9999, 209999, 9999, 30000, 230000},
+		{"percentage wins over fixed discount", 10, 15, 9999, 209999, 21000, 28350, 217349},
+		{"two-decimal rates", 12.5, 7.25, 0, 209999, 26250, 13322, 197071},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			q := &Quotation{
+				Items: []QuotationItem{
+					{Quantity: 2, UnitPrice: 100000},
+					{Quantity: 3, UnitPrice: 3333},
+				},
+				DiscountPercent: tt.discountPercent,
+				Discount:        tt.discount,
+				VatRate:         tt.vatRate,
+			}
+			q.Calculate()
+			if q.Items[0].Amount != 200000 || q.Items[1].Amount != 9999 {
+				t.Errorf("item amounts = %d, %d, want 200000, 9999", q.Items[0].Amount, q.Items[1].Amount)
+			}
+			if q.Subtotal != tt.subtotal || q.Discount != tt.wantDiscount || q.Vat != tt.wantVat || q.Total != tt.wantTotal {
+				t.Errorf("got subtotal %d, discount %d, vat %d, total %d; want %d, %d, %d, %d",
+					q.Subtotal, q.Discount, q.Vat, q.Total, tt.subtotal, tt.wantDiscount, tt.wantVat, tt.wantTotal)
+			}
+		})
+	}
+}
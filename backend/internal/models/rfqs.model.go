@@ -0,0 +1,99 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// Statuses of the request-for-quotation workflow
+const (
+	RfqStatusNew      = "NEW"
+	RfqStatusQuoted   = "QUOTED"
+	RfqStatusAccepted = "ACCEPTED"
+	RfqStatusRejected = "REJECTED"
+)
+
+// RfqStatuses lists the RFQ statuses in workflow order.
+var RfqStatuses = []string{RfqStatusNew, RfqStatusQuoted, RfqStatusAccepted, RfqStatusRejected}
+
+// rfqTransitions maps each RFQ status to the statuses it may move to. ACCEPTED is final;
+// a rejected request can be reopened.
+var rfqTransitions = map[string][]string{
+	RfqStatusNew:      {RfqStatusQuoted, RfqStatusRejected},
+	RfqStatusQuoted:   {RfqStatusAccepted, RfqStatusRejected},
+	RfqStatusAccepted: {},
+	RfqStatusRejected: {RfqStatusNew},
+}
+
+// RfqNextStatuses returns the statuses an RFQ in status from may move to.
+func RfqNextStatuses(from string) []string {
+	return rfqTransitions[from]
+}
+
+// RfqAllows reports whether an RFQ may change status from -> to.
+func RfqAllows(from, to string) bool {
+	for _, s := range rfqTransitions[from] {
+		if s == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Rfq is a request for quotation: a list of catalogue products with quantities sent by a visitor.
+type Rfq struct {
+	ID      int64  `json:"id"`
+	Name    string `json:"name"`
+	Company string `json:"company"`
+	Email   string `json:"email"`
+	Mobile  string `json:"mobile"`
+	Message string `json:"message"`
+	Status  string `json:"status"`
+	// IsSpam, SpamScore and SpamReasons are set by the spam scorer when the request is submitted
+	IsSpam      bool      `json:"is_spam"`
+	SpamScore   int       `json:"spam_score"`
+	SpamReasons string    `json:"spam_reasons"`
+	IPAddress   string    `json:"ip_address"`
+	ItemCount   int       `json:"item_count"`
+	Items       []RfqItem `json:"items"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// RfqItem is one line of an RFQ. ProductCode and ProductName are copied from the product on submission;
+// ProductID becomes nil when the product is deleted.
+type RfqItem struct {
+	ID          int64  `json:"id"`
+	RfqID       int64  `json:"rfq_id"`
+	ProductID   *int64 `json:"product_id"`
+	ProductCode string `json:"product_code"`
+	ProductName string `json:"product_name"`
+	Quantity    int    `json:"quantity"`
+	Note        string `json:"note"`
+}
+
+// ReferenceNo is the customer-facing reference of the request, e.g. RFQ-20250131-000123.
+func (q *Rfq) ReferenceNo() string {
+	return fmt.Sprintf("RFQ-%s-%06d", q.CreatedAt.Format("20060102"), q.ID)
+}
+
+// RfqFilter holds the paging, filter and search options of the RFQ list.
+type RfqFilter struct {
+	PageIndex  int    // 1-based page number
+	PageLength int    // rows per page
+	Status     string // exact status, optional
+	Search     string // matches name, company, email, mobile, message and the item products, optional
+	Spam       bool   // list only the requests flagged as spam instead of the others
+}
+
+// RfqStatusChange is one entry of an RFQ's status history. From is empty for the initial status.
+type RfqStatusChange struct {
+	ID            int64     `json:"id"`
+	RfqID         int64     `json:"rfq_id"`
+	From          string    `json:"from_status"`
+	To            string    `json:"to_status"`
+	ChangedBy     *int64    `json:"changed_by"`
+	ChangedByName string    `json:"changed_by_name"`
+	Comment       string    `json:"comment"`
+	CreatedAt     time.Time `json:"created_at"`
+}
@@ -0,0 +1,146 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// Events sent to webhook endpoints
+const (
+	WebhookEventInquiryCreated       = "inquiry.created"
+	WebhookEventInquiryStatusChanged = "inquiry.status_changed"
+	WebhookEventInquiryAssigned      = "inquiry.assigned"
+	WebhookEventInquiryConverted     = "inquiry.converted"
+	WebhookEventClientCreated        = "client.created"
+	WebhookEventClientUpdated        = "client.updated"
+	WebhookEventClientDeleted        = "client.deleted"
+	WebhookEventMemberCreated        = "member.created"
+	WebhookEventMemberUpdated        = "member.updated"
+	WebhookEventMemberDeleted        = "member.deleted"
+	WebhookEventTeamCreated          = "team.created"
+	WebhookEventTeamUpdated          = "team.updated"
+	WebhookEventTeamDeleted          = "team.deleted"
+	WebhookEventGalleryCreated       = "gallery.created"
+	WebhookEventGalleryUpdated       = "gallery.updated"
+	WebhookEventGalleryDeleted       = "gallery.deleted"
+	WebhookEventProductCreated       = "product.created"
+	WebhookEventProductUpdated       = "product.updated"
+	WebhookEventProductDeleted       = "product.deleted"
+	WebhookEventRfqCreated           = "rfq.created"
+	WebhookEventRfqStatusChanged     = "rfq.status_changed"
+	WebhookEventQuotationCreated     = "quotation.created"
+	WebhookEventQuotationSent        = "quotation.sent"
+
+	// WebhookEventPing is sent by the test endpoint only; it cannot be subscribed to
+	WebhookEventPing = "ping"
+	// WebhookEventAll subscribes an endpoint to every event
+	WebhookEventAll = "*"
+)
+
+// WebhookEvents lists the events an endpoint can subscribe to.
+var WebhookEvents = []string{
+	WebhookEventInquiryCreated,
+	WebhookEventInquiryStatusChanged,
+	WebhookEventInquiryAssigned,
+	WebhookEventInquiryConverted,
+	WebhookEventClientCreated,
+	WebhookEventClientUpdated,
+	WebhookEventClientDeleted,
+	WebhookEventMemberCreated,
+	WebhookEventMemberUpdated,
+	WebhookEventMemberDeleted,
+	WebhookEventTeamCreated,
+	WebhookEventTeamUpdated,
+	WebhookEventTeamDeleted,
+	WebhookEventGalleryCreated,
+	WebhookEventGalleryUpdated,
+	WebhookEventGalleryDeleted,
+	WebhookEventProductCreated,
+	WebhookEventProductUpdated,
+	WebhookEventProductDeleted,
+	WebhookEventRfqCreated,
+	WebhookEventRfqStatusChanged,
+	WebhookEventQuotationCreated,
+	WebhookEventQuotationSent,
+}
+
+// Delivery states of a webhook_deliveries row
+const (
+	WebhookStatusPending   = "PENDING"
+	WebhookStatusSucceeded = "SUCCEEDED"
+	WebhookStatusFailed    = "FAILED"
+)
+
+// WebhookEndpoint is a URL registered to receive events (table webhook_endpoints).
+// The signing secret is only returned when the endpoint is created or the secret rotated.
+type WebhookEndpoint struct {
+	ID          int64     `json:"id"`
+	URL         string    `json:"url"`
+	Description string    `json:"description"`
+	Events      []string  `json:"events"`
+	Secret      string    `json:"-"`
+	Active      bool      `json:"active"`
+	CreatedBy   *int64    `json:"createdBy"`
+	CreatedAt   time.Time `json:"createdAt"`
+	UpdatedAt   time.Time `json:"updatedAt"`
+}
+
+// Subscribes reports whether the endpoint receives event.
+func (e *WebhookEndpoint) Subscribes(event string) bool {
+	for _, ev := range e.Events {
+		if ev == event || ev == WebhookEventAll {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookDelivery is one event sent to one endpoint (table webhook_deliveries), with the outcome
+// of the latest attempt. Payload is the exact JSON body that is signed and posted.
+type WebhookDelivery struct {
+	ID             int64           `json:"id"`
+	EndpointID     int64           `json:"endpointId"`
+	EndpointURL    string          `json:"endpointUrl"`
+	Event          string          `json:"event"`
+	EventID        string          `json:"eventId"`
+	Payload        json.RawMessage `json:"payload,omitempty"`
+	Status         string          `json:"status"`
+	Attempts       int             `json:"attempts"`
+	NextAttemptAt  *time.Time      `json:"nextAttemptAt,omitempty"`
+	LastAttemptAt  *time.Time      `json:"lastAttemptAt,omitempty"`
+	ResponseStatus *int            `json:"responseStatus,omitempty"`
+	ResponseBody   string          `json:"responseBody,omitempty"`
+	Error          string          `json:"error,omitempty"`
+	DurationMS     int             `json:"durationMs"`
+	ReplayOf       *int64          `json:"replayOf,omitempty"`
+	DeliveredAt    *time.Time      `json:"deliveredAt,omitempty"`
+	CreatedAt      time.Time       `json:"createdAt"`
+}
+
+// WebhookJob is a claimed delivery together with the endpoint details needed to send it.
+type WebhookJob struct {
+	Delivery WebhookDelivery
+	URL      string
+	Secret   string
+}
+
+// WebhookAttempt is the outcome of one delivery attempt.
+// NextAttemptAt is set when the delivery stays PENDING for another attempt.
+type WebhookAttempt struct {
+	DeliveryID     int64
+	Status         string
+	ResponseStatus *int
+	ResponseBody   string
+	Error          string
+	DurationMS     int
+	NextAttemptAt  *time.Time
+}
+
+// WebhookDeliveryFilter narrows the delivery log. Zero values mean "any".
+type WebhookDeliveryFilter struct {
+	EndpointID int64
+	Status     string
+	Event      string
+	Page       int
+	Limit      int
+}
@@ -0,0 +1,304 @@
+// Package pdf writes simple PDF documents: text in the standard Helvetica fonts, lines and filled rectangles
+// on A4 pages. Nothing is embedded, so every PDF reader can display the result and files stay small.
+//
+// Coordinates are in points (1/72 inch) measured from the top-left corner of the page; y of Text is the baseline.
+package pdf
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// A4 page size in points
+const (
+	PageWidth  = 595.28
+	PageHeight = 841.89
+)
+
+// Font selects one of the standard fonts.
+type Font int
+
+const (
+	Regular Font = iota // Helvetica
+	Bold                // Helvetica-Bold
+)
+
+// Color is an RGB color.
+type Color struct {
+	R, G, B uint8
+}
+
+// Common colors
+var (
+	Black = Color{0, 0, 0}
+	White = Color{255, 255, 255}
+)
+
+func (c Color) operands() string {
+	return fmt.Sprintf("%.3f %.3f %.3f", float64(c.R)/255, float64(c.G)/255, float64(c.B)/255)
+}
+
+// Document is a PDF under construction. Draw on the current page, then call WriteTo.
+type Document struct {
+	pages     []*bytes.Buffer // content stream of every page
+	page      *bytes.Buffer   // content stream of the current page
+	font      Font
+	size      float64
+	textColor Color
+	fillColor Color
+	lineColor Color
+	title     string
+	author    string
+}
+
+// New returns an empty document with one page. The font is Helvetica 10 pt in black.
+func New() *Document {
+	d := &Document{font: Regular, size: 10, textColor: Black, fillColor: Black, lineColor: Black}
+	d.AddPage()
+	return d
+}
+
+// SetInfo sets the title and author shown in the document properties.
+func (d *Document) SetInfo(title, author string) {
+	d.title, d.author = title, author
+}
+
+// AddPage starts a new page and makes it the current one.
+func (d *Document) AddPage() {
+	d.page = &bytes.Buffer{}
+	d.pages = append(d.pages, d.page)
+}
+
+// PageCount returns the number of pages.
+func (d *Document) PageCount() int {
+	return len(d.pages)
+}
+
+// SetPage makes page i (0-based) the current page again, e.g. to add footers once the page count is known.
+func (d *Document) SetPage(i int) {
+	d.page = d.pages[i]
+}
+
+// SetFont selects the font and size (in points) of the following text.
+func (d *Document) SetFont(f Font, size float64) {
+	d.font, d.size = f, size
+}
+
+// SetTextColor sets the color of the following text.
+func (d *Document) SetTextColor(c Color) {
+	d.textColor = c
+}
+
+// SetFillColor sets the color of the following filled rectangles.
+func (d *Document) SetFillColor(c Color) {
+	d.fillColor = c
+}
+
+// SetLineColor sets the color of the following lines.
+func (d *Document) SetLineColor(c Color) {
+	d.lineColor = c
+}
+
+// Text draws s with its baseline starting at (x, y).
+func (d *Document) Text(x, y float64, s string) {
+	fmt.Fprintf(d.page, "BT %s rg /F%d %.2f Tf %.2f %.2f Td (%s) Tj ET\n",
+		d.textColor.operands(), d.font+1, d.size, x, PageHeight-y, escape(encode(s)))
+}
+
+// TextRight draws s so that it ends at x.
+func (d *Document) TextRight(x, y float64, s string) {
+	d.Text(x-d.Width(s), y, s)
+}
+
+// Rect fills the rectangle with the top-left corner (x, y), width w and height h.
+func (d *Document) Rect(x, y, w, h float64) {
+	fmt.Fprintf(d.page, "%s rg %.2f %.2f %.2f %.2f re f\n", d.fillColor.operands(), x, PageHeight-y-h, w, h)
+}
+
+// Line draws a straight line of the given width from (x1, y1) to (x2, y2).
+func (d *Document) Line(x1, y1, x2, y2, width float64) {
+	fmt.Fprintf(d.page, "%s RG %.2f w %.2f %.2f m %.2f %.2f l S\n",
+		d.lineColor.operands(), width, x1, PageHeight-y1, x2, PageHeight-y2)
+}
+
+// Width returns the width of s in points in the current font and size.
+func (d *Document) Width(s string) float64 {
+	widths := &helveticaWidths
+	if d.font == Bold {
+		widths = &helveticaBoldWidths
+	}
+	total := 0
+	encoded := encode(s)
+	for i := 0; i < len(encoded); i++ {
+		if c := encoded[i]; c >= 32 && c <= 126 {
+			total += widths[c-32]
+		} else {
+			total += 556
+		}
+	}
+	return float64(total) * d.size / 1000
+}
+
+// Wrap splits s into lines no wider than width in the current font. Line breaks in s are kept;
+// a word longer than a line is cut.
+func (d *Document) Wrap(s string, width float64) []string {
+	var lines []string
+	for _, paragraph := range strings.Split(strings.ReplaceAll(s, "\r\n", "\n"), "\n") {
+		line := ""
+		for _, word := range strings.Fields(paragraph) {
+			candidate := word
+			if line != "" {
+				candidate = line + " " + word
+			}
+			if d.Width(candidate) <= width {
+				line = candidate
+				continue
+			}
+			if line != "" {
+				lines = append(lines, line)
+			}
+			// cut words that do not fit on a line of their own
+			for d.Width(word) > width {
+				runes := []rune(word)
+				n := len(runes) - 1
+				for n > 1 && d.Width(string(runes[:n])) > width {
+					n--
+				}
+				lines = append(lines, string(runes[:n]))
+				word = string(runes[n:])
+			}
+			line = word
+		}
+		lines = append(lines, line)
+	}
+	return lines
+}
+
+// WriteTo writes the finished document to w.
+func (d *Document) WriteTo(w io.Writer) (int64, error) {
+	var b bytes.Buffer
+	var offsets []int
+
+	// objects are numbered from 1 in the order they are written
+	object := func(body string) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+	stream := func(dict string, data []byte) {
+		offsets = append(offsets, b.Len())
+		fmt.Fprintf(&b, "%d 0 obj\n<< %s /Length %d >>\nstream\n", len(offsets), dict, len(data))
+		b.Write(data)
+		b.WriteString("\nendstream\nendobj\n")
+	}
+
+	// 1 catalog, 2 page tree, 3-4 fonts, 5 info, then a page and its content stream for every page
+	const firstPage = 6
+	kids := make([]string, len(d.pages))
+	for i := range d.pages {
+		kids[i] = fmt.Sprintf("%d 0 R", firstPage+2*i)
+	}
+
+	b.WriteString("%PDF-1.4\n%\xe2\xe3\xcf\xd3\n")
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(d.pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+	object(fmt.Sprintf("<< /Title (%s) /Author (%s) /Producer (ajfses) /CreationDate (D:%s) >>",
+		escape(encode(d.title)), escape(encode(d.author)), time.Now().Format("20060102150405")))
+
+	for i, content := range d.pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] "+
+			"/Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>",
+			PageWidth, PageHeight, firstPage+2*i+1))
+
+		var compressed bytes.Buffer
+		zw := zlib.NewWriter(&compressed)
+		zw.Write(content.Bytes())
+		zw.Close()
+		stream("/Filter /FlateDecode", compressed.Bytes())
+	}
+
+	xref := b.Len()
+	fmt.Fprintf(&b, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, off := range offsets {
+		fmt.Fprintf(&b, "%010d 00000 n \n", off)
+	}
+	fmt.Fprintf(&b, "trailer\n<< /Size %d /Root 1 0 R /Info 5 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return b.WriteTo(w)
+}
+
+// winAnsi maps the characters of Windows-1252 outside Latin-1 to their codes.
+var winAnsi = map[rune]byte{
+	'€': 0x80, '‚': 0x82, 'ƒ': 0x83, '„': 0x84, '…': 0x85, '†': 0x86, '‡': 0x87, 'ˆ': 0x88, '‰': 0x89,
+	'Š': 0x8a, '‹': 0x8b, 'Œ': 0x8c, 'Ž': 0x8e, '‘': 0x91, '’': 0x92, '“': 0x93, '”': 0x94, '•': 0x95,
+	'–': 0x96, '—': 0x97, '˜': 0x98, '™': 0x99, 'š': 0x9a, '›': 0x9b, 'œ': 0x9c, 'ž': 0x9e, 'Ÿ': 0x9f,
+}
+
+// encode converts s to the WinAnsi encoding of the standard fonts; other characters become '?'.
+// Callers check their text with Unsupported first.
+func encode(s string) string {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			b = append(b, ' ')
+		case r >= 32 && r <= 126, r >= 0xa0 && r <= 0xff:
+			b = append(b, byte(r))
+		case winAnsi[r] != 0:
+			b = append(b, winAnsi[r])
+		default:
+			b = append(b, '?')
+		}
+	}
+	return string(b)
+}
+
+// Unsupported returns the first character of s that the standard fonts cannot print, and false when there is none.
+// Tabs and line breaks count as printable because they are drawn as spaces.
+func Unsupported(s string) (rune, bool) {
+	for _, r := range s {
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+		case r >= 32 && r <= 126, r >= 0xa0 && r <= 0xff:
+		case winAnsi[r] != 0:
+		default:
+			return r, true
+		}
+	}
+	return 0, false
+}
+
+// escape escapes the characters with a special meaning in a PDF string literal.
+func escape(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "(", `\(`, ")", `\)`).Replace(s)
+}
+
+// Advance widths of the characters 32-126 in 1/1000 of the font size (from the Adobe font metrics).
+var helveticaWidths = [95]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, // 0-9
+	278, 278, 584, 584, 584, 556, 1015, // : to @
+	667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, // A-M
+	722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, // N-Z
+	278, 278, 278, 469, 556, 333, // [ to `
+	556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, // a-m
+	556, 556, 556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, // n-z
+	334, 260, 334, 584, // { to ~
+}
+
+var helveticaBoldWidths = [95]int{
+	278, 333, 474, 556, 556, 889, 722, 238, 333, 333, 389, 584, 278, 333, 278, 278, // space to /
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, // 0-9
+	333, 333, 584, 584, 584, 611, 975, // : to @
+	722, 722, 722, 722, 667, 611, 778, 722, 278, 556, 722, 611, 833, // A-M
+	722, 778, 667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, // N-Z
+	333, 278, 333, 584, 556, 333, // [ to `
+	556, 611, 556, 611, 556, 333, 611, 611, 278, 278, 556, 278, 889, // a-m
+	611, 611, 611, 611, 389, 556, 333, 611, 556, 778, 556, 556, 500, // n-z
+	389, 280, 389, 584, // { to ~
+}
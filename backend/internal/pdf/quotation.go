@@ -0,0 +1,271 @@
+package pdf
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+// Colors of the company branding
+var (
+	brandRed   = Color{185, 28, 28}
+	mutedGray  = Color{107, 114, 128}
+	lightGray  = Color{243, 244, 246}
+	borderGray = Color{229, 231, 235}
+)
+
+// Layout of the quotation pages
+const (
+	marginX      = 40.0
+	contentRight = PageWidth - marginX
+	footerTop    = PageHeight - 50
+	// item table columns: number, description, quantity, unit price and amount (right edges for numbers)
+	colNo          = marginX + 6
+	colDescription = marginX + 30
+	colQuantity    = 365.0
+	colUnitPrice   = 460.0
+	colAmount      = contentRight - 6
+)
+
+// WriteQuotation renders the quotation as a branded A4 PDF: the company header, the customer, the priced
+// items, the totals, notes and terms, and a footer with the page numbers on every page.
+func WriteQuotation(w io.Writer, company models.CompanyConfig, q *models.Quotation) error {
+	d := New()
+	d.SetInfo("Quotation "+q.QuotationNo, company.Name)
+
+	y := quotationHeader(d, company)
+
+	// Customer and document details side by side
+	top := y
+	d.SetTextColor(mutedGray)
+	d.SetFont(Bold, 8)
+	d.Text(marginX, y, "QUOTATION FOR")
+	y += 15
+	d.SetTextColor(Black)
+	d.SetFont(Bold, 11)
+	d.Text(marginX, y, q.CustomerName)
+	d.SetFont(Regular, 9)
+	var customer []string
+	if q.CustomerCompany != "" {
+		customer = append(customer, q.CustomerCompany)
+	}
+	if q.CustomerAddress != "" {
+		customer = append(customer, d.Wrap(q.CustomerAddress, 260)...)
+	}
+	if q.CustomerEmail != "" {
+		customer = append(customer, q.CustomerEmail)
+	}
+	if q.CustomerMobile != "" {
+		customer = append(customer, q.CustomerMobile)
+	}
+	for _, line := range customer {
+		y += 12
+		d.Text(marginX, y, line)
+	}
+
+	details := [][2]string{
+		{"Quotation No", q.QuotationNo},
+		{"Date", q.IssueDate.Format("02 Jan 2006")},
+	}
+	if q.ValidUntil != nil {
+		details = append(details, [2]string{"Valid until", q.ValidUntil.Format("02 Jan 2006")})
+	}
+	if q.Reference != "" {
+		details = append(details, [2]string{"Your reference", q.Reference})
+	}
+	dy := top
+	for _, row := range details {
+		d.SetTextColor(mutedGray)
+		d.SetFont(Regular, 9)
+		d.Text(360, dy, row[0])
+		d.SetTextColor(Black)
+		d.SetFont(Bold, 9)
+		d.TextRight(contentRight, dy, row[1])
+		dy += 14
+	}
+	if dy > y {
+		y = dy
+	}
+
+	if q.Subject != "" {
+		y += 24
+		d.SetFont(Bold, 10)
+		d.SetTextColor(Black)
+		d.Text(marginX, y, "Subject: "+q.Subject)
+	}
+
+	// Items
+	y += 20
+	y = itemTableHeader(d, y)
+	for i, item := range q.Items {
+		d.SetFont(Regular, 9)
+		lines := d.Wrap(item.Description, colQuantity-colDescription-40)
+		height := float64(len(lines))*11 + 10
+		if item.ProductCode != "" {
+			height += 10
+		}
+		if y+height > footerTop-10 {
+			d.AddPage()
+			y = itemTableHeader(d, 50)
+		}
+
+		rowY := y + 13
+		d.SetTextColor(mutedGray)
+		d.Text(colNo, rowY, strconv.Itoa(i+1))
+		d.SetTextColor(Black)
+		for j, line := range lines {
+			d.Text(colDescription, rowY+float64(j)*11, line)
+		}
+		if item.ProductCode != "" {
+			d.SetFont(Regular, 7.5)
+			d.SetTextColor(mutedGray)
+			d.Text(colDescription, rowY+float64(len(lines))*11, "Code: "+item.ProductCode)
+			d.SetFont(Regular, 9)
+			d.SetTextColor(Black)
+		}
+		quantity := strconv.Itoa(item.Quantity)
+		if item.Unit != "" {
+			quantity += " " + item.Unit
+		}
+		d.TextRight(colQuantity, rowY, quantity)
+		d.TextRight(colUnitPrice, rowY, item.UnitPrice.String())
+		d.TextRight(colAmount, rowY, item.Amount.String())
+
+		y += height
+		d.SetLineColor(borderGray)
+		d.Line(marginX, y, contentRight, y, 0.5)
+	}
+
+	// Totals
+	totals := [][2]string{{"Subtotal", q.Subtotal.String()}}
+	if q.Discount > 0 {
+		label := "Discount"
+		if q.DiscountPercent > 0 {
+			label = fmt.Sprintf("Discount (%s%%)", formatPercent(q.DiscountPercent))
+		}
+		totals = append(totals, [2]string{label, "-" + q.Discount.String()})
+	}
+	if q.VatRate > 0 {
+		totals = append(totals, [2]string{fmt.Sprintf("VAT (%s%%)", formatPercent(q.VatRate)), q.Vat.String()})
+	}
+	if y+float64(len(totals))*16+40 > footerTop-10 {
+		d.AddPage()
+		y = 50
+	}
+	y += 8
+	for _, row := range totals {
+		y += 16
+		d.SetFont(Regular, 9)
+		d.SetTextColor(mutedGray)
+		d.Text(colQuantity-40, y, row[0])
+		d.SetTextColor(Black)
+		d.TextRight(colAmount, y, row[1])
+	}
+	y += 10
+	d.SetFillColor(brandRed)
+	d.Rect(colQuantity-50, y, contentRight-colQuantity+50, 24)
+	d.SetTextColor(White)
+	d.SetFont(Bold, 11)
+	d.Text(colQuantity-40, y+16, "Total "+q.Currency)
+	d.TextRight(colAmount, y+16, q.Total.String())
+	y += 24
+
+	// Notes and terms
+	for _, block := range [][2]string{{"Notes", q.Notes}, {"Terms and conditions", q.Terms}} {
+		if strings.TrimSpace(block[1]) == "" {
+			continue
+		}
+		d.SetFont(Regular, 8.5)
+		lines := d.Wrap(block[1], contentRight-marginX)
+		y += 26
+		if y+14+float64(len(lines))*11 > footerTop-10 {
+			d.AddPage()
+			y = 50
+		}
+		d.SetFont(Bold, 9)
+		d.SetTextColor(Black)
+		d.Text(marginX, y, block[0])
+		d.SetFont(Regular, 8.5)
+		d.SetTextColor(mutedGray)
+		for _, line := range lines {
+			y += 11
+			if y > footerTop-10 {
+				d.AddPage()
+				y = 50
+			}
+			d.Text(marginX, y, line)
+		}
+	}
+
+	// Footer on every page
+	pages := d.PageCount()
+	for i := 0; i < pages; i++ {
+		d.SetPage(i)
+		d.SetLineColor(borderGray)
+		d.Line(marginX, footerTop, contentRight, footerTop, 0.5)
+		d.SetFont(Regular, 7.5)
+		d.SetTextColor(mutedGray)
+		footer := company.Name
+		if company.Website != "" {
+			footer += "  |  " + company.Website
+		}
+		d.Text(marginX, footerTop+14, footer)
+		d.TextRight(contentRight, footerTop+14, fmt.Sprintf("%s  |  Page %d of %d", q.QuotationNo, i+1, pages))
+	}
+
+	_, err := d.WriteTo(w)
+	return err
+}
+
+// quotationHeader draws the brand band with the company details and returns the y where the body starts.
+func quotationHeader(d *Document, company models.CompanyConfig) float64 {
+	d.SetFillColor(brandRed)
+	d.Rect(0, 0, PageWidth, 70)
+	d.SetTextColor(White)
+	d.SetFont(Bold, 18)
+	d.Text(marginX, 43, company.Name)
+	d.SetFont(Bold, 14)
+	d.TextRight(contentRight, 43, "QUOTATION")
+
+	y := 70.0
+	d.SetFont(Regular, 8.5)
+	d.SetTextColor(mutedGray)
+	var contact []string
+	for _, v := range []string{company.Phone, company.Email, company.Website} {
+		if v != "" {
+			contact = append(contact, v)
+		}
+	}
+	if company.Address != "" {
+		y += 16
+		d.Text(marginX, y, company.Address)
+	}
+	if len(contact) > 0 {
+		y += 12
+		d.Text(marginX, y, strings.Join(contact, "  |  "))
+	}
+
+	return y + 30
+}
+
+// itemTableHeader draws the header row of the item table at y and returns the y below it.
+func itemTableHeader(d *Document, y float64) float64 {
+	d.SetFillColor(lightGray)
+	d.Rect(marginX, y, contentRight-marginX, 20)
+	d.SetFont(Bold, 8.5)
+	d.SetTextColor(Black)
+	d.Text(colNo, y+13.5, "#")
+	d.Text(colDescription, y+13.5, "Description")
+	d.TextRight(colQuantity, y+13.5, "Qty")
+	d.TextRight(colUnitPrice, y+13.5, "Unit price")
+	d.TextRight(colAmount, y+13.5, "Amount")
+	return y + 20
+}
+
+// formatPercent formats a percentage without trailing zeros, e.g. 15 or 7.5.
+func formatPercent(p float64) string {
+	return strconv.FormatFloat(p, 'f', -1, 64)
+}
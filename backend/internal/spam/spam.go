@@ -0,0 +1,120 @@
+// Package spam scores public form submissions so that likely bot traffic can be set aside.
+package spam
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Submission is what a scorer gets to see of a public form post.
+type Submission struct {
+	Name     string
+	Email    string
+	Mobile   string
+	Subject  string
+	Message  string
+	IP       string
+	Honeypot string        // value of the hidden field that humans never fill in
+	TokenAge time.Duration // time between loading the form and submitting it
+	TokenErr error         // why the form token was rejected, nil when it is valid
+}
+
+// Result is the outcome of scoring a submission. Reasons explains each contribution to Score.
+type Result struct {
+	Score   int
+	Reasons []string
+}
+
+// Add increases the score by points for the given reason.
+func (r *Result) Add(points int, reason string) {
+	r.Score += points
+	r.Reasons = append(r.Reasons, reason)
+}
+
+// Merge adds the score and reasons of other.
+func (r *Result) Merge(other Result) {
+	r.Score += other.Score
+	r.Reasons = append(r.Reasons, other.Reasons...)
+}
+
+// Scorer rates how likely a submission is spam; higher is more likely.
+// Implementations may call external services, so they receive the request context.
+type Scorer interface {
+	Score(ctx context.Context, s *Submission) (Result, error)
+}
+
+// Scorers combines several scorers by adding up their results.
+type Scorers []Scorer
+
+// Score runs every scorer. A failing scorer is skipped; the first error is returned with the combined result.
+func (list Scorers) Score(ctx context.Context, s *Submission) (Result, error) {
+	var total Result
+	var firstErr error
+	for _, scorer := range list {
+		res, err := scorer.Score(ctx, s)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		total.Merge(res)
+	}
+	return total, firstErr
+}
+
+// DefaultKeywords are phrases that show up in nearly every bot submission and never in a real inquiry.
+var DefaultKeywords = []string{
+	"viagra", "cialis", "casino", "betting", "crypto", "bitcoin", "forex", "backlinks",
+	"seo services", "guest post", "web traffic", "rank your website", "loan offer",
+}
+
+var linkPattern = regexp.MustCompile(`(?i)(https?://|www\.)\S+|\[url=`)
+
+// HeuristicScorer applies simple built-in rules: the honeypot, the form token, links and keywords.
+type HeuristicScorer struct {
+	MinSubmitTime time.Duration // faster submissions are treated as bots
+	Keywords      []string      // lower-case phrases that count against the message
+}
+
+// NewHeuristicScorer returns a HeuristicScorer with the DefaultKeywords.
+func NewHeuristicScorer(minSubmitTime time.Duration) *HeuristicScorer {
+	return &HeuristicScorer{MinSubmitTime: minSubmitTime, Keywords: DefaultKeywords}
+}
+
+// Score implements Scorer.
+func (h *HeuristicScorer) Score(ctx context.Context, s *Submission) (Result, error) {
+	var res Result
+
+	if strings.TrimSpace(s.Honeypot) != "" {
+		res.Add(10, "honeypot field filled in")
+	}
+
+	switch {
+	case s.TokenErr != nil:
+		res.Add(5, "form token: "+s.TokenErr.Error())
+	case s.TokenAge < h.MinSubmitTime:
+		res.Add(5, "submitted "+s.TokenAge.Round(100*time.Millisecond).String()+" after loading the form")
+	}
+
+	if linkPattern.MatchString(s.Name) || linkPattern.MatchString(s.Subject) {
+		res.Add(5, "link in name or subject")
+	}
+	if links := len(linkPattern.FindAllString(s.Message, -1)); links >= 3 {
+		res.Add(3, "many links in message")
+	} else if links > 0 {
+		res.Add(1, "link in message")
+	}
+
+	text := strings.ToLower(s.Subject + " " + s.Message)
+	for _, kw := range h.Keywords {
+		if strings.Contains(text, kw) {
+			res.Add(3, "contains \""+kw+"\"")
+			break
+		}
+	}
+
+	return res, nil
+}
@@ -0,0 +1,64 @@
+package spam
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrTokenMissing = errors.New("missing")
+	ErrTokenInvalid = errors.New("invalid")
+	ErrTokenExpired = errors.New("expired")
+)
+
+// FormTokens issues and checks signed form tokens. A token records when the form was loaded, so the
+// server can tell how long the visitor took to fill it in without keeping any state.
+type FormTokens struct {
+	secret []byte
+	maxAge time.Duration
+}
+
+// NewFormTokens returns a FormTokens signing with secret; tokens older than maxAge are rejected.
+func NewFormTokens(secret string, maxAge time.Duration) *FormTokens {
+	return &FormTokens{secret: []byte(secret), maxAge: maxAge}
+}
+
+// Issue returns a token for a form loaded at now, formatted "<unix milliseconds>.<signature>".
+func (f *FormTokens) Issue(now time.Time) string {
+	ts := strconv.FormatInt(now.UnixMilli(), 10)
+	return ts + "." + f.sign(ts)
+}
+
+// Verify checks token and returns how long ago it was issued.
+func (f *FormTokens) Verify(token string, now time.Time) (time.Duration, error) {
+	if token == "" {
+		return 0, ErrTokenMissing
+	}
+	ts, sig, ok := strings.Cut(token, ".")
+	if !ok || !hmac.Equal([]byte(sig), []byte(f.sign(ts))) {
+		return 0, ErrTokenInvalid
+	}
+	ms, err := strconv.ParseInt(ts, 10, 64)
+	if err != nil {
+		return 0, ErrTokenInvalid
+	}
+	age := now.Sub(time.UnixMilli(ms))
+	if age < 0 {
+		return 0, ErrTokenInvalid
+	}
+	if age > f.maxAge {
+		return age, ErrTokenExpired
+	}
+	return age, nil
+}
+
+func (f *FormTokens) sign(ts string) string {
+	mac := hmac.New(sha256.New, f.secret)
+	mac.Write([]byte("form:" + ts))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
@@ -5,7 +5,10 @@ import (
 	"errors"
 	"io"
 	"log"
+	"math"
 	"net/http"
+	"strconv"
+	"time"
 )
 
 // readJSON read json from request body into data. It accepts a sinle JSON of 1MB max size value in the body
@@ -125,3 +128,58 @@ func Unauthorized(w http.ResponseWriter, err error) {
 		log.Printf("Error writing unauthorized response: %v", err)
 	}
 }
+
+// Forbidden sends an HTTP 403 Forbidden response: the caller is authenticated but lacks the permission.
+func Forbidden(w http.ResponseWriter, err error) {
+	resp := struct {
+		Error   bool   `json:"error"`
+		Message string `json:"message"`
+	}{
+		Error:   true,
+		Message: err.Error(),
+	}
+
+	if err := WriteJSON(w, http.StatusForbidden, resp); err != nil {
+		log.Printf("Error writing forbidden response: %v", err)
+	}
+}
+
+// TooManyRequests sends an HTTP 429 response, with a Retry-After header when retryAfter is positive.
+func TooManyRequests(w http.ResponseWriter, err error, retryAfter time.Duration) {
+	resp := struct {
+		Error   bool   `json:"error"`
+		Message string `json:"message"`
+	}{
+		Error:   true,
+		Message: err.Error(),
+	}
+
+	headers := http.Header{}
+	if retryAfter > 0 {
+		headers.Set("Retry-After", strconv.Itoa(int(math.Ceil(retryAfter.Seconds()))))
+	}
+	if err := WriteJSON(w, http.StatusTooManyRequests, resp, headers); err != nil {
+		log.Printf("Error writing too many requests response: %v", err)
+	}
+}
+
+// Locked sends an HTTP 423 response for a locked account, including when the lock ends.
+func Locked(w http.ResponseWriter, err error, until time.Time) {
+	resp := struct {
+		Error       bool      `json:"error"`
+		Status      string    `json:"status"`
+		Message     string    `json:"message"`
+		LockedUntil time.Time `json:"lockedUntil"`
+	}{
+		Error:       true,
+		Status:      "account_locked",
+		Message:     err.Error(),
+		LockedUntil: until,
+	}
+
+	headers := http.Header{}
+	headers.Set("Retry-After", strconv.Itoa(int(math.Ceil(time.Until(until).Seconds()))))
+	if err := WriteJSON(w, http.StatusLocked, resp, headers); err != nil {
+		log.Printf("Error writing locked response: %v", err)
+	}
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA-1 key of the RFC 6238 test vectors ("12345678901234567890") in base32
+const rfc6238Secret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestValidateTOTPVectors(t *testing.T) {
+	// RFC 6238 appendix B, SHA-1; the 8 digit values cut to the last 6 digits
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+	for _, tt := range tests {
+		step, ok := ValidateTOTP(rfc6238Secret, tt.code, time.Unix(tt.unix, 0))
+		if !ok {
+			t.Errorf("ValidateTOTP(%s at %d) = false, want true", tt.code, tt.unix)
+			continue
+		}
+		if want := tt.unix / totpPeriod; step != want {
+			t.Errorf("ValidateTOTP(%s at %d) step = %d, want %d", tt.code, tt.unix, step, want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	at := time.Unix(1111111111, 0) // code 050471
+	tests := []struct {
+		name   string
+		secret string
+		code   string
+		at     time.Time
+		want   bool
+	}{
+		{"current step", rfc6238Secret, "050471", at, true},
+		{"lower-case secret and spaces", "gezdgnbvgy3tqojqgezdgnbvgy3tqojq", " 050471 ", at, true},
+		{"one step late", rfc6238Secret, "050471", at.Add(totpPeriod * time.Second), true},
+		{"one step early", rfc6238Secret, "050471", at.Add(-totpPeriod * time.Second), true},
+		{"two steps late", rfc6238Secret, "050471", at.Add(2 * totpPeriod * time.Second), false},
+		{"wrong code", rfc6238Secret, "050472", at, false},
+		{"too short", rfc6238Secret, "50471", at, false},
+		{"8 digit code", rfc6238Secret, "14050471", at, false},
+		{"invalid secret", "not base32!", "050471", at, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, got := ValidateTOTP(tt.secret, tt.code, tt.at); got != tt.want {
+				t.Errorf("ValidateTOTP() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGenerateTOTPSecret(t *testing.T) {
+	secret, err := GenerateTOTPSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) != 20 {
+		t.Errorf("GenerateTOTPSecret() = %q, want 20 bytes of base32", secret)
+	}
+}
@@ -1,12 +1,17 @@
 package utils
 
 import (
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
 	"errors"
 	"fmt"
-	"math/rand"
+	"net"
 	"net/http"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -22,8 +27,17 @@ func EnsureDir(dir string) error {
 // GenerateJWT generates a JWT token for the given user
 func GenerateJWT(user models.JWT, cfg models.JWTConfig) (string, error) {
 	now := time.Now()
+	jti := user.TokenID
+	if jti == "" {
+		var err error
+		if jti, err = GenerateOpaqueToken(16); err != nil {
+			return "", err
+		}
+	}
 	claims := jwt.MapClaims{
 		"id":         user.ID,
+		"jti":        jti,
+		"ver":        user.TokenVersion,
 		"name":       user.Name,
 		"username":   user.Username,
 		"role":       user.Role,
@@ -39,6 +53,50 @@ func GenerateJWT(user models.JWT, cfg models.JWTConfig) (string, error) {
 	return token.SignedString([]byte(cfg.SecretKey))
 }
 
+// GenerateChallengeJWT generates a short-lived token proving that the user passed the password step
+// of a two-factor sign-in. It cannot be used as an access token.
+func GenerateChallengeJWT(userID int64, cfg models.JWTConfig, ttl time.Duration) (string, error) {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"sub":     userID,
+		"purpose": "2fa",
+		"iss":     cfg.Issuer,
+		"aud":     cfg.Audience,
+		"exp":     now.Add(ttl).Unix(),
+		"iat":     now.Unix(),
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(cfg.Algorithm), claims)
+	return token.SignedString([]byte(cfg.SecretKey))
+}
+
+// VerifyChallengeJWT validates a token created by GenerateChallengeJWT and returns the user ID
+func VerifyChallengeJWT(tokenString string, cfg models.JWTConfig) (int64, error) {
+	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if token.Method.Alg() != cfg.Algorithm {
+			return nil, errors.New("unexpected signing method")
+		}
+		return []byte(cfg.SecretKey), nil
+	}, jwt.WithIssuer(cfg.Issuer), jwt.WithAudience(cfg.Audience))
+	if err != nil || !token.Valid {
+		return 0, errors.New("invalid or expired challenge token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["purpose"] != "2fa" {
+		return 0, errors.New("invalid challenge token")
+	}
+	if _, ok := claims["exp"].(float64); !ok {
+		return 0, errors.New("invalid challenge token")
+	}
+
+	sub, ok := claims["sub"].(float64)
+	if !ok {
+		return 0, errors.New("invalid challenge token")
+	}
+	return int64(sub), nil
+}
+
 // ParseJWT validates the token and returns claims
 func ParseJWT(tokenString string, cfg models.JWTConfig) (*models.JWT, error) {
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
@@ -71,7 +129,7 @@ func ParseJWT(tokenString string, cfg models.JWTConfig) (*models.JWT, error) {
 // VerifyJWT validates the token string and returns the claims (models.JWT).
 // It performs strict checks against the provided JWTConfig and safely parses time fields.
 func VerifyJWT(tokenString string, cfg models.JWTConfig) (*models.JWT, error) {
-	
+
 	// 1. Parse the token
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		// Verify the signing algorithm matches the configuration
@@ -98,8 +156,13 @@ func VerifyJWT(tokenString string, cfg models.JWTConfig) (*models.JWT, error) {
 		return nil, errors.New("invalid claims format")
 	}
 
+	// Challenge tokens (two-factor sign-in) are never valid access tokens
+	if _, isChallenge := claims["purpose"]; isChallenge {
+		return nil, errors.New("not an access token")
+	}
+
 	// 2. Perform Security/Config Checks and map claims
-	
+
 	// A. Check Issuer (iss)
 	if claims["iss"].(string) != cfg.Issuer {
 		return nil, errors.New("token issuer mismatch")
@@ -109,9 +172,9 @@ func VerifyJWT(tokenString string, cfg models.JWTConfig) (*models.JWT, error) {
 	if claims["aud"].(string) != cfg.Audience {
 		return nil, errors.New("token audience mismatch")
 	}
-    
+
 	// C. Check Expiration (exp)
-    // JSON numbers are parsed as float64
+	// JSON numbers are parsed as float64
 	expTimestamp, ok := claims["exp"].(float64)
 	if !ok {
 		return nil, errors.New("token expiry claim missing or invalid")
@@ -119,49 +182,60 @@ func VerifyJWT(tokenString string, cfg models.JWTConfig) (*models.JWT, error) {
 	if time.Unix(int64(expTimestamp), 0).Before(time.Now()) {
 		return nil, errors.New("token has expired")
 	}
-	
+
 	// 3. Map Claims to models.JWT with safe type conversions
-	
+
 	// Safely get ID (parsed as float64)
-	id, ok := claims["id"].(float64) 
+	id, ok := claims["id"].(float64)
 	if !ok {
 		return nil, errors.New("token 'id' claim missing or invalid")
 	}
-    
-    // Safely parse created_at time (FIX for the panic)
-    createdAtStr, ok := claims["created_at"].(string)
-    if !ok {
-        return nil, errors.New("token 'created_at' claim missing or invalid format")
-    }
-    createdAt, err := time.Parse(time.RFC3339, createdAtStr)
-    if err != nil {
-        return nil, fmt.Errorf("failed to parse created_at time: %w", err)
-    }
-
-    // Safely parse updated_at time (FIX for the panic)
-    updatedAtStr, ok := claims["updated_at"].(string)
-    if !ok {
-        return nil, errors.New("token 'updated_at' claim missing or invalid format")
-    }
-    updatedAt, err := time.Parse(time.RFC3339, updatedAtStr)
-    if err != nil {
-        return nil, fmt.Errorf("failed to parse updated_at time: %w", err)
-    }
 
+	// Token ID and version are needed for revocation checks
+	jti, ok := claims["jti"].(string)
+	if !ok || jti == "" {
+		return nil, errors.New("token 'jti' claim missing or invalid")
+	}
+	version, ok := claims["ver"].(float64)
+	if !ok {
+		return nil, errors.New("token 'ver' claim missing or invalid")
+	}
+
+	// Safely parse created_at time (FIX for the panic)
+	createdAtStr, ok := claims["created_at"].(string)
+	if !ok {
+		return nil, errors.New("token 'created_at' claim missing or invalid format")
+	}
+	createdAt, err := time.Parse(time.RFC3339, createdAtStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse created_at time: %w", err)
+	}
+
+	// Safely parse updated_at time (FIX for the panic)
+	updatedAtStr, ok := claims["updated_at"].(string)
+	if !ok {
+		return nil, errors.New("token 'updated_at' claim missing or invalid format")
+	}
+	updatedAt, err := time.Parse(time.RFC3339, updatedAtStr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse updated_at time: %w", err)
+	}
 
 	return &models.JWT{
-		ID:    int64(id),
-		Name:   claims["name"].(string),
-		Username: claims["username"].(string),
-		Role:   claims["role"].(string),
-		
+		ID:           int64(id),
+		TokenID:      jti,
+		TokenVersion: int64(version),
+		Name:         claims["name"].(string),
+		Username:     claims["username"].(string),
+		Role:         claims["role"].(string),
+
 		// Standard claims
-		Issuer:  claims["iss"].(string),
-		Audience: claims["aud"].(string),
+		Issuer:    claims["iss"].(string),
+		Audience:  claims["aud"].(string),
 		ExpiresAt: int64(claims["exp"].(float64)),
-		IssuedAt: int64(claims["iat"].(float64)),
-        
-        // Time fields (Now correctly parsed from string)
+		IssuedAt:  int64(claims["iat"].(float64)),
+
+		// Time fields (Now correctly parsed from string)
 		CreatedAt: createdAt,
 		UpdatedAt: updatedAt,
 	}, nil
@@ -201,19 +275,104 @@ func GetBranchID(r *http.Request) int64 {
 	return branchID
 }
 
-// GenerateMemoNo generates a memo number like "MMDD-4CHAR"
-func GenerateMemoNo() string {
-	// create a new rand with its own seed
-	r := rand.New(rand.NewSource(time.Now().UnixNano()))
+// GenerateMemoNo formats a document number like "QT-2026-0042" from the prefix, the year of date and
+// the sequence value. seq must come from a database sequence (see document_sequences) so numbers never collide.
+func GenerateMemoNo(prefix string, date time.Time, seq int64) string {
+	return fmt.Sprintf("%s-%s-%04d", prefix, date.Format("2006"), seq)
+}
+
+// GenerateOpaqueToken returns a URL-safe random token built from n random bytes
+func GenerateOpaqueToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := crand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// HashToken returns the hex encoded SHA-256 hash of a token, used to store tokens at rest
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// trustedProxies are the reverse proxies whose forwarding headers ClientIP believes
+var trustedProxies []*net.IPNet
 
-	// MMDD part
-	datePart := time.Now().Format("0102")
-	const charset = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	// 4 random alphanumeric characters
-	randomPart := make([]byte, 4)
-	for i := range randomPart {
-		randomPart[i] = charset[r.Intn(len(charset))]
+// ParseTrustedProxies parses a comma-separated list of IP addresses and CIDR ranges, e.g. "127.0.0.1,10.0.0.0/8"
+func ParseTrustedProxies(list string) ([]*net.IPNet, error) {
+	var nets []*net.IPNet
+	for _, entry := range strings.Split(list, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid trusted proxy %q", entry)
+			}
+			bits := 128
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 32
+			}
+			nets = append(nets, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid trusted proxy %q: %w", entry, err)
+		}
+		nets = append(nets, ipNet)
 	}
+	return nets, nil
+}
+
+// SetTrustedProxies sets the reverse proxies ClientIP trusts. It is called once at startup.
+func SetTrustedProxies(nets []*net.IPNet) {
+	trustedProxies = nets
+}
 
-	return fmt.Sprintf("%s%s", datePart, string(randomPart))
+// isTrustedProxy reports whether ip belongs to one of the trusted proxies
+func isTrustedProxy(ip net.IP) bool {
+	for _, n := range trustedProxies {
+		if n.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
+
+// ClientIP returns the client address. Forwarding headers are only honoured when the peer is a trusted proxy:
+// X-Forwarded-For is then read from the right and the first hop that is not a trusted proxy is returned, so a
+// client cannot choose its address by sending the header itself. X-Real-IP is used when X-Forwarded-For is absent.
+func ClientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		host = r.RemoteAddr
+	}
+	peer := net.ParseIP(host)
+	if peer == nil || !isTrustedProxy(peer) {
+		return host
+	}
+
+	if fwd := r.Header.Values("X-Forwarded-For"); len(fwd) > 0 {
+		hops := strings.Split(strings.Join(fwd, ","), ",")
+		client := host
+		for i := len(hops) - 1; i >= 0; i-- {
+			ip := net.ParseIP(strings.TrimSpace(hops[i]))
+			if ip == nil {
+				break
+			}
+			client = ip.String()
+			if !isTrustedProxy(ip) {
+				break
+			}
+		}
+		return client
+	}
+	if ip := net.ParseIP(strings.TrimSpace(r.Header.Get("X-Real-IP"))); ip != nil {
+		return ip.String()
+	}
+	return host
 }
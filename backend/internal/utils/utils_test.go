@@ -0,0 +1,71 @@
+package utils
+
+import (
+	"net/http"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	nets, err := ParseTrustedProxies("10.0.0.1, 192.168.0.0/16")
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetTrustedProxies(nets)
+	defer SetTrustedProxies(nil)
+
+	tests := []struct {
+		name   string
+		remote string
+		fwd    string
+		realIP string
+		want   string
+	}{
+		{"direct client", "203.0.113.7:5000", "", "", "203.0.113.7"},
+		{"untrusted peer cannot spoof", "203.0.113.7:5000", "1.2.3.4", "5.6.7.8", "203.0.113.7"},
+		{"trusted proxy", "10.0.0.1:443", "198.51.100.2", "", "198.51.100.2"},
+		{"right-most untrusted hop", "10.0.0.1:443", "1.2.3.4, 198.51.100.2, 192.168.1.5", "", "198.51.100.2"},
+		{"all hops trusted", "10.0.0.1:443", "192.168.1.9, 192.168.1.5", "", "192.168.1.9"},
+		{"invalid hop stops the walk", "10.0.0.1:443", "evil, 192.168.1.5", "", "192.168.1.5"},
+		{"real ip from trusted proxy", "10.0.0.1:443", "", "198.51.100.9", "198.51.100.9"},
+		{"no headers from trusted proxy", "10.0.0.1:443", "", "", "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, _ := http.NewRequest(http.MethodGet, "/", nil)
+			r.RemoteAddr = tt.remote
+			if tt.fwd != "" {
+				r.Header.Set("X-Forwarded-For", tt.fwd)
+			}
+			if tt.realIP != "" {
+				r.Header.Set("X-Real-IP", tt.realIP)
+			}
+			if got := ClientIP(r); got != tt.want {
+				t.Errorf("ClientIP() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseTrustedProxies(t *testing.T) {
+	tests := []struct {
+		list    string
+		count   int
+		wantErr bool
+	}{
+		{"", 0, false},
+		{"127.0.0.1", 1, false},
+		{"10.0.0.0/8, ::1", 2, false},
+		{"not-an-ip", 0, true},
+		{"10.0.0.0/33", 0, true},
+	}
+	for _, tt := range tests {
+		nets, err := ParseTrustedProxies(tt.list)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseTrustedProxies(%q) error = %v, wantErr %v", tt.list, err, tt.wantErr)
+			continue
+		}
+		if len(nets) != tt.count {
+			t.Errorf("ParseTrustedProxies(%q) = %d entries, want %d", tt.list, len(nets), tt.count)
+		}
+	}
+}
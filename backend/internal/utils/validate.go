@@ -0,0 +1,33 @@
+package utils
+
+import (
+	"net/mail"
+	"strings"
+)
+
+// ValidEmail reports whether s is a plain email address such as user@example.com (no display name).
+func ValidEmail(s string) bool {
+	addr, err := mail.ParseAddress(s)
+	if err != nil || addr.Address != s {
+		return false
+	}
+	at := strings.LastIndex(s, "@")
+	return at > 0 && strings.Contains(s[at+1:], ".")
+}
+
+// ValidPhone reports whether s looks like a phone number: an optional leading +, then 7 to 15 digits
+// that may be grouped with spaces, dashes, dots or parentheses.
+func ValidPhone(s string) bool {
+	digits := 0
+	for i, c := range s {
+		switch {
+		case c >= '0' && c <= '9':
+			digits++
+		case c == '+' && i == 0:
+		case c == ' ' || c == '-' || c == '.' || c == '(' || c == ')':
+		default:
+			return false
+		}
+	}
+	return digits >= 7 && digits <= 15
+}
@@ -0,0 +1,51 @@
+package webhook
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestSign(t *testing.T) {
+	// Expected values computed independently with Python's hmac module
+	tests := []struct {
+		secret    string
+		timestamp int64
+		body      string
+		want      string
+	}{
+		{"whsec_test", 1700000000, `{"event":"ping"}`, "sha256=aa8efe37b751e71157c508c5ac4acb1e9fe5225db98355dfc00f4b680afbc447"},
+		{"secret", 0, "", "sha256=3445798a051818ef95def46c2eb62b43d377ce6e3c29b4d0aec3da0e59577f79"},
+	}
+	for _, tt := range tests {
+		if got := Sign(tt.secret, tt.timestamp, []byte(tt.body)); got != tt.want {
+			t.Errorf("Sign(%q, %d, %q) = %s, want %s", tt.secret, tt.timestamp, tt.body, got, tt.want)
+		}
+	}
+}
+
+func TestSignDependsOnEveryInput(t *testing.T) {
+	base := Sign("secret", 1700000000, []byte("body"))
+	for name, sig := range map[string]string{
+		"secret":    Sign("secret2", 1700000000, []byte("body")),
+		"timestamp": Sign("secret", 1700000001, []byte("body")),
+		"body":      Sign("secret", 1700000000, []byte("body2")),
+	} {
+		if sig == base {
+			t.Errorf("changing the %s did not change the signature", name)
+		}
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	a, err := GenerateSecret()
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, _ := GenerateSecret()
+	if !strings.HasPrefix(a, "whsec_") || len(a) != len("whsec_")+43 {
+		t.Errorf("GenerateSecret() = %q, want whsec_ and 43 base64url characters", a)
+	}
+	if a == b {
+		t.Error("GenerateSecret() returned the same secret twice")
+	}
+}
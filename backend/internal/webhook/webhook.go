@@ -0,0 +1,286 @@
+// Package webhook delivers events to the HTTP endpoints registered by admins.
+//
+// Publish stores one delivery per subscribed endpoint; the Dispatcher's Run loop picks up due deliveries,
+// posts the signed payload and retries failures with exponential backoff until MaxAttempts is reached.
+// Every attempt is recorded, so the delivery log survives restarts and failed deliveries can be replayed.
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/projuktisheba/ajfses/backend/internal/models"
+)
+
+const (
+	// pollInterval is how often the dispatcher looks for due deliveries when it is not woken up
+	pollInterval = 5 * time.Second
+	// batchSize is the number of deliveries claimed and sent concurrently
+	batchSize = 10
+	// maxRetryDelay caps the backoff between attempts
+	maxRetryDelay = 6 * time.Hour
+	// maxResponseBody is how much of an endpoint's response is kept in the log
+	maxResponseBody = 2 << 10
+	// publishTimeout bounds storing the deliveries of an event
+	publishTimeout = 5 * time.Second
+	// userAgent identifies the dispatcher to receivers
+	userAgent = "AJFSES-Webhooks/1.0"
+)
+
+// Store persists the deliveries. It is implemented by the database layer.
+type Store interface {
+	// Enqueue queues the event for every active endpoint subscribed to it and returns the number queued.
+	Enqueue(ctx context.Context, event, eventID string, payload []byte) (int, error)
+	// ClaimDue returns up to limit due deliveries and holds them for lease.
+	ClaimDue(ctx context.Context, limit int, lease time.Duration) ([]models.WebhookJob, error)
+	// RecordAttempt stores the outcome of an attempt.
+	RecordAttempt(ctx context.Context, a *models.WebhookAttempt) error
+}
+
+// Envelope is the JSON body posted for every event.
+type Envelope struct {
+	ID        string    `json:"id"`
+	Event     string    `json:"event"`
+	CreatedAt time.Time `json:"createdAt"`
+	Data      any       `json:"data"`
+}
+
+// NewEnvelope wraps data in an envelope with a new event ID and returns the ID and the encoded body.
+func NewEnvelope(event string, data any) (string, []byte, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", nil, err
+	}
+	env := Envelope{
+		ID:        "evt_" + hex.EncodeToString(b),
+		Event:     event,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	payload, err := json.Marshal(env)
+	if err != nil {
+		return "", nil, fmt.Errorf("webhook: failed to encode %s payload: %w", event, err)
+	}
+	return env.ID, payload, nil
+}
+
+// Dispatcher queues events and delivers them in the background.
+type Dispatcher struct {
+	store       Store
+	client      *http.Client
+	maxAttempts int
+	retryDelay  time.Duration
+	lease       time.Duration
+	wake        chan struct{}
+	infoLog     *log.Logger
+	errorLog    *log.Logger
+}
+
+// New creates a Dispatcher. Deliveries are only sent while Run is running.
+func New(cfg models.WebhookConfig, store Store, infoLog, errorLog *log.Logger) *Dispatcher {
+	maxAttempts := cfg.MaxAttempts
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+	timeout := cfg.Timeout
+	if timeout <= 0 {
+		timeout = 10 * time.Second
+	}
+	retryDelay := cfg.RetryDelay
+	if retryDelay <= 0 {
+		retryDelay = 30 * time.Second
+	}
+
+	return &Dispatcher{
+		store: store,
+		client: &http.Client{
+			Timeout: timeout,
+			// A redirect is reported as a failure instead of re-posting the payload somewhere else
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		maxAttempts: maxAttempts,
+		retryDelay:  retryDelay,
+		lease:       timeout + time.Minute,
+		wake:        make(chan struct{}, 1),
+		infoLog:     infoLog,
+		errorLog:    errorLog,
+	}
+}
+
+// Publish queues event for every subscribed endpoint. data is sent as the envelope's "data".
+// Failures are logged, never returned, so a webhook problem cannot break the request that triggered it.
+// The deliveries are stored even if ctx is cancelled right after the request finishes.
+func (d *Dispatcher) Publish(ctx context.Context, event string, data any) {
+	if d == nil {
+		return
+	}
+
+	eventID, payload, err := NewEnvelope(event, data)
+	if err != nil {
+		d.errorLog.Println(err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), publishTimeout)
+	defer cancel()
+	n, err := d.store.Enqueue(ctx, event, eventID, payload)
+	if err != nil {
+		d.errorLog.Printf("webhook: failed to queue %s: %v", event, err)
+		return
+	}
+	if n > 0 {
+		d.Wake()
+	}
+}
+
+// Wake makes Run look for due deliveries now, e.g. after a delivery was queued or replayed.
+func (d *Dispatcher) Wake() {
+	if d == nil {
+		return
+	}
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run delivers due deliveries until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		d.drain(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// drain sends batches of due deliveries until none is left.
+func (d *Dispatcher) drain(ctx context.Context) {
+	for ctx.Err() == nil {
+		jobs, err := d.store.ClaimDue(ctx, batchSize, d.lease)
+		if err != nil {
+			if ctx.Err() == nil {
+				d.errorLog.Println("webhook: failed to claim deliveries:", err)
+			}
+			return
+		}
+
+		var wg sync.WaitGroup
+		for i := range jobs {
+			wg.Add(1)
+			go func(job *models.WebhookJob) {
+				defer wg.Done()
+				d.attempt(ctx, job)
+			}(&jobs[i])
+		}
+		wg.Wait()
+
+		if len(jobs) < batchSize {
+			return
+		}
+	}
+}
+
+// attempt sends one delivery and records the outcome, scheduling a retry if attempts are left.
+func (d *Dispatcher) attempt(ctx context.Context, job *models.WebhookJob) {
+	a := d.send(ctx, job)
+	if ctx.Err() != nil {
+		// Shutting down: the claim runs out and the delivery is sent again after the restart
+		return
+	}
+
+	attempts := job.Delivery.Attempts + 1
+	switch {
+	case a.Status == models.WebhookStatusSucceeded:
+	case attempts >= d.maxAttempts:
+		a.Status = models.WebhookStatusFailed
+		d.errorLog.Printf("webhook: delivery %d (%s) to %s failed after %d attempts: %s",
+			job.Delivery.ID, job.Delivery.Event, job.URL, attempts, a.Error)
+	default:
+		a.Status = models.WebhookStatusPending
+		next := time.Now().Add(d.backoff(attempts))
+		a.NextAttemptAt = &next
+	}
+
+	recordCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), publishTimeout)
+	defer cancel()
+	if err := d.store.RecordAttempt(recordCtx, a); err != nil {
+		d.errorLog.Printf("webhook: failed to record attempt of delivery %d: %v", job.Delivery.ID, err)
+	}
+}
+
+// backoff returns the delay after the given number of failed attempts: RetryDelay, then 4x longer each time.
+func (d *Dispatcher) backoff(attempts int) time.Duration {
+	delay := d.retryDelay
+	for i := 1; i < attempts && delay < maxRetryDelay; i++ {
+		delay *= 4
+	}
+	if delay > maxRetryDelay {
+		delay = maxRetryDelay
+	}
+	return delay
+}
+
+// send posts the payload once. Any 2xx response counts as success.
+func (d *Dispatcher) send(ctx context.Context, job *models.WebhookJob) *models.WebhookAttempt {
+	a := &models.WebhookAttempt{DeliveryID: job.Delivery.ID}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, job.URL, bytes.NewReader(job.Delivery.Payload))
+	if err != nil {
+		a.Error = err.Error()
+		return a
+	}
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", userAgent)
+	req.Header.Set(HeaderEvent, job.Delivery.Event)
+	req.Header.Set(HeaderEventID, job.Delivery.EventID)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(job.Delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, Sign(job.Secret, timestamp, job.Delivery.Payload))
+
+	start := time.Now()
+	resp, err := d.client.Do(req)
+	a.DurationMS = int(time.Since(start).Milliseconds())
+	if err != nil {
+		a.Error = err.Error()
+		return a
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBody))
+	if err != nil && !errors.Is(err, io.EOF) {
+		a.Error = "failed to read response: " + err.Error()
+	}
+	// Stored as TEXT: keep it valid UTF-8 without NUL bytes
+	a.ResponseBody = strings.ReplaceAll(string(bytes.ToValidUTF8(body, []byte("?"))), "\x00", "")
+	a.ResponseStatus = &resp.StatusCode
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		a.Status = models.WebhookStatusSucceeded
+		a.Error = ""
+		return a
+	}
+	a.Error = "unexpected response status " + resp.Status
+	return a
+}
@@ -0,0 +1,18 @@
+-- =========================
+-- Access token revocation
+-- =========================
+-- Every access token carries the user's token_version ("ver" claim).
+-- Bumping token_version invalidates every token issued before it.
+ALTER TABLE users ADD COLUMN token_version BIGINT NOT NULL DEFAULT 0;
+
+-- Individually revoked access tokens (logout), keyed by JWT ID ("jti" claim).
+-- Rows can be purged once expires_at has passed.
+CREATE TABLE revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    expires_at TIMESTAMPTZ NOT NULL,
+    revoked_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_revoked_tokens_expires_at ON revoked_tokens(expires_at);
@@ -0,0 +1,7 @@
+-- =========================
+-- Unique user identifiers
+-- =========================
+-- Users sign in with their mobile or email, so both must identify a single account.
+-- The names match the constraint names mapped to friendly errors in UserRepo.
+CREATE UNIQUE INDEX users_mobile_key ON users(mobile);
+CREATE UNIQUE INDEX users_email_key ON users(email) WHERE email <> '';
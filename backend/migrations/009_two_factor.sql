@@ -0,0 +1,22 @@
+-- =========================
+-- TOTP two-factor authentication
+-- =========================
+-- totp_secret is the base32 shared secret. It is set on enrollment and only
+-- becomes active once totp_enabled is switched on after a verified code.
+-- totp_last_step stores the last accepted 30s time step so a code cannot be replayed.
+ALTER TABLE users
+    ADD COLUMN totp_secret TEXT NOT NULL DEFAULT '',
+    ADD COLUMN totp_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN totp_last_step BIGINT NOT NULL DEFAULT 0;
+
+-- One-time recovery codes, stored hashed
+CREATE TABLE user_recovery_codes (
+    id BIGSERIAL PRIMARY KEY,
+    user_id BIGINT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    code_hash VARCHAR(64) NOT NULL,
+    used_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_user_recovery_codes_user_id ON user_recovery_codes(user_id);
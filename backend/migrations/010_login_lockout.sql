@@ -0,0 +1,27 @@
+-- =========================
+-- Sign-in brute-force protection
+-- =========================
+-- Accounts are locked through the existing status column ('Locked') until locked_until.
+-- lock_count grows with every lock so each lock lasts longer; it resets on a successful sign-in.
+ALTER TABLE users DROP CONSTRAINT users_status_check;
+ALTER TABLE users ADD CONSTRAINT users_status_check CHECK (status IN ('Active', 'Inactive', 'Locked'));
+
+ALTER TABLE users
+    ADD COLUMN failed_login_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN lock_count INT NOT NULL DEFAULT 0,
+    ADD COLUMN locked_until TIMESTAMPTZ;
+
+-- Every sign-in attempt, used for per-IP throttling and auditing
+CREATE TABLE login_attempts (
+    id BIGSERIAL PRIMARY KEY,
+    username VARCHAR(150) NOT NULL DEFAULT '',
+    user_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    ip_address VARCHAR(64) NOT NULL DEFAULT '',
+    success BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_login_attempts_ip_created_at ON login_attempts(ip_address, created_at);
+CREATE INDEX idx_login_attempts_username_created_at ON login_attempts(username, created_at);
+CREATE INDEX idx_login_attempts_created_at ON login_attempts(created_at);
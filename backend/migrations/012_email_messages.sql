@@ -0,0 +1,21 @@
+-- =========================
+-- Outgoing email log
+-- =========================
+-- One row per message handed to the mailer, with the final delivery outcome after retries.
+CREATE TABLE email_messages (
+    id BIGSERIAL PRIMARY KEY,
+    template VARCHAR(100) NOT NULL DEFAULT '',
+    recipients TEXT[] NOT NULL DEFAULT '{}',
+    subject TEXT NOT NULL DEFAULT '',
+    transport VARCHAR(20) NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL CHECK (status IN ('SENT', 'FAILED')),
+    attempts INT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    sent_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_email_messages_created_at ON email_messages(created_at);
+CREATE INDEX idx_email_messages_status ON email_messages(status);
+CREATE INDEX idx_email_messages_template ON email_messages(template);
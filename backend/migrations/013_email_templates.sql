@@ -0,0 +1,13 @@
+-- =========================
+-- Admin-edited email templates
+-- =========================
+-- Overrides of the templates built into the mailer (internal/mailer/templates).
+-- A template without a row here uses the built-in default; deleting the row restores it.
+CREATE TABLE email_templates (
+    name VARCHAR(100) PRIMARY KEY,
+    subject TEXT NOT NULL,
+    text_body TEXT NOT NULL,
+    html_body TEXT NOT NULL DEFAULT '',
+    updated_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
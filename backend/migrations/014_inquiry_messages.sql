@@ -0,0 +1,20 @@
+-- =========================
+-- Table: inquiry_messages
+-- =========================
+-- Conversation thread of an inquiry. The customer's original message stays on the inquiry;
+-- staff add internal NOTEs (never sent) and REPLYs (emailed to the customer).
+-- email_status records the delivery outcome of a reply.
+CREATE TABLE inquiry_messages (
+    id BIGSERIAL PRIMARY KEY,
+    inquiry_id BIGINT NOT NULL REFERENCES inquiries(id) ON DELETE CASCADE,
+    kind VARCHAR(20) NOT NULL CHECK (kind IN ('NOTE', 'REPLY')),
+    body TEXT NOT NULL,
+    author_id BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    recipient VARCHAR(255) NOT NULL DEFAULT '',
+    email_status VARCHAR(20) NOT NULL DEFAULT '' CHECK (email_status IN ('', 'SENT', 'FAILED')),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_inquiry_messages_inquiry_id_created_at ON inquiry_messages(inquiry_id, created_at);
+CREATE INDEX idx_inquiry_messages_author_id ON inquiry_messages(author_id);
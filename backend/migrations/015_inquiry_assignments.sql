@@ -0,0 +1,22 @@
+-- =========================
+-- Inquiry ownership
+-- =========================
+-- assigned_to is the staff member currently following up the inquiry (NULL = unassigned).
+ALTER TABLE inquiries
+    ADD COLUMN assigned_to BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    ADD COLUMN assigned_at TIMESTAMPTZ;
+
+-- Every (re)assignment, including unassignment (assigned_to NULL)
+CREATE TABLE inquiry_assignments (
+    id BIGSERIAL PRIMARY KEY,
+    inquiry_id BIGINT NOT NULL REFERENCES inquiries(id) ON DELETE CASCADE,
+    previous_assignee BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    assigned_to BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    assigned_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    note TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Indexes
+CREATE INDEX idx_inquiries_assigned_to ON inquiries(assigned_to);
+CREATE INDEX idx_inquiry_assignments_inquiry_id ON inquiry_assignments(inquiry_id);
@@ -0,0 +1,62 @@
+-- =========================
+-- Configurable inquiry workflow
+-- =========================
+-- The allowed statuses and transitions live in tables instead of a CHECK constraint.
+-- The handler validates every change against inquiry_status_transitions.
+CREATE TABLE inquiry_statuses (
+    code VARCHAR(20) PRIMARY KEY,
+    label VARCHAR(100) NOT NULL,
+    is_initial BOOLEAN NOT NULL DEFAULT FALSE, -- status of a newly submitted inquiry (exactly one)
+    is_closed BOOLEAN NOT NULL DEFAULT FALSE,  -- no further follow-up expected
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE inquiry_status_transitions (
+    from_status VARCHAR(20) NOT NULL REFERENCES inquiry_statuses(code) ON UPDATE CASCADE ON DELETE CASCADE,
+    to_status VARCHAR(20) NOT NULL REFERENCES inquiry_statuses(code) ON UPDATE CASCADE ON DELETE CASCADE,
+    PRIMARY KEY (from_status, to_status),
+    CHECK (from_status <> to_status)
+);
+
+INSERT INTO inquiry_statuses (code, label, is_initial, is_closed, sort_order) VALUES
+    ('NEW', 'New', TRUE, FALSE, 10),
+    ('IN PROGRESS', 'In Progress', FALSE, FALSE, 20),
+    ('QUOTED', 'Quoted', FALSE, FALSE, 30),
+    ('WON', 'Won', FALSE, TRUE, 40),
+    ('LOST', 'Lost', FALSE, TRUE, 50),
+    ('RESOLVED', 'Resolved', FALSE, TRUE, 60),
+    ('SPAM', 'Spam', FALSE, TRUE, 70);
+
+INSERT INTO inquiry_status_transitions (from_status, to_status) VALUES
+    ('NEW', 'IN PROGRESS'), ('NEW', 'QUOTED'), ('NEW', 'RESOLVED'), ('NEW', 'LOST'), ('NEW', 'SPAM'),
+    ('IN PROGRESS', 'QUOTED'), ('IN PROGRESS', 'RESOLVED'), ('IN PROGRESS', 'LOST'), ('IN PROGRESS', 'SPAM'),
+    ('QUOTED', 'WON'), ('QUOTED', 'LOST'), ('QUOTED', 'IN PROGRESS'),
+    ('WON', 'RESOLVED'),
+    ('LOST', 'IN PROGRESS'),
+    ('RESOLVED', 'IN PROGRESS'),
+    ('SPAM', 'NEW');
+
+-- inquiries.status now references the configured statuses
+ALTER TABLE inquiries DROP CONSTRAINT inquiries_status_check;
+ALTER TABLE inquiries
+    ADD CONSTRAINT inquiries_status_fkey FOREIGN KEY (status) REFERENCES inquiry_statuses(code) ON UPDATE CASCADE;
+
+-- Every status change. Status codes are kept as text so history survives workflow changes.
+CREATE TABLE inquiry_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    inquiry_id BIGINT NOT NULL REFERENCES inquiries(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL DEFAULT '', -- '' for the initial status
+    to_status VARCHAR(20) NOT NULL,
+    changed_by BIGINT REFERENCES users(id) ON DELETE SET NULL, -- NULL for the public form
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- Existing inquiries start their history with their current status
+INSERT INTO inquiry_status_history (inquiry_id, to_status, created_at)
+SELECT id, status, created_at FROM inquiries;
+
+-- Indexes
+CREATE INDEX idx_inquiry_status_history_inquiry_id ON inquiry_status_history(inquiry_id, created_at);
+CREATE INDEX idx_inquiry_status_history_to_status ON inquiry_status_history(to_status);
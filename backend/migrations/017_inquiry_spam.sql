@@ -0,0 +1,23 @@
+-- =========================
+-- Inquiry spam protection
+-- =========================
+-- Suspicious contact-form submissions are kept with status SPAM together with the score and the
+-- reasons given by the spam scorer, so staff can review and restore false positives.
+ALTER TABLE inquiries
+    ADD COLUMN spam_score INT NOT NULL DEFAULT 0,
+    ADD COLUMN spam_reasons TEXT NOT NULL DEFAULT '',
+    ADD COLUMN ip_address VARCHAR(45) NOT NULL DEFAULT '';
+
+-- SPAM must exist even if the workflow was edited after it was introduced
+INSERT INTO inquiry_statuses (code, label, is_initial, is_closed, sort_order)
+VALUES ('SPAM', 'Spam', FALSE, TRUE, 70)
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO inquiry_status_transitions (from_status, to_status)
+VALUES ('SPAM', 'NEW')
+ON CONFLICT DO NOTHING;
+
+-- =========================
+-- Indexes
+-- =========================
+CREATE INDEX idx_inquiries_ip_address ON inquiries(ip_address);
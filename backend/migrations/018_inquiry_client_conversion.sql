@@ -0,0 +1,28 @@
+-- =========================
+-- Inquiry to client conversion
+-- =========================
+-- A client created from an inquiry keeps a link to it and the inquiry links back to the client.
+ALTER TABLE clients
+    ADD COLUMN inquiry_id BIGINT REFERENCES inquiries(id) ON DELETE SET NULL;
+
+ALTER TABLE inquiries
+    ADD COLUMN client_id BIGINT REFERENCES clients(id) ON DELETE SET NULL;
+
+-- Converted inquiries need no further follow-up
+INSERT INTO inquiry_statuses (code, label, is_initial, is_closed, sort_order)
+VALUES ('CONVERTED', 'Converted', FALSE, TRUE, 45)
+ON CONFLICT (code) DO NOTHING;
+
+INSERT INTO inquiry_status_transitions (from_status, to_status) VALUES
+    ('NEW', 'CONVERTED'),
+    ('IN PROGRESS', 'CONVERTED'),
+    ('QUOTED', 'CONVERTED'),
+    ('WON', 'CONVERTED')
+ON CONFLICT DO NOTHING;
+
+-- =========================
+-- Indexes
+-- =========================
+-- An inquiry is converted at most once
+CREATE UNIQUE INDEX idx_clients_inquiry_id ON clients(inquiry_id) WHERE inquiry_id IS NOT NULL;
+CREATE INDEX idx_inquiries_client_id ON inquiries(client_id);
@@ -0,0 +1,19 @@
+-- =========================
+-- Inquiry attachments
+-- =========================
+-- Files sent with the contact form (floor plans, site photos). The files live under
+-- data/attachments/inquiries/<inquiry id>/<stored_name>; file_name is the customer's original name.
+CREATE TABLE inquiry_attachments (
+    id BIGSERIAL PRIMARY KEY,
+    inquiry_id BIGINT NOT NULL REFERENCES inquiries(id) ON DELETE CASCADE,
+    file_name VARCHAR(255) NOT NULL,
+    stored_name VARCHAR(100) NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- =========================
+-- Indexes
+-- =========================
+CREATE INDEX idx_inquiry_attachments_inquiry_id ON inquiry_attachments(inquiry_id);
@@ -0,0 +1,45 @@
+-- =========================
+-- Products
+-- =========================
+-- The product catalogue shown on the public products pages. code is the SKU / catalogue number
+-- (unique, case-insensitive); specifications is a list of { label, value } pairs.
+-- The spec sheet lives under data/datasheets/products/<datasheet_link>; datasheet_name is the upload's name.
+-- Inactive products are only visible to staff.
+CREATE TABLE products (
+    id BIGSERIAL PRIMARY KEY,
+    code VARCHAR(50) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    category VARCHAR(100) NOT NULL DEFAULT '',
+    brand VARCHAR(100) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    specifications JSONB NOT NULL DEFAULT '[]',
+    datasheet_link VARCHAR(255) NOT NULL DEFAULT '',
+    datasheet_name VARCHAR(255) NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT TRUE,
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- =========================
+-- Product images
+-- =========================
+-- Images are stored under data/images/products and served from /api/v1/images/products/<image_link>.
+-- The first image by sort_order is the cover.
+CREATE TABLE product_images (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    image_link VARCHAR(255) NOT NULL,
+    alt_text VARCHAR(255) NOT NULL DEFAULT '',
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- =========================
+-- Indexes
+-- =========================
+CREATE UNIQUE INDEX ux_products_code ON products(LOWER(code));
+CREATE INDEX idx_products_category ON products(category);
+CREATE INDEX idx_products_brand ON products(brand);
+CREATE INDEX idx_products_is_active ON products(is_active);
+CREATE INDEX idx_product_images_product_id ON product_images(product_id);
@@ -0,0 +1,84 @@
+-- =========================
+-- Product categories
+-- =========================
+-- Categories form a tree through parent_id; a category with subcategories cannot be deleted.
+-- slug identifies the category in public URLs. Images are stored under data/images/categories.
+CREATE TABLE product_categories (
+    id BIGSERIAL PRIMARY KEY,
+    parent_id BIGINT REFERENCES product_categories(id) ON DELETE RESTRICT,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    image_link VARCHAR(255) NOT NULL DEFAULT '',
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- =========================
+-- Brands
+-- =========================
+-- Logos are stored under data/images/brands.
+CREATE TABLE brands (
+    id BIGSERIAL PRIMARY KEY,
+    slug VARCHAR(100) NOT NULL UNIQUE,
+    name VARCHAR(100) NOT NULL,
+    logo_link VARCHAR(255) NOT NULL DEFAULT '',
+    website VARCHAR(255) NOT NULL DEFAULT '',
+    description TEXT NOT NULL DEFAULT '',
+    sort_order INT NOT NULL DEFAULT 0,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- =========================
+-- Link products
+-- =========================
+-- The free-text category and brand of existing products become rows of the new tables.
+ALTER TABLE products
+    ADD COLUMN category_id BIGINT REFERENCES product_categories(id) ON DELETE SET NULL,
+    ADD COLUMN brand_id BIGINT REFERENCES brands(id) ON DELETE SET NULL;
+
+INSERT INTO product_categories (slug, name)
+SELECT slug, MIN(category)
+FROM (
+    SELECT TRIM(category) AS category,
+           TRIM(BOTH '-' FROM LOWER(REGEXP_REPLACE(TRIM(category), '[^A-Za-z0-9]+', '-', 'g'))) AS slug
+    FROM products
+    WHERE TRIM(category) <> ''
+) c
+WHERE slug <> ''
+GROUP BY slug;
+
+INSERT INTO brands (slug, name)
+SELECT slug, MIN(brand)
+FROM (
+    SELECT TRIM(brand) AS brand,
+           TRIM(BOTH '-' FROM LOWER(REGEXP_REPLACE(TRIM(brand), '[^A-Za-z0-9]+', '-', 'g'))) AS slug
+    FROM products
+    WHERE TRIM(brand) <> ''
+) b
+WHERE slug <> ''
+GROUP BY slug;
+
+UPDATE products p
+SET category_id = c.id
+FROM product_categories c
+WHERE c.slug = TRIM(BOTH '-' FROM LOWER(REGEXP_REPLACE(TRIM(p.category), '[^A-Za-z0-9]+', '-', 'g')));
+
+UPDATE products p
+SET brand_id = b.id
+FROM brands b
+WHERE b.slug = TRIM(BOTH '-' FROM LOWER(REGEXP_REPLACE(TRIM(p.brand), '[^A-Za-z0-9]+', '-', 'g')));
+
+-- The indexes on the old columns are dropped with them
+ALTER TABLE products
+    DROP COLUMN category,
+    DROP COLUMN brand;
+
+-- =========================
+-- Indexes
+-- =========================
+CREATE INDEX idx_product_categories_parent_id ON product_categories(parent_id);
+CREATE INDEX idx_products_category_id ON products(category_id);
+CREATE INDEX idx_products_brand_id ON products(brand_id);
@@ -0,0 +1,33 @@
+-- =========================
+-- Product datasheets
+-- =========================
+-- Every uploaded spec sheet is kept as a numbered version of its product; at most one version is current
+-- and linked from the public product. Files are stored under data/datasheets/products/<stored_name>;
+-- file_name is the name of the upload.
+CREATE TABLE product_datasheets (
+    id BIGSERIAL PRIMARY KEY,
+    product_id BIGINT NOT NULL REFERENCES products(id) ON DELETE CASCADE,
+    version INT NOT NULL,
+    file_name VARCHAR(255) NOT NULL,
+    stored_name VARCHAR(255) NOT NULL,
+    size BIGINT NOT NULL DEFAULT 0,
+    is_current BOOLEAN NOT NULL DEFAULT FALSE,
+    uploaded_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (product_id, version)
+);
+
+-- The spec sheets uploaded so far become version 1 (their size was not recorded)
+INSERT INTO product_datasheets (product_id, version, file_name, stored_name, is_current, created_at)
+SELECT id, 1, datasheet_name, datasheet_link, TRUE, updated_at
+FROM products
+WHERE datasheet_link <> '';
+
+ALTER TABLE products
+    DROP COLUMN datasheet_link,
+    DROP COLUMN datasheet_name;
+
+-- =========================
+-- Indexes
+-- =========================
+CREATE UNIQUE INDEX ux_product_datasheets_current ON product_datasheets(product_id) WHERE is_current;
@@ -0,0 +1,60 @@
+-- =========================
+-- Requests for quotation
+-- =========================
+-- A visitor picks products from the catalogue with quantities and submits them as one request.
+-- RFQs have their own fixed workflow: NEW -> QUOTED -> ACCEPTED, NEW or QUOTED -> REJECTED, REJECTED -> NEW.
+-- Submissions flagged by the spam scorer are stored REJECTED with is_spam set and are hidden from the list;
+-- reopening one clears the flag.
+CREATE TABLE rfqs (
+    id BIGSERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    company VARCHAR(150) NOT NULL DEFAULT '',
+    email VARCHAR(255) NOT NULL,
+    mobile VARCHAR(20) NOT NULL,
+    message TEXT NOT NULL DEFAULT '',
+    status VARCHAR(20) NOT NULL DEFAULT 'NEW' CHECK (status IN ('NEW', 'QUOTED', 'ACCEPTED', 'REJECTED')),
+    is_spam BOOLEAN NOT NULL DEFAULT FALSE,
+    spam_score INT NOT NULL DEFAULT 0,
+    spam_reasons TEXT NOT NULL DEFAULT '',
+    ip_address VARCHAR(45) NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- =========================
+-- RFQ line items
+-- =========================
+-- product_code and product_name are copied when the request is submitted, so the request still reads
+-- correctly after the product is renamed or deleted.
+CREATE TABLE rfq_items (
+    id BIGSERIAL PRIMARY KEY,
+    rfq_id BIGINT NOT NULL REFERENCES rfqs(id) ON DELETE CASCADE,
+    product_id BIGINT REFERENCES products(id) ON DELETE SET NULL,
+    product_code VARCHAR(50) NOT NULL,
+    product_name VARCHAR(255) NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    note VARCHAR(500) NOT NULL DEFAULT '',
+    sort_order INT NOT NULL DEFAULT 0
+);
+
+-- =========================
+-- RFQ status history
+-- =========================
+CREATE TABLE rfq_status_history (
+    id BIGSERIAL PRIMARY KEY,
+    rfq_id BIGINT NOT NULL REFERENCES rfqs(id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL DEFAULT '', -- '' for the initial status
+    to_status VARCHAR(20) NOT NULL,
+    changed_by BIGINT REFERENCES users(id) ON DELETE SET NULL, -- NULL for the public form
+    comment TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- =========================
+-- Indexes
+-- =========================
+CREATE INDEX idx_rfqs_status ON rfqs(status) WHERE NOT is_spam;
+CREATE INDEX idx_rfqs_created_at ON rfqs(created_at);
+CREATE INDEX idx_rfq_items_rfq_id ON rfq_items(rfq_id);
+CREATE INDEX idx_rfq_items_product_id ON rfq_items(product_id);
+CREATE INDEX idx_rfq_status_history_rfq_id ON rfq_status_history(rfq_id);
@@ -0,0 +1,76 @@
+-- =========================
+-- Document sequences
+-- =========================
+-- One counter per document type and period (e.g. quotation / 2026). The next number is taken with an
+-- INSERT ... ON CONFLICT DO UPDATE ... RETURNING inside the transaction that creates the document, so the
+-- row lock serialises concurrent requests and a rolled back document does not use up its number.
+CREATE TABLE document_sequences (
+    name VARCHAR(30) NOT NULL,
+    period VARCHAR(10) NOT NULL,
+    last_value BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (name, period)
+);
+
+-- =========================
+-- Quotations
+-- =========================
+-- A priced offer built by staff, usually from an RFQ or an inquiry. Amounts are stored in hundredths of the
+-- currency unit; subtotal, discount, vat and total are computed by the application from the items.
+-- The rendered PDF is stored under data/quotations/<pdf_file>. Only drafts can be edited or deleted.
+CREATE TABLE quotations (
+    id BIGSERIAL PRIMARY KEY,
+    quotation_no VARCHAR(30) NOT NULL UNIQUE,
+    rfq_id BIGINT REFERENCES rfqs(id) ON DELETE SET NULL,
+    inquiry_id BIGINT REFERENCES inquiries(id) ON DELETE SET NULL,
+    customer_name VARCHAR(100) NOT NULL,
+    customer_company VARCHAR(150) NOT NULL DEFAULT '',
+    customer_email VARCHAR(255) NOT NULL DEFAULT '',
+    customer_mobile VARCHAR(20) NOT NULL DEFAULT '',
+    customer_address TEXT NOT NULL DEFAULT '',
+    subject VARCHAR(255) NOT NULL DEFAULT '',
+    reference VARCHAR(50) NOT NULL DEFAULT '', -- the customer's reference, e.g. the RFQ or inquiry number
+    issue_date DATE NOT NULL DEFAULT CURRENT_DATE,
+    valid_until DATE,
+    currency VARCHAR(3) NOT NULL,
+    subtotal BIGINT NOT NULL DEFAULT 0,
+    discount_percent NUMERIC(5,2) NOT NULL DEFAULT 0, -- 0 when a fixed discount is given
+    discount BIGINT NOT NULL DEFAULT 0,
+    vat_rate NUMERIC(5,2) NOT NULL DEFAULT 0,
+    vat BIGINT NOT NULL DEFAULT 0,
+    total BIGINT NOT NULL DEFAULT 0,
+    notes TEXT NOT NULL DEFAULT '',
+    terms TEXT NOT NULL DEFAULT '',
+    status VARCHAR(10) NOT NULL DEFAULT 'DRAFT' CHECK (status IN ('DRAFT', 'SENT')),
+    pdf_file VARCHAR(255) NOT NULL DEFAULT '',
+    sent_at TIMESTAMPTZ,
+    sent_to VARCHAR(255) NOT NULL DEFAULT '',
+    created_by BIGINT REFERENCES users(id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    CHECK (discount >= 0 AND discount <= subtotal)
+);
+
+-- =========================
+-- Quotation line items
+-- =========================
+CREATE TABLE quotation_items (
+    id BIGSERIAL PRIMARY KEY,
+    quotation_id BIGINT NOT NULL REFERENCES quotations(id) ON DELETE CASCADE,
+    product_id BIGINT REFERENCES products(id) ON DELETE SET NULL,
+    product_code VARCHAR(50) NOT NULL DEFAULT '',
+    description TEXT NOT NULL,
+    quantity INT NOT NULL CHECK (quantity > 0),
+    unit VARCHAR(20) NOT NULL DEFAULT '',
+    unit_price BIGINT NOT NULL CHECK (unit_price >= 0),
+    amount BIGINT NOT NULL,
+    sort_order INT NOT NULL DEFAULT 0
+);
+
+-- =========================
+-- Indexes
+-- =========================
+CREATE INDEX idx_quotations_rfq_id ON quotations(rfq_id);
+CREATE INDEX idx_quotations_inquiry_id ON quotations(inquiry_id);
+CREATE INDEX idx_quotations_created_at ON quotations(created_at);
+CREATE INDEX idx_quotation_items_quotation_id ON quotation_items(quotation_id);